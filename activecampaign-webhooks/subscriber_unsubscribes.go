@@ -1,65 +1,16 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
-	"strings"
 
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
 	"bitbucket.org/dagoodma/nancyhillis-go/util"
-
-	"github.com/gosexy/to"
-	"github.com/gosexy/yaml"
 )
 
 var Debug = true // Show/hide debug output
 //var WebhookIsSilent = false // don't print anything since we return JSON
-var UserAgent = "Nancy Hillis Studio Webhook Backend (wbhk97.nancyhillis.com)"
-
-// Subscriber joins rain mail list input data
-type InputData struct {
-	List  string `json:"list"`
-	Id    string `json:"id"`
-	Email string `json:"email"`
-}
-
-// Zapier webhook output data
-type ZapOutputData struct {
-	Status    string `json:"status"`
-	Attempt   string `json:"attempt"`
-	Id        string `json:"id"`
-	RequestId string `json:"request_id"`
-}
-
-/* Rainmail -> Zapier settings */
-var SecretsFilePath = "/var/webhook/secrets/active_campaign_secrets.yml"
-
-func GetUrlSecret(listName string) (string, error) {
-	secrets := yaml.New()
-	secrets, err := yaml.Open(SecretsFilePath)
-	if err != nil {
-		return "", err
-	}
-
-	url := to.String(secrets.Get(ZapierUrlParentKey, listName))
-	if len(url) < 1 {
-		// Remove slashes and try again
-		listName = strings.Replace(listName, "\\", "", -1)
-		url = to.String(secrets.Get(ZapierUrlParentKey, listName))
-
-		if len(url) < 1 {
-			msg := fmt.Sprintf("No url found for list: %s", listName)
-			return "", errors.New(msg)
-		}
-	}
-
-	return url, nil
-}
 
 func main() {
 	argsWithProg := os.Args
@@ -79,109 +30,86 @@ func main() {
 	if Debug {
 		util.RecordWebhookStarted(w)
 	}
+	if _, err := os.Stat("ac_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local AC secrets.")
+		ac.SecretsFilePath = "ac_secrets.yml"
+	}
 	log.Println("Entire headers: " + string(header))
 	log.Println("Entire payload: " + string(data))
 
-	// Unmarshal the input data
-	m := InputData{}
-	err := json.Unmarshal(data, &m)
-	//m := make(map[string]string)
-	//err := json.Unmarshal(data, &m)
+	// Parse AC's native webhook post, with the hook URL's query (and secret)
+	// passed as an extra argument
+	query := ""
+	if len(argsWithProg) > 3 {
+		query = argsWithProg[3]
+	}
+	p, err := ac.ParseWebhookWithQuery(data, query)
 	if err != nil {
-		HandleError(w, "Error while parsing input data for '%s'. %v", data, err)
+		HandleError(w, "Error while parsing webhook data for '%s'. %v", data, err)
+		return
+	}
+	m, ok := p.(*ac.UnsubscribeWebhook)
+	if !ok {
+		HandleError(w, "Expected an unsubscribe webhook, got: %s", p.Common().Type)
+		return
+	}
+	c, err := ac.DefaultClient()
+	if err != nil {
+		HandleError(w, "Could not load AC client: %s", err.Error())
+		return
+	}
+	if err := m.CheckSecret(c.WebhookSecret); err != nil {
+		HandleError(w, "Rejected unsubscribe webhook: %s", err.Error())
 		return
 	}
 
-	// Get and validate the fields (customer_id)
-	//customerId, ok := m["customer_id"]
-	listName := m.List
-	if len(listName) < 1 {
-		HandleError(w, "No email list name provided")
+	// Get and validate the fields
+	listId := m.ListId
+	if len(listId) < 1 {
+		HandleError(w, "No email list ID provided")
 		return
 	}
-	email := m.Email
+	email := m.Contact.Email
 	if !util.EmailLooksValid(email) {
 		HandleError(w, "Invalid subscriber email: %s", email)
 		return
 	}
-	id := m.Id
+	id := m.Contact.Id
 	if len(id) < 1 {
 		HandleError(w, "No subscriber ID provided")
 		return
 	}
 
-	// Get Zapier webhook url secrets
-	url, err := GetUrlSecret(listName)
+	// Find their current status in the list
+	status, err := c.GetContactListStatus(id, listId)
 	if err != nil {
-		HandleError(w, "Could not handle new subscriber \"%s\": %s", email, err.Error())
+		HandleError(w, "Could not get list status of subscriber \"%s\" (%s): %s", email, id, err.Error())
 		return
 	}
 
-	if Debug {
-		log.Printf("Forwarding to Zapier webhook: %s\n\t%v\n", url, m)
-	}
-	rawData, err := json.Marshal(m)
-	if err != nil {
-		HandleError(w, "Failed marshalling JSON data for webhook: %s", err.Error())
+	// Make sure they're unsubscribed
+	if status == ac.LIST_STATUS_UNSUBSCRIBED {
+		message := fmt.Sprintf("Subscriber \"%s\" (%s) unsubscribed from list %s (initiated from %s by %s)",
+			email, id, listId, m.InitiatedFrom, m.InitiatedBy)
+		util.ReportWebhookSuccess(w, message)
 		return
 	}
-	r, err := ForwardZapierWebhook(url, rawData)
-	if err != nil {
-		HandleError(w, "Failed forwarding JSON data webhook url: %s. %v", url, err.Error())
-		return
+	if Debug {
+		log.Printf("Subscriber '%s' has status %d in list %s, unsubscribing", email, status, listId)
 	}
-	log.Printf("Here with resp: %s\n", r)
-
-	// Unmarshal the output data (response)
-	m2 := ZapOutputData{}
-	err = json.Unmarshal(r, &m2)
+	err = c.UnsubscribeContactFromList(id, listId)
 	if err != nil {
-		HandleError(w, "Error while parsing output data for (%s) '%s'. %v", url, r, err)
-		return
-	}
-	if m2.Status != "success" {
-		HandleError(w, "Expected success forwarding data to webhook url (%s), got: %s. %s", url, m2.Status, r)
+		HandleError(w, "Could not unsubscribe \"%s\" (%s) from AC list %s: %s", email, id, listId, err.Error())
 		return
 	}
 
 	// Report to slack
-	message := fmt.Sprintf("Subscriber \"%s\" (%s) joined a list \"%s\" and was forwarded to a Zapier webhook: %s",
-		email, id, listName, url)
+	message := fmt.Sprintf("Subscriber \"%s\" (%s) was unsubscribed from list %s (initiated from %s by %s)",
+		email, id, listId, m.InitiatedFrom, m.InitiatedBy)
 	util.ReportWebhookSuccess(w, message)
 	return
 }
 
-func ForwardZapierWebhook(url string, jsonData []byte) ([]byte, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		//log.Fatalln(err)
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", UserAgent)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		//log.Fatalln(err)
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-
-	//log.Println("response Status:", resp.Status)
-	//log.Println("response Headers:", resp.Header)
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		//log.Fatalln(err)
-		return nil, err
-	}
-	//log.Println("response Body:", string(body))
-
-	return body, nil
-}
-
 func HandleError(w *util.WebhookEvent, format string, args ...interface{}) {
 	message := fmt.Sprintf(format, args...)
 	util.PrintJsonError(message)
@@ -0,0 +1,665 @@
+package actest
+
+import (
+    "fmt"
+    "io/ioutil"
+    "strconv"
+    "strings"
+
+    "gopkg.in/yaml.v2"
+
+    ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+// Items to seed the server with. IDs are generated for any left empty, and
+// contacts refer to tags, automations and fields by name, so a fixture file
+// only needs IDs where a test checks them. For example:
+//
+//     lists:
+//       - name: Newsletter
+//     tags:
+//       - name: SJC_Enrolled
+//     automations:
+//       - name: SJC_Enrolled
+//     fields:
+//       - title: Changed Email
+//         perstag: CHANGED_EMAIL
+//     contacts:
+//       - id: "42"
+//         email: tester@example.com
+//         tags: [SJC_Enrolled]
+//         automations: [SJC_Enrolled]
+//         fields: {CHANGED_EMAIL: new@example.com}
+//         notes: ["Enrolled in SJC"]
+//         lists: [Newsletter]
+type Fixtures struct {
+    Lists       []ListFixture       `yaml:"lists"`
+    Tags        []TagFixture        `yaml:"tags"`
+    Automations []AutomationFixture `yaml:"automations"`
+    Fields      []FieldFixture      `yaml:"fields"`
+    Contacts    []ContactFixture    `yaml:"contacts"`
+}
+
+type ListFixture struct {
+    Id          string  `yaml:"id"`
+    Name        string  `yaml:"name"`
+}
+
+type TagFixture struct {
+    Id          string  `yaml:"id"`
+    Name        string  `yaml:"name"`
+}
+
+type AutomationFixture struct {
+    Id          string  `yaml:"id"`
+    Name        string  `yaml:"name"`
+}
+
+type FieldFixture struct {
+    Id          string  `yaml:"id"`
+    Title       string  `yaml:"title"`
+    Perstag     string  `yaml:"perstag"`
+    Type        string  `yaml:"type"` // defaults to "text"
+}
+
+type ContactFixture struct {
+    Id          string  `yaml:"id"`
+    Email       string  `yaml:"email"`
+    FirstName   string  `yaml:"first_name"`
+    LastName    string  `yaml:"last_name"`
+    Phone       string  `yaml:"phone"`
+    Created     string  `yaml:"created"` // in DATE_LAYOUT, defaults to now
+    Updated     string  `yaml:"updated"`
+    Tags        []string            `yaml:"tags"` // names, created if missing
+    Automations []string            `yaml:"automations"` // names, created if missing
+    CompletedAutomations []string   `yaml:"completed_automations"`
+    Fields      map[string]string   `yaml:"fields"` // values by perstag
+    Notes       []string            `yaml:"notes"`
+    Lists       []string            `yaml:"lists"` // names subscribed to, created if missing
+}
+
+// Reads fixtures from a YAML file and seeds the server with them
+func (s *Server) LoadFixtures(filePath string) error {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        return fmt.Errorf("Failed reading fixtures file '%s': %w", filePath, err)
+    }
+    var f Fixtures
+    err = yaml.Unmarshal(data, &f)
+    if err != nil {
+        return fmt.Errorf("Failed unmarshaling fixtures file '%s': %w", filePath, err)
+    }
+    return s.Seed(f)
+}
+
+// Adds the fixtures to whatever the server already has
+func (s *Server) Seed(f Fixtures) error {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+
+    for _, l := range f.Lists {
+        if s.findListByName(l.Name) != nil {
+            return fmt.Errorf("Duplicate list fixture: %s", l.Name)
+        }
+        s.addList(l.Id, l.Name)
+    }
+    for _, t := range f.Tags {
+        if s.findTagByName(t.Name) != nil {
+            return fmt.Errorf("Duplicate tag fixture: %s", t.Name)
+        }
+        s.addTag(t.Id, t.Name)
+    }
+    for _, a := range f.Automations {
+        s.addAutomation(a.Id, a.Name)
+    }
+    for _, fd := range f.Fields {
+        s.addField(fd)
+    }
+    for _, c := range f.Contacts {
+        if err := s.seedContact(c); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (s *Server) seedContact(f ContactFixture) error {
+    if s.findContactByEmail(f.Email) != nil {
+        return fmt.Errorf("Duplicate contact fixture: %s", f.Email)
+    }
+    c := s.addContact(f.Id, f.Email, f.FirstName, f.LastName, f.Phone)
+    if f.Created != "" {
+        c.CreationDate = f.Created
+        c.UpdatedDate = f.Created
+    }
+    if f.Updated != "" {
+        c.UpdatedDate = f.Updated
+    }
+
+    for _, name := range f.Tags {
+        t := s.findTagByName(name)
+        if t == nil {
+            t = s.addTag("", name)
+        }
+        s.addContactTag(c.Id, t.Id)
+    }
+    for i, names := range [][]string{f.Automations, f.CompletedAutomations} {
+        for _, name := range names {
+            a := s.findAutomationByName(name)
+            if a == nil {
+                a = s.addAutomation("", name)
+            }
+            s.addContactAutomation("", c.Id, a.Id, i == 1)
+        }
+    }
+    var values []ac.UpdateContactContactFieldValue
+    for perstag, value := range f.Fields {
+        fd := s.findFieldByPerstag(perstag)
+        if fd == nil {
+            return fmt.Errorf("No field fixture for contact '%s' field: %s", f.Email, perstag)
+        }
+        values = append(values, ac.UpdateContactContactFieldValue{Field: fd.Id, Value: value})
+    }
+    s.setFieldValues(c.Id, values)
+    for _, note := range f.Notes {
+        s.addNote("", c.Id, "Subscriber", note)
+    }
+    for _, name := range f.Lists {
+        l := s.findListByName(name)
+        if l == nil {
+            l = s.addList("", name)
+        }
+        s.setContactListStatus(c.Id, l.Id, ac.LIST_STATUS_ACTIVE)
+    }
+    return nil
+}
+
+/*
+ * Inspecting state from tests
+ */
+// Returns a copy of the contact with the email, or nil
+func (s *Server) ContactByEmail(email string) *ac.ListContactsContact {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    c := s.findContactByEmail(email)
+    if c == nil {
+        return nil
+    }
+    c2 := *c
+    return &c2
+}
+
+// Returns the names of the contact's tags
+func (s *Server) ContactTags(contactId string) []string {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    var l []string
+    for _, ct := range s.contactTags {
+        if ct.Contact == contactId {
+            if t := s.findTag(ct.Tag); t != nil {
+                l = append(l, t.Tag)
+            }
+        }
+    }
+    return l
+}
+
+// Returns the names of the automations the contact is currently in
+func (s *Server) ContactAutomations(contactId string) []string {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    var l []string
+    for _, ca := range s.contactAutomations {
+        if ca.Contact == contactId && ca.Completed == 0 {
+            if a := s.findAutomation(ca.Automation); a != nil {
+                l = append(l, a.Name)
+            }
+        }
+    }
+    return l
+}
+
+// Returns the text of the contact's notes
+func (s *Server) ContactNotes(contactId string) []string {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    var l []string
+    for _, n := range s.notes {
+        if n.RelativeId == contactId && n.RelativeType == "Subscriber" {
+            l = append(l, n.Note)
+        }
+    }
+    return l
+}
+
+// Returns the contact's custom field values keyed by perstag
+func (s *Server) ContactFieldValues(contactId string) map[string]string {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    values := make(map[string]string)
+    for _, v := range s.fieldValues {
+        if v.Contact == contactId {
+            if fd := s.findField(v.Field); fd != nil {
+                values[fd.PersonalizationTag] = v.Value
+            }
+        }
+    }
+    return values
+}
+
+// Returns the contact's status in the list (see ac.LIST_STATUS_*), or -1 if
+// they were never added to it
+func (s *Server) ContactListStatus(contactId string, listName string) int {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    l := s.findListByName(listName)
+    if l == nil {
+        return -1
+    }
+    for _, cl := range s.contactLists {
+        if cl.Contact == contactId && cl.List == l.Id {
+            status, _ := strconv.Atoi(cl.Status)
+            return status
+        }
+    }
+    return -1
+}
+
+/*
+ * State, the caller must hold the mutex
+ */
+func (s *Server) addContact(id string, email string, firstName string, lastName string,
+    phone string) *ac.ListContactsContact {
+    c := &ac.ListContactsContact{
+        Id: s.useId(id),
+        Email: email,
+        FirstName: firstName,
+        LastName: lastName,
+        Phone: phone,
+        CreationDate: s.now(),
+        UpdatedDate: s.now(),
+        Deleted: "0",
+        Anonymized: "0",
+        ScoreValues: []string{},
+        AccountContacts: []string{},
+    }
+    if at := strings.LastIndex(email, "@"); at >= 0 {
+        c.EmailLocal = email[:at]
+        c.EmailDomain = email[at + 1:]
+    }
+    c.CreatedTimestamp = c.CreationDate
+    c.UpdatedTimestamp = c.UpdatedDate
+    c.Links = ac.ListContactsContactLinks{
+        ContactAutomations: s.link("/contacts/%s/contactAutomations", c.Id),
+        ContactTags: s.link("/contacts/%s/contactTags", c.Id),
+        FieldValues: s.link("/contacts/%s/fieldValues", c.Id),
+        Notes: s.link("/contacts/%s/notes", c.Id),
+    }
+    s.contacts = append(s.contacts, c)
+    return c
+}
+
+func (s *Server) removeContact(id string) {
+    var contacts []*ac.ListContactsContact
+    for _, c := range s.contacts {
+        if c.Id != id {
+            contacts = append(contacts, c)
+        }
+    }
+    s.contacts = contacts
+    var contactTags []*ac.ListContactTagsTag
+    for _, ct := range s.contactTags {
+        if ct.Contact != id {
+            contactTags = append(contactTags, ct)
+        }
+    }
+    s.contactTags = contactTags
+    var contactAutomations []*ac.ListContactAutomationsContact
+    for _, ca := range s.contactAutomations {
+        if ca.Contact != id {
+            contactAutomations = append(contactAutomations, ca)
+        }
+    }
+    s.contactAutomations = contactAutomations
+    var fieldValues []*ac.RetrieveContactFieldValue
+    for _, v := range s.fieldValues {
+        if v.Contact != id {
+            fieldValues = append(fieldValues, v)
+        }
+    }
+    s.fieldValues = fieldValues
+    var notes []*ac.Note
+    for _, n := range s.notes {
+        if n.RelativeId != id || n.RelativeType != "Subscriber" {
+            notes = append(notes, n)
+        }
+    }
+    s.notes = notes
+    var contactLists []*ac.RetrieveContactList
+    for _, cl := range s.contactLists {
+        if cl.Contact != id {
+            contactLists = append(contactLists, cl)
+        }
+    }
+    s.contactLists = contactLists
+}
+
+func (s *Server) findContact(id string) *ac.ListContactsContact {
+    for _, c := range s.contacts {
+        if c.Id == id {
+            return c
+        }
+    }
+    return nil
+}
+
+func (s *Server) findContactByEmail(email string) *ac.ListContactsContact {
+    for _, c := range s.contacts {
+        if strings.EqualFold(c.Email, email) {
+            return c
+        }
+    }
+    return nil
+}
+
+func (s *Server) addTag(id string, name string) *ac.ListTagsTag {
+    t := &ac.ListTagsTag{
+        Id: s.useId(id),
+        Tag: name,
+        TagType: "contact",
+        CreationDate: s.now(),
+        SubscriberCount: "0",
+    }
+    t.CreatedTimestamp = t.CreationDate
+    t.UpdatedTimestamp = t.CreationDate
+    t.Links.ContactGoalTags = s.link("/tags/%s/contactGoalTags", t.Id)
+    s.tags = append(s.tags, t)
+    return t
+}
+
+func (s *Server) findTag(id string) *ac.ListTagsTag {
+    for _, t := range s.tags {
+        if t.Id == id {
+            return t
+        }
+    }
+    return nil
+}
+
+func (s *Server) findTagByName(name string) *ac.ListTagsTag {
+    for _, t := range s.tags {
+        if strings.EqualFold(t.Tag, name) {
+            return t
+        }
+    }
+    return nil
+}
+
+func (s *Server) addContactTag(contactId string, tagId string) *ac.ListContactTagsTag {
+    ct := &ac.ListContactTagsTag{
+        Id: s.newId(),
+        Contact: contactId,
+        Tag: tagId,
+        CreationDate: s.now(),
+    }
+    ct.CreatedTimestamp = ct.CreationDate
+    ct.UpdatedTimestamp = ct.CreationDate
+    ct.Links.Tag = s.link("/contactTags/%s/tag", ct.Id)
+    ct.Links.Contact = s.link("/contactTags/%s/contact", ct.Id)
+    s.contactTags = append(s.contactTags, ct)
+    return ct
+}
+
+func (s *Server) findContactTag(contactId string, tagId string) *ac.ListContactTagsTag {
+    for _, ct := range s.contactTags {
+        if ct.Contact == contactId && ct.Tag == tagId {
+            return ct
+        }
+    }
+    return nil
+}
+
+func (s *Server) addAutomation(id string, name string) *ac.ListAutomationsAutomation {
+    a := &ac.ListAutomationsAutomation{
+        Id: s.useId(id),
+        Name: name,
+        CreatedDate: s.now(),
+        ModifiedDate: s.now(),
+        UserId: "1",
+        Status: "1",
+        Entered: "0",
+        Exited: "0",
+        Hidden: "0",
+    }
+    a.Links.ContactAutomations = s.link("/automations/%s/contactAutomations", a.Id)
+    a.Links.ContactGoals = s.link("/automations/%s/contactGoals", a.Id)
+    s.automations = append(s.automations, a)
+    return a
+}
+
+func (s *Server) findAutomation(id string) *ac.ListAutomationsAutomation {
+    for _, a := range s.automations {
+        if a.Id == id {
+            return a
+        }
+    }
+    return nil
+}
+
+func (s *Server) findAutomationByName(name string) *ac.ListAutomationsAutomation {
+    for _, a := range s.automations {
+        if a.Name == name {
+            return a
+        }
+    }
+    return nil
+}
+
+func (s *Server) addContactAutomation(id string, contactId string, automationId string,
+    completed bool) *ac.ListContactAutomationsContact {
+    ca := &ac.ListContactAutomationsContact{
+        Id: s.useId(id),
+        Contact: contactId,
+        SeriesId: automationId,
+        Automation: automationId,
+        Status: "1",
+        AddDate: s.now(),
+    }
+    if completed {
+        ca.Status = "2"
+        ca.Completed = 1
+        ca.CompleteValue = 100
+        ca.RemoveDate = s.now()
+        ca.IsCompleted = true
+    }
+    ca.Links.Automation = s.link("/contactAutomations/%s/automation", ca.Id)
+    ca.Links.Contact = s.link("/contactAutomations/%s/contact", ca.Id)
+    s.contactAutomations = append(s.contactAutomations, ca)
+    return ca
+}
+
+func (s *Server) findContactAutomation(id string) *ac.ListContactAutomationsContact {
+    for _, ca := range s.contactAutomations {
+        if ca.Id == id {
+            return ca
+        }
+    }
+    return nil
+}
+
+func (s *Server) contactContactAutomations(contactId string) []*ac.ListContactAutomationsContact {
+    l := []*ac.ListContactAutomationsContact{}
+    for _, ca := range s.contactAutomations {
+        if ca.Contact == contactId {
+            l = append(l, ca)
+        }
+    }
+    return l
+}
+
+func (s *Server) addNote(id string, relId string, relType string, note string) *ac.Note {
+    n := &ac.Note{
+        Id: s.useId(id),
+        Note: note,
+        RelativeId: relId,
+        RelativeType: relType,
+        UserId: "1",
+        CreationDate: s.now(),
+        ModifiedDate: s.now(),
+    }
+    s.notes = append(s.notes, n)
+    return n
+}
+
+func (s *Server) findNote(id string) *ac.Note {
+    for _, n := range s.notes {
+        if n.Id == id {
+            return n
+        }
+    }
+    return nil
+}
+
+func (s *Server) addField(f FieldFixture) *ac.ListFieldsField {
+    fd := &ac.ListFieldsField{
+        Id: s.useId(f.Id),
+        Title: f.Title,
+        Type: f.Type,
+        PersonalizationTag: strings.ToUpper(strings.Trim(f.Perstag, "%")),
+        IsRequired: "0",
+        Visible: "1",
+    }
+    if fd.Type == "" {
+        fd.Type = "text"
+    }
+    s.fields = append(s.fields, fd)
+    return fd
+}
+
+func (s *Server) findField(id string) *ac.ListFieldsField {
+    for _, fd := range s.fields {
+        if fd.Id == id {
+            return fd
+        }
+    }
+    return nil
+}
+
+func (s *Server) findFieldByPerstag(perstag string) *ac.ListFieldsField {
+    perstag = strings.ToUpper(strings.Trim(perstag, "%"))
+    for _, fd := range s.fields {
+        if fd.PersonalizationTag == perstag {
+            return fd
+        }
+    }
+    return nil
+}
+
+// Sets each field value on the contact, replacing any existing value
+func (s *Server) setFieldValues(contactId string, values []ac.UpdateContactContactFieldValue) {
+    for _, v := range values {
+        var existing *ac.RetrieveContactFieldValue
+        for _, fv := range s.fieldValues {
+            if fv.Contact == contactId && fv.Field == v.Field {
+                existing = fv
+            }
+        }
+        if existing == nil {
+            existing = &ac.RetrieveContactFieldValue{
+                Id: s.newId(),
+                Contact: contactId,
+                Field: v.Field,
+                Owner: contactId,
+                CreationDate: s.now(),
+            }
+            existing.Links.Owner = s.link("/fieldValues/%s/owner", existing.Id)
+            existing.Links.Field = s.link("/fieldValues/%s/field", existing.Id)
+            s.fieldValues = append(s.fieldValues, existing)
+        }
+        existing.Value = v.Value
+        existing.UpdateDate = s.now()
+    }
+}
+
+func (s *Server) contactFieldValues(contactId string) []*ac.RetrieveContactFieldValue {
+    l := []*ac.RetrieveContactFieldValue{}
+    for _, v := range s.fieldValues {
+        if v.Contact == contactId {
+            l = append(l, v)
+        }
+    }
+    return l
+}
+
+func (s *Server) addList(id string, name string) *ac.ListListsList {
+    l := &ac.ListListsList{
+        Id: s.useId(id),
+        Name: name,
+        StringId: strings.ToLower(strings.ReplaceAll(name, " ", "-")),
+        UserId: "1",
+        CreationDate: s.now(),
+        UpdateDate: s.now(),
+        Private: "0",
+    }
+    l.Links.ContactGoalLists = s.link("/lists/%s/contactGoalLists", l.Id)
+    l.Links.User = s.link("/lists/%s/user", l.Id)
+    l.Links.AddressLists = s.link("/lists/%s/addressLists", l.Id)
+    s.lists = append(s.lists, l)
+    return l
+}
+
+func (s *Server) findList(id string) *ac.ListListsList {
+    for _, l := range s.lists {
+        if l.Id == id {
+            return l
+        }
+    }
+    return nil
+}
+
+func (s *Server) findListByName(name string) *ac.ListListsList {
+    for _, l := range s.lists {
+        if l.Name == name {
+            return l
+        }
+    }
+    return nil
+}
+
+// Sets the contact's status in the list, adding them to it if needed
+func (s *Server) setContactListStatus(contactId string, listId string, status int) *ac.RetrieveContactList {
+    var cl *ac.RetrieveContactList
+    for _, existing := range s.contactLists {
+        if existing.Contact == contactId && existing.List == listId {
+            cl = existing
+        }
+    }
+    if cl == nil {
+        cl = &ac.RetrieveContactList{
+            Id: s.newId(),
+            Contact: contactId,
+            List: listId,
+            SubscribeDate: s.now(),
+        }
+        cl.CreatedTimestamp = cl.SubscribeDate
+        cl.Links.List = s.link("/contactLists/%s/list", cl.Id)
+        cl.Links.Contact = s.link("/contactLists/%s/contact", cl.Id)
+        s.contactLists = append(s.contactLists, cl)
+    }
+    cl.Status = strconv.Itoa(status)
+    cl.UpdatedTimestamp = s.now()
+    if status == ac.LIST_STATUS_UNSUBSCRIBED {
+        cl.UnsubscribeDate = s.now()
+    }
+    return cl
+}
+
+func (s *Server) contactContactLists(contactId string) []*ac.RetrieveContactList {
+    l := []*ac.RetrieveContactList{}
+    for _, cl := range s.contactLists {
+        if cl.Contact == contactId {
+            l = append(l, cl)
+        }
+    }
+    return l
+}
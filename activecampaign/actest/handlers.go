@@ -0,0 +1,657 @@
+package actest
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/url"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+// AC accepts either of these in the date filters
+var filterDateLayouts = []string{DATE_LAYOUT, "2006-01-02 15:04:05", "2006-01-02"}
+
+func (s *Server) getRoot(w http.ResponseWriter, r *http.Request, id string) {
+    writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+/*
+ * Contacts
+ */
+func (s *Server) listContacts(w http.ResponseWriter, r *http.Request, id string) {
+    q := r.URL.Query()
+    var l []*ac.ListContactsContact
+    for _, c := range s.contacts {
+        if s.contactMatches(c, q) {
+            l = append(l, c)
+        }
+    }
+    sortContacts(l, q)
+
+    start, end, meta := paginate(r, len(l))
+    for _, k := range []string{"email", "search"} {
+        if v := q.Get(k); v != "" {
+            meta.PageInput[k] = v
+        }
+    }
+    for _, k := range []string{"tagid", "seriesid"} {
+        if v, err := strconv.Atoi(q.Get(k)); err == nil {
+            meta.PageInput[k] = v
+        }
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "scoreValues": []string{},
+        "contacts": nonNilContacts(l[start:end]),
+        "meta": meta,
+    })
+}
+
+func (s *Server) contactMatches(c *ac.ListContactsContact, q url.Values) bool {
+    if v := q.Get("email"); v != "" && !strings.EqualFold(c.Email, v) {
+        return false
+    }
+    if v := q.Get("email_like"); v != "" && !containsFold(c.Email, v) {
+        return false
+    }
+    if v := q.Get("search"); v != "" && !containsFold(c.Email, v) &&
+        !containsFold(c.FirstName + " " + c.LastName, v) {
+        return false
+    }
+    if v := q.Get("tagid"); v != "" && s.findContactTag(c.Id, v) == nil {
+        return false
+    }
+    if v := q.Get("seriesid"); v != "" {
+        found := false
+        for _, ca := range s.contactAutomations {
+            if ca.Contact == c.Id && ca.Automation == v {
+                found = true
+            }
+        }
+        if !found {
+            return false
+        }
+    }
+    for _, f := range []struct{ key, date string; after bool }{
+        {"filters[created_after]", c.CreationDate, true},
+        {"filters[created_before]", c.CreationDate, false},
+        {"filters[updated_after]", c.UpdatedDate, true},
+        {"filters[updated_before]", c.UpdatedDate, false},
+    } {
+        v := q.Get(f.key)
+        if v == "" {
+            continue
+        }
+        filter, ok := parseFilterDate(v)
+        date, _ := time.Parse(DATE_LAYOUT, f.date)
+        if ok && ((f.after && !date.After(filter)) || (!f.after && !date.Before(filter))) {
+            return false
+        }
+    }
+    return true
+}
+
+// Sorts by the orders[...] parameter, or by ID like AC does by default
+func sortContacts(l []*ac.ListContactsContact, q url.Values) {
+    field, desc := "id", false
+    for k, v := range q {
+        if strings.HasPrefix(k, "orders[") && strings.HasSuffix(k, "]") {
+            field = strings.TrimSuffix(strings.TrimPrefix(k, "orders["), "]")
+            desc = len(v) > 0 && strings.EqualFold(v[0], "DESC")
+        }
+    }
+    key := func(c *ac.ListContactsContact) string {
+        switch field {
+        case "email":
+            return strings.ToLower(c.Email)
+        case "first_name":
+            return strings.ToLower(c.FirstName)
+        case "last_name":
+            return strings.ToLower(c.LastName)
+        case "name":
+            return strings.ToLower(c.FirstName + " " + c.LastName)
+        case "cdate":
+            return c.CreationDate
+        }
+        return ""
+    }
+    sort.SliceStable(l, func(i, j int) bool {
+        less := false
+        ki, kj := key(l[i]), key(l[j])
+        if ki == kj {
+            less = idLess(l[i].Id, l[j].Id)
+        } else {
+            less = ki < kj
+        }
+        if desc {
+            return !less
+        }
+        return less
+    })
+}
+
+func (s *Server) createContact(w http.ResponseWriter, r *http.Request, id string) {
+    var m ac.SyncContact
+    if err := decodeBody(r, &m); err != nil {
+        writeBadRequest(w, err)
+        return
+    }
+    if !validateEmail(w, m.Contact.Email) {
+        return
+    }
+    if s.findContactByEmail(m.Contact.Email) != nil {
+        writeValidation(w, "duplicate", "Email address already exists in the system.",
+            "/data/attributes/email")
+        return
+    }
+    if !s.validateFieldValues(w, m.Contact.FieldValues) {
+        return
+    }
+
+    c := s.addContact("", m.Contact.Email, m.Contact.FirstName, m.Contact.LastName, m.Contact.Phone)
+    s.setFieldValues(c.Id, m.Contact.FieldValues)
+    writeJSON(w, http.StatusCreated, map[string]interface{}{
+        "fieldValues": s.contactFieldValues(c.Id),
+        "contact": c,
+    })
+}
+
+// Creates or updates a contact by email. Empty values are left unchanged.
+func (s *Server) syncContact(w http.ResponseWriter, r *http.Request, id string) {
+    var m ac.SyncContact
+    if err := decodeBody(r, &m); err != nil {
+        writeBadRequest(w, err)
+        return
+    }
+    if !validateEmail(w, m.Contact.Email) || !s.validateFieldValues(w, m.Contact.FieldValues) {
+        return
+    }
+
+    status := http.StatusOK
+    c := s.findContactByEmail(m.Contact.Email)
+    if c == nil {
+        status = http.StatusCreated
+        c = s.addContact("", m.Contact.Email, "", "", "")
+    }
+    for _, v := range []struct{ to *string; from string }{
+        {&c.FirstName, m.Contact.FirstName},
+        {&c.LastName, m.Contact.LastName},
+        {&c.Phone, m.Contact.Phone},
+    } {
+        if v.from != "" {
+            *v.to = v.from
+        }
+    }
+    c.UpdatedDate = s.now()
+    s.setFieldValues(c.Id, m.Contact.FieldValues)
+    writeJSON(w, status, map[string]interface{}{
+        "fieldValues": s.contactFieldValues(c.Id),
+        "contact": c,
+    })
+}
+
+func (s *Server) getContact(w http.ResponseWriter, r *http.Request, id string) {
+    c := s.findContact(id)
+    if c == nil {
+        writeNotFound(w, "Subscriber", id)
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "contactAutomations": s.contactContactAutomations(id),
+        "contactData": []interface{}{},
+        "contactLists": []interface{}{},
+        "deals": []interface{}{},
+        "fieldValues": s.contactFieldValues(id),
+        "geoIps": []interface{}{},
+        "geoAddresses": []interface{}{},
+        "accountContacts": []interface{}{},
+        "contact": c,
+    })
+}
+
+// Only the values sent are changed, but an empty value clears it like AC
+func (s *Server) updateContact(w http.ResponseWriter, r *http.Request, id string) {
+    c := s.findContact(id)
+    if c == nil {
+        writeNotFound(w, "Subscriber", id)
+        return
+    }
+    var m struct {
+        Contact map[string]json.RawMessage `json:"contact"`
+    }
+    if err := decodeBody(r, &m); err != nil {
+        writeBadRequest(w, err)
+        return
+    }
+    values := make(map[string]string)
+    for _, k := range []string{"email", "firstName", "lastName", "phone"} {
+        if raw, ok := m.Contact[k]; ok {
+            var v string
+            json.Unmarshal(raw, &v)
+            values[k] = v
+        }
+    }
+    var fieldValues []ac.UpdateContactContactFieldValue
+    if raw, ok := m.Contact["fieldValues"]; ok {
+        if err := json.Unmarshal(raw, &fieldValues); err != nil {
+            writeBadRequest(w, err)
+            return
+        }
+    }
+
+    if email, ok := values["email"]; ok {
+        if !validateEmail(w, email) {
+            return
+        }
+        if other := s.findContactByEmail(email); other != nil && other.Id != id {
+            writeValidation(w, "duplicate", "Email address already exists in the system.",
+                "/data/attributes/email")
+            return
+        }
+    }
+    if !s.validateFieldValues(w, fieldValues) {
+        return
+    }
+
+    if v, ok := values["email"]; ok {
+        c.Email = v
+    }
+    if v, ok := values["firstName"]; ok {
+        c.FirstName = v
+    }
+    if v, ok := values["lastName"]; ok {
+        c.LastName = v
+    }
+    if v, ok := values["phone"]; ok {
+        c.Phone = v
+    }
+    c.UpdatedDate = s.now()
+    s.setFieldValues(id, fieldValues)
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "fieldValues": s.contactFieldValues(id),
+        "contact": c,
+    })
+}
+
+func (s *Server) deleteContact(w http.ResponseWriter, r *http.Request, id string) {
+    if s.findContact(id) == nil {
+        writeNotFound(w, "Subscriber", id)
+        return
+    }
+    s.removeContact(id)
+    writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (s *Server) listContactTags(w http.ResponseWriter, r *http.Request, id string) {
+    if s.findContact(id) == nil {
+        writeNotFound(w, "Subscriber", id)
+        return
+    }
+    l := []*ac.ListContactTagsTag{}
+    for _, ct := range s.contactTags {
+        if ct.Contact == id {
+            l = append(l, ct)
+        }
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{"contactTags": l})
+}
+
+func (s *Server) listContactContactAutomations(w http.ResponseWriter, r *http.Request, id string) {
+    if s.findContact(id) == nil {
+        writeNotFound(w, "Subscriber", id)
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "contactAutomations": s.contactContactAutomations(id),
+    })
+}
+
+func (s *Server) listContactNotes(w http.ResponseWriter, r *http.Request, id string) {
+    if s.findContact(id) == nil {
+        writeNotFound(w, "Subscriber", id)
+        return
+    }
+    l := []*ac.Note{}
+    for _, n := range s.notes {
+        if n.RelativeId == id && n.RelativeType == "Subscriber" {
+            l = append(l, n)
+        }
+    }
+    start, end, meta := paginate(r, len(l))
+    writeJSON(w, http.StatusOK, map[string]interface{}{"notes": l[start:end], "meta": meta})
+}
+
+func (s *Server) listContactFieldValues(w http.ResponseWriter, r *http.Request, id string) {
+    if s.findContact(id) == nil {
+        writeNotFound(w, "Subscriber", id)
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{"fieldValues": s.contactFieldValues(id)})
+}
+
+/*
+ * Tags
+ */
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request, id string) {
+    search := r.URL.Query().Get("search")
+    var l []*ac.ListTagsTag
+    for _, t := range s.tags {
+        if search == "" || containsFold(t.Tag, search) {
+            l = append(l, t)
+        }
+    }
+    start, end, meta := paginate(r, len(l))
+    page := append([]*ac.ListTagsTag{}, l[start:end]...)
+    writeJSON(w, http.StatusOK, map[string]interface{}{"tags": page, "meta": meta})
+}
+
+func (s *Server) createTag(w http.ResponseWriter, r *http.Request, id string) {
+    var m ac.CreateTag
+    if err := decodeBody(r, &m); err != nil {
+        writeBadRequest(w, err)
+        return
+    }
+    if strings.TrimSpace(m.Tag.Tag) == "" {
+        writeValidation(w, "field_missing", "Tag name cannot be blank", "/data/attributes/tag")
+        return
+    }
+    if s.findTagByName(m.Tag.Tag) != nil {
+        writeValidation(w, "duplicate", "Tag already exists", "/data/attributes/tag")
+        return
+    }
+    t := s.addTag("", m.Tag.Tag)
+    t.Description = m.Tag.Description
+    writeJSON(w, http.StatusCreated, map[string]interface{}{"tag": t})
+}
+
+func (s *Server) getTag(w http.ResponseWriter, r *http.Request, id string) {
+    t := s.findTag(id)
+    if t == nil {
+        writeNotFound(w, "Tag", id)
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{"tag": t})
+}
+
+// Tagging a contact that already has the tag returns the existing association
+func (s *Server) createContactTag(w http.ResponseWriter, r *http.Request, id string) {
+    var m ac.CreateContactTag
+    if err := decodeBody(r, &m); err != nil {
+        writeBadRequest(w, err)
+        return
+    }
+    if !s.validateRelated(w, "Subscriber", m.ContactTag.Contact, "contact") ||
+        !s.validateRelated(w, "Tag", m.ContactTag.Tag, "tag") {
+        return
+    }
+    ct := s.findContactTag(m.ContactTag.Contact, m.ContactTag.Tag)
+    if ct == nil {
+        ct = s.addContactTag(m.ContactTag.Contact, m.ContactTag.Tag)
+    }
+    writeJSON(w, http.StatusCreated, map[string]interface{}{"contactTag": ct})
+}
+
+func (s *Server) deleteContactTag(w http.ResponseWriter, r *http.Request, id string) {
+    for i, ct := range s.contactTags {
+        if ct.Id == id {
+            s.contactTags = append(s.contactTags[:i], s.contactTags[i+1:]...)
+            writeJSON(w, http.StatusOK, map[string]interface{}{})
+            return
+        }
+    }
+    writeNotFound(w, "ContactTag", id)
+}
+
+/*
+ * Automations
+ */
+func (s *Server) listAutomations(w http.ResponseWriter, r *http.Request, id string) {
+    start, end, meta := paginate(r, len(s.automations))
+    page := append([]*ac.ListAutomationsAutomation{}, s.automations[start:end]...)
+    writeJSON(w, http.StatusOK, map[string]interface{}{"automations": page, "meta": meta})
+}
+
+func (s *Server) listAutomationContactAutomations(w http.ResponseWriter, r *http.Request, id string) {
+    if s.findAutomation(id) == nil {
+        writeNotFound(w, "Automation", id)
+        return
+    }
+    var l []*ac.ListContactAutomationsContact
+    for _, ca := range s.contactAutomations {
+        if ca.Automation == id {
+            l = append(l, ca)
+        }
+    }
+    start, end, meta := paginate(r, len(l))
+    page := append([]*ac.ListContactAutomationsContact{}, l[start:end]...)
+    writeJSON(w, http.StatusOK, map[string]interface{}{"contactAutomations": page, "meta": meta})
+}
+
+func (s *Server) createContactAutomation(w http.ResponseWriter, r *http.Request, id string) {
+    var m ac.CreateContactAutomation
+    if err := decodeBody(r, &m); err != nil {
+        writeBadRequest(w, err)
+        return
+    }
+    if !s.validateRelated(w, "Subscriber", m.ContactAutomation.Contact, "contact") ||
+        !s.validateRelated(w, "Automation", m.ContactAutomation.Automation, "automation") {
+        return
+    }
+    ca := s.addContactAutomation("", m.ContactAutomation.Contact, m.ContactAutomation.Automation, false)
+    writeJSON(w, http.StatusCreated, map[string]interface{}{
+        "contacts": []*ac.ListContactsContact{s.findContact(ca.Contact)},
+        "contactAutomation": ca,
+    })
+}
+
+func (s *Server) getContactAutomation(w http.ResponseWriter, r *http.Request, id string) {
+    ca := s.findContactAutomation(id)
+    if ca == nil {
+        writeNotFound(w, "ContactAutomation", id)
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{"contactAutomation": ca})
+}
+
+func (s *Server) deleteContactAutomation(w http.ResponseWriter, r *http.Request, id string) {
+    for i, ca := range s.contactAutomations {
+        if ca.Id == id {
+            s.contactAutomations = append(s.contactAutomations[:i], s.contactAutomations[i+1:]...)
+            writeJSON(w, http.StatusOK, map[string]interface{}{})
+            return
+        }
+    }
+    writeNotFound(w, "ContactAutomation", id)
+}
+
+func (s *Server) getContactAutomationContact(w http.ResponseWriter, r *http.Request, id string) {
+    ca := s.findContactAutomation(id)
+    if ca == nil {
+        writeNotFound(w, "ContactAutomation", id)
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{"contact": s.findContact(ca.Contact)})
+}
+
+/*
+ * Notes
+ */
+func (s *Server) createNote(w http.ResponseWriter, r *http.Request, id string) {
+    var m ac.CreateNote
+    if err := decodeBody(r, &m); err != nil {
+        writeBadRequest(w, err)
+        return
+    }
+    if strings.TrimSpace(m.Note.Note) == "" {
+        writeValidation(w, "field_missing", "Note cannot be blank", "/data/attributes/note")
+        return
+    }
+    if m.Note.RelativeType == "Subscriber" &&
+        !s.validateRelated(w, "Subscriber", m.Note.RelativeId, "relid") {
+        return
+    }
+    n := s.addNote("", m.Note.RelativeId, m.Note.RelativeType, m.Note.Note)
+    writeJSON(w, http.StatusCreated, map[string]interface{}{"note": n})
+}
+
+func (s *Server) getNote(w http.ResponseWriter, r *http.Request, id string) {
+    n := s.findNote(id)
+    if n == nil {
+        writeNotFound(w, "Note", id)
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{"note": n})
+}
+
+func (s *Server) updateNote(w http.ResponseWriter, r *http.Request, id string) {
+    n := s.findNote(id)
+    if n == nil {
+        writeNotFound(w, "Note", id)
+        return
+    }
+    var m ac.UpdateNote
+    if err := decodeBody(r, &m); err != nil {
+        writeBadRequest(w, err)
+        return
+    }
+    if strings.TrimSpace(m.Note.Note) == "" {
+        writeValidation(w, "field_missing", "Note cannot be blank", "/data/attributes/note")
+        return
+    }
+    n.Note = m.Note.Note
+    n.ModifiedDate = s.now()
+    writeJSON(w, http.StatusOK, map[string]interface{}{"note": n})
+}
+
+func (s *Server) deleteNote(w http.ResponseWriter, r *http.Request, id string) {
+    for i, n := range s.notes {
+        if n.Id == id {
+            s.notes = append(s.notes[:i], s.notes[i+1:]...)
+            writeJSON(w, http.StatusOK, map[string]interface{}{})
+            return
+        }
+    }
+    writeNotFound(w, "Note", id)
+}
+
+/*
+ * Custom fields
+ */
+func (s *Server) listFields(w http.ResponseWriter, r *http.Request, id string) {
+    start, end, meta := paginate(r, len(s.fields))
+    page := append([]*ac.ListFieldsField{}, s.fields[start:end]...)
+    writeJSON(w, http.StatusOK, map[string]interface{}{"fields": page, "meta": meta})
+}
+
+/*
+ * Lists
+ */
+func (s *Server) listLists(w http.ResponseWriter, r *http.Request, id string) {
+    start, end, meta := paginate(r, len(s.lists))
+    page := append([]*ac.ListListsList{}, s.lists[start:end]...)
+    writeJSON(w, http.StatusOK, map[string]interface{}{"lists": page, "meta": meta})
+}
+
+func (s *Server) listContactContactLists(w http.ResponseWriter, r *http.Request, id string) {
+    if s.findContact(id) == nil {
+        writeNotFound(w, "Subscriber", id)
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{"contactLists": s.contactContactLists(id)})
+}
+
+// Adds the contact to the list, or updates their status if they're already
+// in it
+func (s *Server) createContactList(w http.ResponseWriter, r *http.Request, id string) {
+    var m ac.UpdateContactList
+    if err := decodeBody(r, &m); err != nil {
+        writeBadRequest(w, err)
+        return
+    }
+    if !s.validateRelated(w, "Subscriber", m.ContactList.Contact, "contact") ||
+        !s.validateRelated(w, "List", m.ContactList.List, "list") {
+        return
+    }
+    cl := s.setContactListStatus(m.ContactList.Contact, m.ContactList.List, m.ContactList.Status)
+    writeJSON(w, http.StatusOK, map[string]interface{}{"contactList": cl})
+}
+
+/*
+ * Validation
+ */
+func validateEmail(w http.ResponseWriter, email string) bool {
+    if strings.TrimSpace(email) == "" {
+        writeValidation(w, "field_missing", "Email address cannot be blank", "/data/attributes/email")
+        return false
+    }
+    at := strings.Index(email, "@")
+    if at < 1 || at == len(email) - 1 {
+        writeValidation(w, "email_invalid", "Email address is not valid", "/data/attributes/email")
+        return false
+    }
+    return true
+}
+
+func (s *Server) validateFieldValues(w http.ResponseWriter, values []ac.UpdateContactContactFieldValue) bool {
+    for _, v := range values {
+        if s.findField(v.Field) == nil {
+            writeValidation(w, "field_invalid", "No Result found for Field with id " + v.Field,
+                "/data/attributes/fieldValues")
+            return false
+        }
+    }
+    return true
+}
+
+// Checks that a related item given by ID exists
+func (s *Server) validateRelated(w http.ResponseWriter, kind string, id string, attribute string) bool {
+    var found bool
+    switch kind {
+    case "Subscriber":
+        found = s.findContact(id) != nil
+    case "Tag":
+        found = s.findTag(id) != nil
+    case "Automation":
+        found = s.findAutomation(id) != nil
+    case "List":
+        found = s.findList(id) != nil
+    }
+    if !found {
+        writeValidation(w, "related_missing", "No Result found for " + kind + " with id " + id,
+            "/data/attributes/" + attribute)
+    }
+    return found
+}
+
+func parseFilterDate(v string) (time.Time, bool) {
+    for _, layout := range filterDateLayouts {
+        if t, err := time.Parse(layout, v); err == nil {
+            return t, true
+        }
+    }
+    return time.Time{}, false
+}
+
+func containsFold(s string, substr string) bool {
+    return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func idLess(a string, b string) bool {
+    na, errA := strconv.Atoi(a)
+    nb, errB := strconv.Atoi(b)
+    if errA != nil || errB != nil {
+        return a < b
+    }
+    return na < nb
+}
+
+// AC sends an empty list rather than null
+func nonNilContacts(l []*ac.ListContactsContact) []*ac.ListContactsContact {
+    if l == nil {
+        return []*ac.ListContactsContact{}
+    }
+    return l
+}
@@ -0,0 +1,246 @@
+// Package actest runs an in-memory fake of the ActiveCampaign v3 API, so the
+// activecampaign package and the webhooks using it can be tested without a
+// real account or secrets file.
+//
+// It covers contacts, tags, contactTags, automations, contactAutomations,
+// notes, custom fields, lists and contactLists, with the same pagination
+// metadata and error bodies as AC. Seed it with Seed() or LoadFixtures(), then point a client
+// at it:
+//
+//     s := actest.NewServer()
+//     defer s.Close()
+//     s.Seed(actest.Fixtures{Contacts: []actest.ContactFixture{{Email: "tester@example.com"}}})
+//     c := s.Client()
+package actest
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "regexp"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+const (
+    DEFAULT_API_TOKEN = "actest-token"
+    DEFAULT_PAGE_LIMIT = 20 // what AC returns without a limit
+    DATE_LAYOUT = "2006-01-02T15:04:05-07:00"
+)
+
+var regexId = regexp.MustCompile(`^\d+$`)
+
+type Server struct {
+    *httptest.Server
+    ApiToken    string // requests with any other Api-Token get a 403
+    Now         func() time.Time // used for created and updated dates
+
+    mutex               sync.Mutex
+    lastId              int
+    requests            []string
+    contacts            []*ac.ListContactsContact
+    tags                []*ac.ListTagsTag
+    contactTags         []*ac.ListContactTagsTag
+    automations         []*ac.ListAutomationsAutomation
+    contactAutomations  []*ac.ListContactAutomationsContact
+    notes               []*ac.Note
+    fields              []*ac.ListFieldsField
+    fieldValues         []*ac.RetrieveContactFieldValue
+    lists               []*ac.ListListsList
+    contactLists        []*ac.RetrieveContactList
+}
+
+// Starts an empty fake server. Call Close() when done with it.
+func NewServer() *Server {
+    s := &Server{ApiToken: DEFAULT_API_TOKEN, Now: time.Now}
+    s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+    return s
+}
+
+// Returns a client for the fake server. Rate limiting and retries are
+// turned off so tests run quickly and see errors right away.
+func (s *Server) Client() *ac.Client {
+    c := ac.NewClient(s.URL, s.ApiToken)
+    c.RateLimit = 0
+    c.MaxAttempts = 1
+    return c
+}
+
+// Returns every request made so far as "METHOD /path"
+func (s *Server) Requests() []string {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    return append([]string(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    s.requests = append(s.requests, r.Method + " " + r.URL.Path)
+
+    if r.Header.Get("Api-Token") != s.ApiToken {
+        writeMessage(w, http.StatusForbidden, "You are not authorized to access this resource")
+        return
+    }
+    if !strings.HasPrefix(r.URL.Path, ac.API_URL_SUFFIX) {
+        writeMessage(w, http.StatusNotFound, "Not Found")
+        return
+    }
+
+    // Match on the path with IDs replaced, e.g. "GET contacts/:id/notes"
+    path := strings.Trim(strings.TrimPrefix(r.URL.Path, ac.API_URL_SUFFIX), "/")
+    parts := strings.Split(path, "/")
+    var ids []string
+    for i, p := range parts {
+        if regexId.MatchString(p) {
+            ids = append(ids, p)
+            parts[i] = ":id"
+        }
+    }
+    id := ""
+    if len(ids) > 0 {
+        id = ids[0]
+    }
+    route := r.Method + " " + strings.Join(parts, "/")
+
+    h, ok := routes[route]
+    if !ok {
+        writeMessage(w, http.StatusNotFound, "Not Found")
+        return
+    }
+    h(s, w, r, id)
+}
+
+type handler func(s *Server, w http.ResponseWriter, r *http.Request, id string)
+
+var routes = map[string]handler{
+    "GET ": (*Server).getRoot,
+    "GET contacts": (*Server).listContacts,
+    "POST contacts": (*Server).createContact,
+    "POST contact/sync": (*Server).syncContact,
+    "GET contacts/:id": (*Server).getContact,
+    "PUT contacts/:id": (*Server).updateContact,
+    "DELETE contacts/:id": (*Server).deleteContact,
+    "GET contacts/:id/contactTags": (*Server).listContactTags,
+    "GET contacts/:id/contactAutomations": (*Server).listContactContactAutomations,
+    "GET contacts/:id/notes": (*Server).listContactNotes,
+    "GET contacts/:id/fieldValues": (*Server).listContactFieldValues,
+    "GET contacts/:id/contactLists": (*Server).listContactContactLists,
+    "GET tags": (*Server).listTags,
+    "POST tags": (*Server).createTag,
+    "GET tags/:id": (*Server).getTag,
+    "POST contactTags": (*Server).createContactTag,
+    "DELETE contactTags/:id": (*Server).deleteContactTag,
+    "GET automations": (*Server).listAutomations,
+    "GET automations/:id/contactAutomations": (*Server).listAutomationContactAutomations,
+    "POST contactAutomations": (*Server).createContactAutomation,
+    "GET contactAutomations/:id": (*Server).getContactAutomation,
+    "DELETE contactAutomations/:id": (*Server).deleteContactAutomation,
+    "GET contactAutomations/:id/contact": (*Server).getContactAutomationContact,
+    "POST notes": (*Server).createNote,
+    "GET notes/:id": (*Server).getNote,
+    "PUT notes/:id": (*Server).updateNote,
+    "DELETE notes/:id": (*Server).deleteNote,
+    "GET fields": (*Server).listFields,
+    "GET lists": (*Server).listLists,
+    "POST contactLists": (*Server).createContactList,
+}
+
+/*
+ * Helpers
+ */
+func (s *Server) newId() string {
+    s.lastId++
+    return strconv.Itoa(s.lastId)
+}
+
+// Keeps generated IDs from colliding with seeded ones
+func (s *Server) useId(id string) string {
+    if id == "" {
+        return s.newId()
+    }
+    if n, err := strconv.Atoi(id); err == nil && n > s.lastId {
+        s.lastId = n
+    }
+    return id
+}
+
+func (s *Server) now() string {
+    return s.Now().Format(DATE_LAYOUT)
+}
+
+func (s *Server) link(format string, args ...interface{}) string {
+    return s.URL + ac.API_URL_SUFFIX + fmt.Sprintf(format, args...)
+}
+
+// AC's metadata for list responses. The total is a string, like AC sends.
+type listMeta struct {
+    Total       string                  `json:"total"`
+    PageInput   map[string]interface{}  `json:"page_input,omitempty"`
+}
+
+// Returns the slice bounds for the page requested with limit and offset,
+// along with the metadata to send
+func paginate(r *http.Request, total int) (int, int, listMeta) {
+    q := r.URL.Query()
+    limit, err := strconv.Atoi(q.Get("limit"))
+    if err != nil || limit < 1 {
+        limit = DEFAULT_PAGE_LIMIT
+    }
+    if limit > ac.API_LIMIT_MAXIMUM {
+        limit = ac.API_LIMIT_MAXIMUM
+    }
+    offset, err := strconv.Atoi(q.Get("offset"))
+    if err != nil || offset < 0 {
+        offset = 0
+    }
+    start, end := offset, offset + limit
+    if start > total {
+        start = total
+    }
+    if end > total {
+        end = total
+    }
+    meta := listMeta{
+        Total: strconv.Itoa(total),
+        PageInput: map[string]interface{}{"limit": limit, "offset": offset},
+    }
+    return start, end, meta
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+    return json.NewDecoder(r.Body).Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+// Error body for 403s and 404s
+func writeMessage(w http.ResponseWriter, status int, message string) {
+    writeJSON(w, status, map[string]string{"message": message})
+}
+
+func writeNotFound(w http.ResponseWriter, kind string, id string) {
+    writeMessage(w, http.StatusNotFound, fmt.Sprintf("No Result found for %s with id %s", kind, id))
+}
+
+// Error body for 422s
+func writeValidation(w http.ResponseWriter, code string, title string, pointer string) {
+    e := ac.ErrorResponseError{Title: title, Code: code}
+    e.Source.Pointer = pointer
+    writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+        "errors": []ac.ErrorResponseError{e},
+    })
+}
+
+func writeBadRequest(w http.ResponseWriter, err error) {
+    writeMessage(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %s", err))
+}
@@ -0,0 +1,168 @@
+package actest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign/actest"
+)
+
+func newFixtureServer(t *testing.T) (*actest.Server, *ac.Client) {
+	s := actest.NewServer()
+	if err := s.LoadFixtures("testdata/fixtures.yml"); err != nil {
+		s.Close()
+		t.Fatalf("LoadFixtures() returned error: %v", err)
+	}
+	return s, s.Client()
+}
+
+func TestContacts(t *testing.T) {
+	s, client := newFixtureServer(t)
+	defer s.Close()
+
+	c, err := client.GetContactByEmail("TESTER@example.com")
+	if err != nil || c.Id != "42" || c.FirstName != "Test" {
+		t.Fatalf("GetContactByEmail() == (%+v, %v)", c, err)
+	}
+	_, err = client.GetContactByEmail("nobody@example.com")
+	if !errors.Is(err, ac.ErrNotFound) {
+		t.Errorf("GetContactByEmail() of missing contact == %v, want ErrNotFound", err)
+	}
+	_, err = client.GetContactById("999")
+	if !errors.Is(err, ac.ErrNotFound) {
+		t.Errorf("GetContactById() of missing contact == %v, want ErrNotFound", err)
+	}
+
+	err = client.UpdateContactEmail("42", "other@example.com")
+	if !errors.Is(err, ac.ErrConflict) {
+		t.Errorf("UpdateContactEmail() to a taken email == %v, want ErrConflict", err)
+	}
+	if err := client.UpdateContactCustomField(c, "CHANGED_EMAIL", "new@example.com"); err != nil {
+		t.Fatalf("UpdateContactCustomField() returned error: %v", err)
+	}
+	v, err := client.GetContactFieldValue("42", "Changed Email")
+	if err != nil || v != "new@example.com" {
+		t.Errorf("GetContactFieldValue() == (%q, %v)", v, err)
+	}
+
+	synced, created, err := client.CreateOrUpdateContact(ac.ContactDetails{Email: "new@example.com",
+		FirstName: "New"})
+	if err != nil || !created || synced.FirstName != "New" {
+		t.Errorf("CreateOrUpdateContact() == (%+v, %t, %v)", synced, created, err)
+	}
+	if err := client.DeleteContact(synced.Id); err != nil {
+		t.Errorf("DeleteContact() returned error: %v", err)
+	}
+	if s.ContactByEmail("new@example.com") != nil {
+		t.Errorf("Contact still exists after DeleteContact()")
+	}
+
+	wrong := ac.NewClient(s.URL, "wrong-token")
+	wrong.MaxAttempts = 1
+	_, err = wrong.GetContactByEmail("tester@example.com")
+	if !errors.Is(err, ac.ErrUnauthorized) {
+		t.Errorf("GetContactByEmail() with wrong token == %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestContactsPagination(t *testing.T) {
+	s := actest.NewServer()
+	defer s.Close()
+	var f actest.Fixtures
+	for i := 0; i < 250; i++ {
+		f.Contacts = append(f.Contacts, actest.ContactFixture{
+			Email: fmt.Sprintf("student%d@example.com", i),
+			Tags:  []string{"SJC_Enrolled"},
+		})
+	}
+	f.Contacts = append(f.Contacts, actest.ContactFixture{Email: "untagged@example.com"})
+	if err := s.Seed(f); err != nil {
+		t.Fatalf("Seed() returned error: %v", err)
+	}
+
+	contacts, err := s.Client().GetContactsByTag("SJC_Enrolled")
+	if err != nil {
+		t.Fatalf("GetContactsByTag() returned error: %v", err)
+	}
+	if len(contacts) != 250 || contacts[249].Email != "student249@example.com" {
+		t.Errorf("GetContactsByTag() returned %d contacts", len(contacts))
+	}
+}
+
+func TestTagsAndAutomations(t *testing.T) {
+	s, client := newFixtureServer(t)
+	defer s.Close()
+
+	added, err := client.AddTagsToContact("42", []string{"SJC_Enrolled", "SJC_Cancelled", "SJC_New"}, true)
+	if err != nil || added != 2 {
+		t.Errorf("AddTagsToContact() == (%d, %v), want 2 added", added, err)
+	}
+	removed, err := client.RemoveTagFromContactByName("42", "SJC_Enrolled")
+	if err != nil || !removed {
+		t.Errorf("RemoveTagFromContactByName() == (%t, %v)", removed, err)
+	}
+	if got := fmt.Sprint(s.ContactTags("42")); got != "[SJC_Cancelled SJC_New]" {
+		t.Errorf("Contact has tags %s", got)
+	}
+
+	l, err := client.GetContactAutomationAssociations("42")
+	if err != nil || len(l) != 2 || l[0].IsCompleted || !l[1].IsCompleted {
+		t.Errorf("GetContactAutomationAssociations() == (%+v, %v)", l, err)
+	}
+	if err := client.AddContactToAutomationByName("42", "SJC_Renewal_Invitation"); err != nil {
+		t.Errorf("AddContactToAutomationByName() returned error: %v", err)
+	}
+	removed, err = client.RemoveContactFromAutomationByName("42", "SJC_Enrolled")
+	if err != nil || !removed {
+		t.Errorf("RemoveContactFromAutomationByName() == (%t, %v)", removed, err)
+	}
+	if got := fmt.Sprint(s.ContactAutomations("42")); got != "[SJC_Renewal_Invitation]" {
+		t.Errorf("Contact is in automations %s", got)
+	}
+
+	a, err := client.GetAutomationByName("SJC_Renewal_Invitation")
+	if err != nil {
+		t.Fatalf("GetAutomationByName() returned error: %v", err)
+	}
+	cas, err := client.GetAutomationContacts(a)
+	if err != nil || len(cas) != 2 {
+		t.Fatalf("GetAutomationContacts() == (%+v, %v)", cas, err)
+	}
+	contacts, err := client.GetAutomationContactsInfo(cas)
+	if err != nil || len(contacts) != 2 || contacts[0].Email != "tester@example.com" {
+		t.Errorf("GetAutomationContactsInfo() == (%+v, %v)", contacts, err)
+	}
+}
+
+func TestNotes(t *testing.T) {
+	s, client := newFixtureServer(t)
+	defer s.Close()
+
+	added, err := client.AddNoteToContactOnce("42", "Enrolled in SJC")
+	if err != nil || added {
+		t.Errorf("AddNoteToContactOnce() of existing note == (%t, %v)", added, err)
+	}
+	added, err = client.AddNoteToContactOnce("42", "Cancelled SJC")
+	if err != nil || !added {
+		t.Errorf("AddNoteToContactOnce() of new note == (%t, %v)", added, err)
+	}
+	notes, err := client.GetContactNotes("42")
+	if err != nil || len(notes) != 2 {
+		t.Fatalf("GetContactNotes() == (%+v, %v)", notes, err)
+	}
+	if _, err := client.UpdateNoteText(notes[0].Id, "Enrolled in SJC (2020)"); err != nil {
+		t.Errorf("UpdateNoteText() returned error: %v", err)
+	}
+	if err := client.DeleteNote(notes[1].Id); err != nil {
+		t.Errorf("DeleteNote() returned error: %v", err)
+	}
+	if got := fmt.Sprint(s.ContactNotes("42")); got != "[Enrolled in SJC (2020)]" {
+		t.Errorf("Contact has notes %s", got)
+	}
+	_, err = client.GetNote("999")
+	if !errors.Is(err, ac.ErrNotFound) {
+		t.Errorf("GetNote() of missing note == %v, want ErrNotFound", err)
+	}
+}
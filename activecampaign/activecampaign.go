@@ -4,6 +4,7 @@ package activecampaign
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "errors"
     "fmt"
@@ -14,6 +15,7 @@ import (
     "math"
     "strings"
     "regexp"
+    "time"
 
     "github.com/xiam/to"
     "gopkg.in/yaml.v2"
@@ -38,8 +40,18 @@ const (
     API_URL_NOTES = "/notes"
     API_URL_TAGS = "/tags"
     API_URL_AUTOMATIONS = "/automations"
-    API_URL_EVENTS = "/events"
+    API_URL_EVENT_TRACKING = "/eventTracking"
     API_URL_CONTACT_TAGS = "/contactTags"
+    API_URL_FIELDS = "/fields"
+    API_URL_CONTACT_SYNC = "/contact/sync"
+    API_URL_LISTS = "/lists"
+    API_URL_CONTACT_LISTS = "/contactLists"
+    API_URL_CONTACT_AUTOMATIONS = "/contactAutomations"
+    API_URL_DEALS = "/deals"
+    API_URL_DEAL_GROUPS = "/dealGroups"
+    API_URL_DEAL_STAGES = "/dealStages"
+    API_URL_BULK_IMPORT = "/import/bulk_import"
+    API_URL_BULK_IMPORT_STATUS = "/import/info"
 )
 
 const (
@@ -50,21 +62,9 @@ const (
 
 var RegexOffsetParameter = regexp.MustCompile(`(offset)=(\d+)`)
 
-// Custom field names to IDs
-// Fetch these with GET request:
+// Custom fields are looked up by perstag or title, see GetCustomFieldByName()
+// To list them with a GET request:
 // curl --request GET -H "Api-Token: ..." https://nancyhillis.api-us1.com/api/3/fields | jq '.'
-/*
-var CustomFields = map[string]int32{
-    "cid":     1,  // GA Client ID
-    "rid":     8,  // Rainmaker ID
-    "tid":     1,  // Teachable ID
-    "sid":     9,  // Stripe ID
-    "taj_csm": 11, // TAJ Campaign|Source_Medium
-    "sj_csm":  -1, // SJ ...^...
-    "ewc_csm": -1, // EWC ...^...
-    "atc_csm": -1, // ATC ...^...
-}
-*/
 
 var SecretsFilePath = "/var/webhook/secrets/ac_secrets.yml"
 
@@ -72,6 +72,11 @@ type SecretsConfig struct {
     AccountId   string `yaml:"ACCOUNT_ID"`
     ApiUrl      string `yaml:"API_URL"`
     ApiToken    string `yaml:"API_TOKEN"`
+    EventKey    string `yaml:"EVENT_KEY"`   // optional, for TrackEvent()
+    EventActId  string `yaml:"EVENT_ACTID"`
+    WebhookSecret string `yaml:"WEBHOOK_SECRET"` // optional, see CheckSecret()
+    ChangedEmailAutomation string `yaml:"CHANGED_EMAIL_AUTOMATION"` // optional, for the email change webhook
+    ChangedEmailFieldId string `yaml:"CHANGED_EMAIL_FIELD_ID"` // optional, used if its perstag isn't found
 }
 
 var SavedSecretsConfig *SecretsConfig
@@ -81,17 +86,21 @@ func (c *SecretsConfig) GetSecrets(filePath string) (error) {
         c.AccountId = SavedSecretsConfig.AccountId
         c.ApiUrl = SavedSecretsConfig.ApiUrl
         c.ApiToken = SavedSecretsConfig.ApiToken
+        c.EventKey = SavedSecretsConfig.EventKey
+        c.EventActId = SavedSecretsConfig.EventActId
+        c.WebhookSecret = SavedSecretsConfig.WebhookSecret
+        c.ChangedEmailAutomation = SavedSecretsConfig.ChangedEmailAutomation
+        c.ChangedEmailFieldId = SavedSecretsConfig.ChangedEmailFieldId
         return nil
     }
 
     yamlFile, err := ioutil.ReadFile(filePath)
     if err != nil {
-        log.Printf("Failed reading screts file: %s ", err)
+        return fmt.Errorf("Failed reading secrets file '%s': %w", filePath, err)
     }
     err = yaml.Unmarshal(yamlFile, c)
     if err != nil {
-        log.Fatalf("Failed unmarshaling secrets file '%s': %s", filePath, err)
-        return err
+        return fmt.Errorf("Failed unmarshaling secrets file '%s': %w", filePath, err)
     }
 
     if SAVE_API_KEY {
@@ -101,62 +110,20 @@ func (c *SecretsConfig) GetSecrets(filePath string) (error) {
     return nil
 }
 
+// Returns the API URL (with suffix) and token from the default client.
+// Prefer NewClientFromSecrets() or DefaultClient() for new code.
 func GetApiCredentials() (string, string) {
-    var c SecretsConfig
-    err := c.GetSecrets(SecretsFilePath)
+    c, err := DefaultClient()
     if err != nil {
-        log.Fatalf("Could not open YAML secrets file: %s", err.Error())
+        log.Printf("Could not load API credentials: %s", err)
+        return "", ""
     }
-
-    accountId := c.AccountId
-    _ = accountId
-    apiUrl := c.ApiUrl
-    apiToken := c.ApiToken
-
-    // Add suffix
-    url := fmt.Sprintf("%s%s", apiUrl, API_URL_SUFFIX)
-
-    return url, apiToken
+    return c.ApiUrl, c.ApiToken
 }
 
 func AuthenticateWithCredentials(url string, apiToken string) error {
-    //fmt.Println("Auth URL:>", url)
-
-    client := &http.Client{}
-    //var jsonStr = []byte(`{"events":[{"email":"dagoodma@gmail.com","action":"TEst event"}]}`)
-    //req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-    req, err := http.NewRequest("GET", url, nil)
-    if err != nil {
-        //log.Fatalln(err)
-        return err
-    }
-    //req.Header.Set("Content-Type", "application/json")
-    req.Header.Set("User-Agent", USER_AGENT)
-    req.Header.Set("Api-Token", apiToken)
-
-    resp, err := client.Do(req)
-    if err != nil {
-        //log.Fatalln(err)
-        return err
-    }
-
-    defer resp.Body.Close()
-
-    //log.Println("response Status:", resp.Status)
-    //log.Println("response Headers:", resp.Header)
-
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        //log.Fatalln(err)
-        return err
-    }
-    _ = body
-
-    if resp.StatusCode != 200 {
-        return HandleBadResponse(resp, body, 200)
-    }
-
-    return nil
+    c := NewClient(strings.TrimSuffix(url, API_URL_SUFFIX), apiToken)
+    return c.Authenticate()
 }
 
 func BuildRequestUrl(apiUrl string, requestSuffix string, pathParts ...string) (*url.URL, error) {
@@ -169,8 +136,7 @@ func BuildRequestUrl(apiUrl string, requestSuffix string, pathParts ...string) (
     }
     u, err := url.ParseRequestURI(urlRaw)
     if err != nil {
-        msg := fmt.Sprintf("Failed parsing request url '%s': %s", urlRaw, err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed parsing request url '%s': %w", urlRaw, err)
     }
     if u.Host == "" {
         msg := fmt.Sprintf("Request url '%s' is missing host", u)
@@ -183,6 +149,20 @@ func BuildRequestUrl(apiUrl string, requestSuffix string, pathParts ...string) (
     return u, nil
 }
 
+// Fields that contact searches can be sorted by, see QueryParameters.OrderBy
+const (
+    CONTACT_ORDER_ID = "id"
+    CONTACT_ORDER_CREATED = "cdate"
+    CONTACT_ORDER_EMAIL = "email"
+    CONTACT_ORDER_FIRST_NAME = "first_name"
+    CONTACT_ORDER_LAST_NAME = "last_name"
+    CONTACT_ORDER_NAME = "name"
+    CONTACT_ORDER_SCORE = "score"
+)
+
+// Layout for the created/updated date filters
+const QUERY_DATE_LAYOUT = "2006-01-02T15:04:05-07:00"
+
 type QueryParameters struct {
     Email       string
     Id          int
@@ -193,6 +173,28 @@ type QueryParameters struct {
     FetchAll    bool
     AutomationName string
     ExactMatch  bool
+
+    // Contact search filters, zero values are ignored
+    EmailLike       string // contacts whose email contains this
+    ListId          int
+    ListStatus      *int // see LIST_STATUS_*, nil for any
+    AutomationId    int // contacts that have been in the automation
+    CreatedBefore   time.Time
+    CreatedAfter    time.Time
+    UpdatedBefore   time.Time
+    UpdatedAfter    time.Time
+    OrderBy         string // see CONTACT_ORDER_*
+    OrderDesc       bool
+}
+
+var validContactOrders = map[string]bool{
+    CONTACT_ORDER_ID: true,
+    CONTACT_ORDER_CREATED: true,
+    CONTACT_ORDER_EMAIL: true,
+    CONTACT_ORDER_FIRST_NAME: true,
+    CONTACT_ORDER_LAST_NAME: true,
+    CONTACT_ORDER_NAME: true,
+    CONTACT_ORDER_SCORE: true,
 }
 
 func BuildQueryWithParams(params QueryParameters) (*url.Values, error) {
@@ -225,189 +227,95 @@ func BuildQueryWithParams(params QueryParameters) (*url.Values, error) {
         }
         q.Set("limit", to.String(limit))
     }
-    return &q, nil
-}
-
-type ApiRequestResult struct {
-    Data []byte
-    Error error
-}
-
-// Handled a POST request with JSON data
-func DoApiRequestPost(requestUrl string, apiToken string, data []byte) (*ApiRequestResult) {
-    r := &ApiRequestResult{Data: nil, Error: nil}
-
-    if DEBUG {
-        log.Printf("Putting %d bytes of data to url: %s", len(data), requestUrl)
+    if params.EmailLike != "" {
+        q.Set("email_like", params.EmailLike)
     }
-
-    client := &http.Client{}
-    req, err := http.NewRequest(http.MethodPost, requestUrl, bytes.NewBuffer(data))
-    if err != nil {
-        r.Error = err
-        return r
+    if params.ListId != 0 {
+        q.Set("listid", to.String(params.ListId))
     }
-    req.Header.Set("Content-Type", "application/json; charset=utf-8")
-    //req.Header.Set("Content-Type", "application/json")
-    req.Header.Set("User-Agent", USER_AGENT)
-    req.Header.Set("Api-Token", apiToken)
-
-    resp, err := client.Do(req)
-    if err != nil {
-        r.Error = err
-        return r
+    if params.ListStatus != nil {
+        q.Set("status", to.String(*params.ListStatus))
     }
-
-    defer resp.Body.Close()
-
-    //log.Println("response Status:", resp.Status)
-    //log.Println("response Headers:", resp.Header)
-
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        r.Error = err
-        return r
+    if params.AutomationId != 0 {
+        q.Set("seriesid", to.String(params.AutomationId))
     }
-    //log.Println("Got body: ", to.String(body))
-
-    if resp.StatusCode != 201 {
-        r.Error = HandleBadResponse(resp, body, 200)
-        return r
+    setQueryDate(q, "filters[created_before]", params.CreatedBefore)
+    setQueryDate(q, "filters[created_after]", params.CreatedAfter)
+    setQueryDate(q, "filters[updated_before]", params.UpdatedBefore)
+    setQueryDate(q, "filters[updated_after]", params.UpdatedAfter)
+    if params.OrderBy != "" {
+        if !validContactOrders[params.OrderBy] {
+            return nil, fmt.Errorf("Invalid sort order: %s", params.OrderBy)
+        }
+        order := "ASC"
+        if params.OrderDesc {
+            order = "DESC"
+        }
+        q.Set(fmt.Sprintf("orders[%s]", params.OrderBy), order)
     }
-    r.Data = []byte(body)
-    return r
+    return &q, nil
 }
-// Handled a PUT request with JSON data
-func DoApiRequestPut(requestUrl string, apiToken string, data []byte) (*ApiRequestResult) {
-    r := &ApiRequestResult{Data: nil, Error: nil}
 
-    if DEBUG {
-        log.Printf("Putting %d bytes of data to url: %s", len(data), requestUrl)
-    }
-
-    client := &http.Client{}
-    req, err := http.NewRequest(http.MethodPut, requestUrl, bytes.NewBuffer(data))
-    if err != nil {
-        r.Error = err
-        return r
-    }
-    req.Header.Set("Content-Type", "application/json; charset=utf-8")
-    //req.Header.Set("Content-Type", "application/json")
-    req.Header.Set("User-Agent", USER_AGENT)
-    req.Header.Set("Api-Token", apiToken)
-
-    resp, err := client.Do(req)
-    if err != nil {
-        r.Error = err
-        return r
+func setQueryDate(q url.Values, key string, t time.Time) {
+    if !t.IsZero() {
+        q.Set(key, t.Format(QUERY_DATE_LAYOUT))
     }
-
-    defer resp.Body.Close()
-
-    //log.Println("response Status:", resp.Status)
-    //log.Println("response Headers:", resp.Header)
-
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        r.Error = err
-        return r
-    }
-    //log.Println("Got body: ", to.String(body))
-
-    if resp.StatusCode != 200 {
-        r.Error = HandleBadResponse(resp, body, 200)
-        return r
-    }
-    r.Data = []byte(body)
-    return r
 }
 
-
-func DoApiRequestGet(requestUrl string, apiToken string) (*ApiRequestResult) {
-    r := &ApiRequestResult{Data: nil, Error: nil}
-
-    if DEBUG {
-        log.Println(fmt.Sprintf("Querying url: %s", requestUrl))
-    }
-
-    client := &http.Client{}
-    req, err := http.NewRequest("GET", requestUrl, nil)
-    if err != nil {
-        r.Error = err
-        return r
-    }
-    //req.Header.Set("Content-Type", "application/json")
-    req.Header.Set("User-Agent", USER_AGENT)
-    req.Header.Set("Api-Token", apiToken)
-
-    resp, err := client.Do(req)
-    if err != nil {
-        r.Error = err
-        return r
-    }
-
-    defer resp.Body.Close()
-
-    //log.Println("response Status:", resp.Status)
-    //log.Println("response Headers:", resp.Header)
-
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        r.Error = err
-        return r
-    }
-    //log.Println("Got body: ", to.String(body))
-
-    if resp.StatusCode != 200 {
-        r.Error = HandleBadResponse(resp, body, 200)
-        return r
-    }
-    r.Data = []byte(body)
-    return r
+type ApiRequestResult struct {
+    Data []byte
+    Error error
+    StatusCode int
 }
 
+// Turns an unexpected response into an *ApiError, see errors.go
 func HandleBadResponse(resp *http.Response, body []byte, expectedStatusCode int) error {
     if DEBUG && DEBUG_VERBOSE {
         log.Printf("Response: %+v", resp)
         log.Printf("Data (%d bytes): %+v", resp.ContentLength, string(body))
     }
-    var message string
+    e := &ApiError{StatusCode: resp.StatusCode}
     switch resp.StatusCode {
     case 422:
         errorResponse := &ErrorResponse{}
         // Unmarshal the message metedata
         err := json.Unmarshal([]byte(body), errorResponse)
         if err != nil {
-            return fmt.Errorf("Failed to unmarshal error response (%s) from data: %s",
+            return fmt.Errorf("Failed to unmarshal error response (%s) from data: %w",
                 resp.Status, err)
         }
-        message = fmt.Sprintf("(%d): %s", resp.StatusCode, errorResponse)
-    case 401:
+        e.Message = errorResponse.String()
+        e.Errors = errorResponse.Errors
+    case 401, 403, 404:
         errorMessage := &ErrorMessage{}
         // Unmarshal the message metedata
         err := json.Unmarshal([]byte(body), errorMessage)
-        if err != nil {
-            return fmt.Errorf("Failed to unmarshal error message (%s) from data: %s",
-                resp.Status, err)
+        if err != nil || errorMessage.Message == "" {
+            e.Message = resp.Status
+        } else {
+            e.Message = errorMessage.String()
         }
-        message = fmt.Sprintf("(%d): %s", resp.StatusCode, errorMessage)
+    case 429:
+        e.Message = "Too many requests"
     default:
-        message = fmt.Sprintf("Unknown error response status '%d', expected: %d", resp.StatusCode, expectedStatusCode)
+        e.Message = fmt.Sprintf("Unknown error response status '%d', expected: %d", resp.StatusCode, expectedStatusCode)
     }
+    e.Kind = errorKindForStatus(resp.StatusCode, e.Errors)
 
-    return errors.New(message)
+    return e
 }
 
 // r, the ListResponse interface, will be populated, but the list within will be missing data
+// This holds every page in memory, prefer NewPageIterator() for large lists.
 // Refs:
 // - https://guzalexander.com/2013/12/06/golang-channels-tutorial.html
 // - https://gist.github.com/montanaflynn/ea4b92ed640f790c4b9cee36046a5383
-func FetchAllEndpointDataAsync(u *url.URL, q *url.Values, r ListResponse, apiToken string) ([]ApiRequestResult, error) {
+func (c *Client) FetchAllEndpointDataAsync(u *url.URL, q *url.Values, r ListResponse) ([]ApiRequestResult, error) {
     // Set initial offset and limit for discovery request
     q.Set("offset", "0")
     q.Set("limit", to.String(API_LIMIT_MINIMUM))
     u.RawQuery = q.Encode()
-    resp := DoApiRequestGet(u.String(), apiToken)
+    resp := c.DoApiRequestGet(u.String())
     if resp.Error != nil {
         return nil, resp.Error
     }
@@ -415,14 +323,13 @@ func FetchAllEndpointDataAsync(u *url.URL, q *url.Values, r ListResponse, apiTok
     // Unmarshal the message metedata
     err := json.Unmarshal(resp.Data, r)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
     //m := result.Metadata
     //total := m.Total
     total := r.totalResults()
     if total < 1 {
-        msg := fmt.Sprintf("Could not find any endpoint data with query: %#v", q)
-        return nil, errors.New(msg)
+        return nil, newNotFoundError("Could not find any endpoint data with query: %#v", q)
     }
     pageTotal := int(math.Ceil(float64(total) / float64(API_LIMIT_MAXIMUM)))
 
@@ -435,7 +342,7 @@ func FetchAllEndpointDataAsync(u *url.URL, q *url.Values, r ListResponse, apiTok
 
     // Use goroutine to request results concurrently
     // this buffered channel will block at the concurrency limit
-    semaphoreChan := make(chan struct{}, REQUEST_CONCURRENCY_LIMIT )
+    semaphoreChan := make(chan struct{}, c.concurrencyLimit())
     // this channel will not block and collect the http request results
     resultsChan := make(chan *ApiRequestResult)
 
@@ -471,7 +378,7 @@ func FetchAllEndpointDataAsync(u *url.URL, q *url.Values, r ListResponse, apiTok
             // send the request and put the response in a result struct
             // along with the index so we can sort them later along with
             // any error that might have occoured
-            result := DoApiRequestGet(requestUrl, apiToken)
+            result := c.DoApiRequestGet(requestUrl)
             // now we can send the result struct through the resultsChan
             resultsChan <- result
 
@@ -501,70 +408,52 @@ func FetchAllEndpointDataAsync(u *url.URL, q *url.Values, r ListResponse, apiTok
     return results, nil
 }
 
-func GetContactsAsync(params QueryParameters) (*ListContacts, error) {
+func (c *Client) GetContactsAsync(params QueryParameters) (*ListContacts, error) {
     if (!params.FetchAll) {
-        return GetContacts(params)
+        return c.GetContacts(params)
     }
 
-    apiUrl, apiToken := GetApiCredentials()
     result := &ListContacts{}
     var resultList []ListContactsContact // Replace result list with empty one later
 
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_CONTACTS)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS)
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request query variables 
     q, err := BuildQueryWithParams(params)
     if err != nil {
-        return nil, fmt.Errorf("Failed parsing query parameters '%#v': %s", params, err)
-    }
-
-    // Fetch all endpoint data asynchronously
-    results, err := FetchAllEndpointDataAsync(u, q, result, apiToken)
-    if err != nil {
-        return nil, fmt.Errorf("Failed fetching all endpoint data asynchronously: %s", err)
+        return nil, fmt.Errorf("Failed parsing query parameters '%#v': %w", params, err)
     }
 
-    // Receive results from the channel and unmarshal them
-    errorCount := 0
-    var errorStrings []string
-    for _, r := range(results) {
-        if r.Error != nil {
-            errorCount += 1
-            errorStrings = append(errorStrings, r.Error.Error())
-            continue
-        } else {
-            l := &ListContacts{}
-            err = json.Unmarshal(r.Data, &l)
-            if err != nil {
-                msg := fmt.Sprintf("Failed to unmarshal response data: %s", err)
-                errorCount += 1
-                errorStrings = append(errorStrings, msg)
-                continue
-            }
-            if DEBUG {
-                log.Printf("Adding %d contacts to %d contacts...",
-                    len(l.Contacts), len(resultList))
-            }
-            resultList = append(resultList, l.Contacts...)
+    // Iterate through all pages
+    it := c.NewPageIterator(context.Background(), u, q,
+        func() ListResponse { return &ListContacts{} }, PageIteratorOptions{})
+    defer it.Close()
+    for it.Next() {
+        l := it.Page().(*ListContacts)
+        if len(resultList) < 1 {
+            result.Metadata = l.Metadata
+        }
+        if DEBUG {
+            log.Printf("Adding %d contacts to %d contacts...",
+                len(l.Contacts), len(resultList))
         }
+        resultList = append(resultList, l.Contacts...)
     }
-    if len(errorStrings) > 0 {
-        log.Printf("Finished parsing all response data from endpoint '%s'," +
-            " but encountered %d errors:", u.String(), len(errorStrings))
-        log.Println(errorStrings)
+    if err := it.Err(); err != nil {
+        return nil, fmt.Errorf("Failed fetching all contacts: %w", err)
+    }
+    if len(resultList) < 1 {
+        return nil, newNotFoundError("Could not find any contacts with params: %#v", params)
     }
     result.Contacts = resultList
     return result, nil
 }
 
-func GetContacts(params QueryParameters) (*ListContacts, error) {
-    apiUrl, apiToken := GetApiCredentials()
-    _ = apiToken
-
+func (c *Client) GetContacts(params QueryParameters) (*ListContacts, error) {
     r := &ListContacts{}
     var l []ListContactsContact
 
@@ -574,17 +463,15 @@ func GetContacts(params QueryParameters) (*ListContacts, error) {
     }
 
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_CONTACTS)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS)
     if err != nil {
-        msg := fmt.Sprintf("Failed building request url: %s", err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request query variables 
     q, err := BuildQueryWithParams(params)
     if err != nil {
-        msg := fmt.Sprintf("Failed parsing query parameters '%#v': %s", params, err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed parsing query parameters '%#v': %w", params, err)
     }
 
     offset := 0
@@ -597,7 +484,7 @@ func GetContacts(params QueryParameters) (*ListContacts, error) {
         }
         u.RawQuery = q.Encode()
         requestUrl := u.String()
-        result := DoApiRequestGet(requestUrl, apiToken)
+        result := c.DoApiRequestGet(requestUrl)
         if result.Error != nil {
             return nil, result.Error
         }
@@ -606,7 +493,7 @@ func GetContacts(params QueryParameters) (*ListContacts, error) {
         t := &ListContacts{}
         err = json.Unmarshal(result.Data, &t)
         if err != nil {
-            return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+            return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
         }
         pages++
         var limit int32 = t.Metadata.PageInput.Limit
@@ -626,8 +513,7 @@ func GetContacts(params QueryParameters) (*ListContacts, error) {
         }
 
         if t.Metadata.Total < 1 {
-            msg := fmt.Sprintf("Could not find any contacts with params: %#v", params)
-            return nil, errors.New(msg)
+            return nil, newNotFoundError("Could not find any contacts with params: %#v", params)
         }
         //log.Printf("Adding %d contacts to %d contacts...", len(l), len(t.Contacts))
         l = append(l, t.Contacts...)
@@ -657,22 +543,19 @@ func GetContacts(params QueryParameters) (*ListContacts, error) {
     return r, nil
 }
 
-func GetContact(params QueryParameters) (*RetrieveContact, error) {
-    apiUrl, apiToken := GetApiCredentials()
-    _ = apiToken
-
+func (c *Client) GetContact(params QueryParameters) (*RetrieveContact, error) {
     if params.Id == 0 {
         return nil, fmt.Errorf("No contact ID specified")
     }
 
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_CONTACTS, to.String(params.Id))
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, to.String(params.Id))
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
     requestUrl := u.String()
-    result := DoApiRequestGet(requestUrl, apiToken)
+    result := c.DoApiRequestGet(requestUrl)
     if result.Error != nil {
         return nil, result.Error
     }
@@ -681,154 +564,169 @@ func GetContact(params QueryParameters) (*RetrieveContact, error) {
     r := &RetrieveContact{}
     err = json.Unmarshal(result.Data, &r)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
 
     return r, nil
 }
 
 
-func GetAutomationsAsync(params QueryParameters) (*ListAutomations, error) {
+func (c *Client) GetAutomationsAsync(params QueryParameters) (*ListAutomations, error) {
     if (!params.FetchAll) {
         return nil, fmt.Errorf("FetchAll must be set to true for GetAutomationsAsync()")
     }
 
-    apiUrl, apiToken := GetApiCredentials()
     result := &ListAutomations{}
     var resultList []ListAutomationsAutomation // Replace result list with empty one later
 
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_AUTOMATIONS)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_AUTOMATIONS)
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request query variables 
     q, err := BuildQueryWithParams(params)
     if err != nil {
-        return nil, fmt.Errorf("Failed parsing query parameters '%#v': %s", params, err)
+        return nil, fmt.Errorf("Failed parsing query parameters '%#v': %w", params, err)
     }
 
-    // Fetch all endpoint data asynchronously
-    results, err := FetchAllEndpointDataAsync(u, q, result, apiToken)
-    if err != nil {
-        return nil, fmt.Errorf("Failed fetching all endpoint data asynchronously: %s", err)
-    }
-
-    // Unmarshal and inspect the results
-    errorCount := 0
-    var errorStrings []string
-    for _, r := range(results) {
-        if r.Error != nil {
-            errorCount += 1
-            errorStrings = append(errorStrings, r.Error.Error())
-            continue
-        } else {
-            l := &ListAutomations{}
-            err = json.Unmarshal(r.Data, &l)
-            if err != nil {
-                msg := fmt.Sprintf("Failed to unmarshal GetAutomations response data: %s", err)
-                errorCount += 1
-                errorStrings = append(errorStrings, msg)
-                continue
+    // Iterate through all pages
+    it := c.NewPageIterator(context.Background(), u, q,
+        func() ListResponse { return &ListAutomations{} }, PageIteratorOptions{})
+    defer it.Close()
+    for it.Next() {
+        l := it.Page().(*ListAutomations)
+        if result.Metadata.Total == 0 {
+            result.Metadata = l.Metadata
+        }
+        if params.AutomationName == "" {
+            // Return all automations (no name comparing)
+            if DEBUG {
+                log.Printf("Adding all %d automations to %d automations...",
+                    len(l.Automations), len(resultList))
             }
-            if params.AutomationName == "" {
-                // Return all automations (no name comparing)
-                if DEBUG {
-                    log.Printf("Adding all %d automations to %d automations...",
-                        len(l.Automations), len(resultList))
-                }
-                resultList = append(resultList, l.Automations...)
-            } else {
-                // Only return automations with the given name
-                for _, a := range l.Automations {
-                    if (params.ExactMatch && a.Name == params.AutomationName) ||
-                       (!params.ExactMatch && strings.Contains(a.Name, params.AutomationName)) {
-                        resultList = append(resultList, a)
-                    }
+            resultList = append(resultList, l.Automations...)
+        } else {
+            // Only return automations with the given name
+            for _, a := range l.Automations {
+                if (params.ExactMatch && a.Name == params.AutomationName) ||
+                   (!params.ExactMatch && strings.Contains(a.Name, params.AutomationName)) {
+                    resultList = append(resultList, a)
                 }
             }
         }
     }
-    if len(errorStrings) > 0 {
-        log.Printf("Finished parsing all response data from endpoint '%s'," +
-            " but encountered %d errors:", u.String(), len(errorStrings))
-        log.Println(errorStrings)
+    if err := it.Err(); err != nil {
+        return nil, fmt.Errorf("Failed fetching all automations: %w", err)
+    }
+    if result.Metadata.Total == 0 {
+        return nil, newNotFoundError("Could not find any automations with params: %#v", params)
     }
     result.Automations = resultList
     return result, nil
 }
 
 // TODO Add ExactMatch support
-func GetTagByName(tag string) (*ListTagsTag, error) {
-    //if (!params.ExactMatch) {
-    //    return nil, fmt.Errorf("ExactMatch must be set to true for GetTagByName()")
-    //}
-
-    apiUrl, apiToken := GetApiCredentials()
+func (c *Client) GetTagByName(tag string) (*ListTagsTag, error) {
+    t, tagNames, err := c.findTagByName(tag)
+    if err != nil {
+        return nil, err
+    }
+    if t == nil {
+        if len(tagNames) < 1 {
+            return nil, newNotFoundError("Could not find any tags with name: %s", tag)
+        }
+        return nil, fmt.Errorf("Found %d tags, but none were an exact match: %s",
+            len(tagNames), strings.Join(tagNames, ", "))
+    }
+    return t, nil
+}
 
+// Searches for a tag with exactly the given name. Returns a nil tag (and no
+// error) along with the names of any partial matches when it's not found.
+func (c *Client) findTagByName(tag string) (*ListTagsTag, []string, error) {
+    if c.Cache != nil {
+        return c.findCachedTagByName(tag)
+    }
     result := &ListTags{}
     var p QueryParameters
     p.Limit = API_LIMIT_MAXIMUM
     p.TagName = tag
 
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_TAGS)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_TAGS)
     if err != nil {
-        msg := fmt.Sprintf("Failed building request url: %s", err)
-        return nil, errors.New(msg)
+        return nil, nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request query variables 
     q, err := BuildQueryWithParams(p)
     if err != nil {
-        msg := fmt.Sprintf("Failed parsing query parameters '%#v': %s", p, err)
-        return nil, errors.New(msg)
+        return nil, nil, fmt.Errorf("Failed parsing query parameters '%#v': %w", p, err)
     }
 
     u.RawQuery = q.Encode()
     requestUrl := u.String()
-    r := DoApiRequestGet(requestUrl, apiToken)
+    r := c.DoApiRequestGet(requestUrl)
     if r.Error != nil {
-        return nil, r.Error
+        return nil, nil, r.Error
     }
 
     // Unmarshal the message metedata
     err = json.Unmarshal(r.Data, &result)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
     m := result.Metadata
     total := m.Total
-    if total < 1 {
-        msg := fmt.Sprintf("Could not find any tags with params: %#v", p)
-        return nil, errors.New(msg)
-    }
     pageTotal := int(math.Ceil(float64(total) / float64(API_LIMIT_MAXIMUM)))
     if pageTotal > 1 {
-        return nil, fmt.Errorf("Too many tags found. Got %d and expected %d at most",
+        return nil, nil, fmt.Errorf("Too many tags found. Got %d and expected %d at most",
             total, API_LIMIT_MAXIMUM)
     }
 
     // Iterate through the results and find an exact match
-    tagNames := ""
+    var tagNames []string
     for _, element := range result.Tags {
         if element.Tag == tag {
-            return &element, nil
-        }
-        delim := ""
-        if len(tagNames) > 0 {
-            delim = ", "
+            return &element, nil, nil
         }
-        tagNames = fmt.Sprintf("%s%s%s", tagNames, delim, element.Tag)
+        tagNames = append(tagNames, element.Tag)
     }
 
-    return nil, fmt.Errorf("Found %d tags, but none were an exact match: %s",
-        total, tagNames)
+    return nil, tagNames, nil
 }
 
-func GetContactsByTag(tag string) ([]ListContactsContact, error) {
-    t, err := GetTagByName(tag)
+// Returns all tags in the account
+func (c *Client) GetAllTags() ([]ListTagsTag, error) {
+    var resultList []ListTagsTag
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_TAGS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Iterate through all pages
+    it := c.NewPageIterator(context.Background(), u, &url.Values{},
+        func() ListResponse { return &ListTags{} }, PageIteratorOptions{})
+    defer it.Close()
+    for it.Next() {
+        l := it.Page().(*ListTags)
+        resultList = append(resultList, l.Tags...)
+    }
+    if err := it.Err(); err != nil {
+        return nil, fmt.Errorf("Failed fetching tags: %w", err)
+    }
+    if DEBUG {
+        log.Printf("Fetched %d tags.", len(resultList))
+    }
+    return resultList, nil
+}
+
+func (c *Client) GetContactsByTag(tag string) ([]ListContactsContact, error) {
+    t, err := c.GetTagByName(tag)
     if err != nil {
         return nil, err
     }
@@ -838,30 +736,61 @@ func GetContactsByTag(tag string) ([]ListContactsContact, error) {
     p.FetchAll = true
     p.Limit = API_LIMIT_MAXIMUM
 
-    r, err := GetContactsAsync(p)
+    r, err := c.GetContactsAsync(p)
     if err != nil {
-        msg := fmt.Sprintf("Failed to get contacts by tag '%s': %s", tag, err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed to get contacts by tag '%s': %w", tag, err)
     }
     if r.Metadata.Total < 1 || len(r.Contacts) < 1 {
-        msg := fmt.Sprintf("No contacts found for tag: %s", tag)
-        return nil, errors.New(msg)
+        return nil, newNotFoundError("No contacts found for tag: %s", tag)
     }
 
     return r.Contacts, nil
 }
 
-func GetAutomationContacts(automation *ListAutomationsAutomation) ([]ListContactAutomationsContact, error) {
-    _, apiToken := GetApiCredentials()
+// Returns all contacts matching the search filters in params, fetching
+// every page. Returns an empty list if nothing matches. For example,
+// contacts with a tag updated in the last week:
+//
+//     p := QueryParameters{TagId: tagId, UpdatedAfter: time.Now().AddDate(0, 0, -7)}
+//     contacts, err := c.SearchContacts(p)
+func (c *Client) SearchContacts(params QueryParameters) ([]ListContactsContact, error) {
+    params.FetchAll = true
+    if params.Limit == 0 {
+        params.Limit = API_LIMIT_MAXIMUM
+    }
+    r, err := c.GetContactsAsync(params)
+    if errors.Is(err, ErrNotFound) {
+        // Nothing matching the filters isn't an error for a search
+        return []ListContactsContact{}, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("Failed searching contacts: %w", err)
+    }
+    return r.Contacts, nil
+}
+
+// Returns contacts with the tag (by name) that match the other filters in params
+func (c *Client) SearchContactsByTag(tag string, params QueryParameters) ([]ListContactsContact, error) {
+    t, err := c.GetTagByName(tag)
+    if err != nil {
+        return nil, err
+    }
+    params.TagId = to.Int(t.Id)
+    contacts, err := c.SearchContacts(params)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to get contacts by tag '%s': %w", tag, err)
+    }
+    return contacts, nil
+}
 
+func (c *Client) GetAutomationContacts(automation *ListAutomationsAutomation) ([]ListContactAutomationsContact, error) {
     result := &ListContactAutomations{}
 
     // Build request URL
     urlRaw := automation.Links.ContactAutomations
     u, err := url.ParseRequestURI(urlRaw)
     if err != nil {
-        msg := fmt.Sprintf("Failed parsing request url '%s': %s", urlRaw, err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed parsing request url '%s': %w", urlRaw, err)
     }
     if u.Host == "" {
         msg := fmt.Sprintf("Request url '%s' is missing host", u)
@@ -876,7 +805,7 @@ func GetAutomationContacts(automation *ListAutomationsAutomation) ([]ListContact
     q := &url.Values{}
     q.Set("limit", to.String(API_LIMIT_MAXIMUM))
     requestUrl := u.String()
-    r := DoApiRequestGet(requestUrl, apiToken)
+    r := c.DoApiRequestGet(requestUrl)
     if r.Error != nil {
         return nil, r.Error
     }
@@ -884,16 +813,14 @@ func GetAutomationContacts(automation *ListAutomationsAutomation) ([]ListContact
     // Unmarshal the message metedata
     err = json.Unmarshal(r.Data, &result)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
 
     return result.ContactAutomations, nil
 }
 
 // This will asynchronously fetch more info on each contact in a ListContactAutomationsContact list
-func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]ListContactsContact, error) {
-    _, apiToken := GetApiCredentials()
-
+func (c *Client) GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]ListContactsContact, error) {
     var result []ListContactsContact
 
     if DEBUG {
@@ -910,7 +837,7 @@ func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]List
 
     // Use goroutine to request results concurrently
     // this buffered channel will block at the concurrency limit
-    semaphoreChan := make(chan struct{}, REQUEST_CONCURRENCY_LIMIT )
+    semaphoreChan := make(chan struct{}, c.concurrencyLimit())
     // this channel will not block and collect the http request results
     resultsChan := make(chan *ApiRequestResult)
 
@@ -920,8 +847,8 @@ func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]List
         close(resultsChan)
     }()
 
-    for _, c := range(contacts) {
-        //log.Printf("Do request for contact %d (id=%d)", i, c.Contact)
+    for _, ca := range(contacts) {
+        //log.Printf("Do request for contact %d (id=%d)", i, ca.Contact)
         go func(contact ListContactAutomationsContact) {
             // this sends an empty struct into the semaphoreChan which
             // is basically saying add one to the limit, but when the
@@ -933,7 +860,7 @@ func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]List
             u, err := url.ParseRequestURI(urlRaw)
             if err != nil {
                 r := &ApiRequestResult{Data: nil,
-                    Error: fmt.Errorf("Failed parsing contact link request url '%s': %s", urlRaw, err)}
+                    Error: fmt.Errorf("Failed parsing contact link request url '%s': %w", urlRaw, err)}
                 resultsChan <- r
                 <-semaphoreChan
                 return
@@ -957,7 +884,7 @@ func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]List
             // send the request and put the response in a result struct
             // along with the index so we can sort them later along with
             // any error that might have occoured
-            r := DoApiRequestGet(requestUrl, apiToken)
+            r := c.DoApiRequestGet(requestUrl)
             // now we can send the result struct through the resultsChan
             resultsChan <- r
 
@@ -966,7 +893,7 @@ func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]List
             // another goroutine to start
             <-semaphoreChan
             //log.Printf("Finished request for page=%d, offset=%d", page, offset)
-        }(c)
+        }(ca)
     }
 
     // make a slice to hold the results we're expecting
@@ -994,8 +921,8 @@ func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]List
             errorStrings = append(errorStrings, r.Error.Error())
             continue
         } else {
-            c := &ListContactAutomationsContactLinksContact{}
-            err := json.Unmarshal(r.Data, &c)
+            l := &ListContactAutomationsContactLinksContact{}
+            err := json.Unmarshal(r.Data, &l)
             if err != nil {
                 msg := fmt.Sprintf("Failed to unmarshal response data: %s", err)
                 errorCount += 1
@@ -1008,7 +935,7 @@ func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]List
                     len(l.Contacts), len(resultList))
             }
             */
-            result = append(result, c.Contact)
+            result = append(result, l.Contact)
         }
     }
     if len(errorStrings) > 0 {
@@ -1019,39 +946,38 @@ func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]List
     return result, nil
 }
 
-func GetAutomationsByName(name string, exactMatch bool) ([]ListAutomationsAutomation, error) {
+func (c *Client) GetAutomationsByName(name string, exactMatch bool) ([]ListAutomationsAutomation, error) {
+    if c.Cache != nil {
+        return c.getCachedAutomationsByName(name, exactMatch)
+    }
     var p QueryParameters
     p.AutomationName = name
     p.FetchAll = true
     p.Limit = API_LIMIT_MAXIMUM
     p.ExactMatch = exactMatch
 
-    r, err := GetAutomationsAsync(p)
+    r, err := c.GetAutomationsAsync(p)
     if err != nil {
-        msg := fmt.Sprintf("Failed to get automations by name '%s': %s", name, err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed to get automations by name '%s': %w", name, err)
     }
     if r.Metadata.Total < 1 || len(r.Automations) < 1 {
-        msg := fmt.Sprintf("No automations found with name: %s", name)
-        return nil, errors.New(msg)
+        return nil, newNotFoundError("No automations found with name: %s", name)
     }
 
     return r.Automations, nil
 }
 
 // This returns the simpler ListContactsContact instead of GetContact (for now)
-func GetContactByEmail(email string) (*ListContactsContact, error) {
+func (c *Client) GetContactByEmail(email string) (*ListContactsContact, error) {
     var p QueryParameters
     p.Email = email
 
-    r, err := GetContacts(p)
+    r, err := c.GetContacts(p)
     if err != nil {
-        msg := fmt.Sprintf("Failed to get contact '%s': %s", email, err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed to get contact '%s': %w", email, err)
     }
     if r.Metadata.Total < 1 || len(r.Contacts) < 1 {
-        msg := fmt.Sprintf("No contacts found for: %s", email)
-        return nil, errors.New(msg)
+        return nil, newNotFoundError("No contacts found for: %s", email)
     }
     if r.Metadata.Total > 1 || len(r.Contacts) > 1 {
         msg := fmt.Sprintf("Found multiple contacts for: %s", email)
@@ -1062,72 +988,60 @@ func GetContactByEmail(email string) (*ListContactsContact, error) {
     return &s, nil
 }
 
-func GetContactById(id string) (*RetrieveContact, error) {
+func (c *Client) GetContactById(id string) (*RetrieveContact, error) {
     var p QueryParameters
     p.Id = to.Int(id)
 
-    r, err := GetContact(p)
+    r, err := c.GetContact(p)
     if err != nil {
-        msg := fmt.Sprintf("Failed to get contact %s: %s", id, err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed to get contact %s: %w", id, err)
     }
 
     return r, nil
 }
 
-func GetContactProfileUrlById(id string) string {
-    var c SecretsConfig
-    err := c.GetSecrets(SecretsFilePath)
-    if err != nil {
-        log.Fatalf("Could not open YAML secrets file: %s", err.Error())
-    }
-    accountId := c.AccountId
-
-    adminUrlPrefix := fmt.Sprintf(ADMIN_PREFIX_URL_FORMAT, accountId)
+func (c *Client) GetContactProfileUrlById(id string) string {
+    adminUrlPrefix := fmt.Sprintf(ADMIN_PREFIX_URL_FORMAT, c.AccountId)
     url := fmt.Sprintf("%s%s/%s", adminUrlPrefix, API_URL_CONTACTS, id)
     return url
 }
 
-func GetContactProfileUrlByEmail(email string) (string, error) {
-    c, err := GetContactByEmail(email)
+func (c *Client) GetContactProfileUrlByEmail(email string) (string, error) {
+    contact, err := c.GetContactByEmail(email)
     if err != nil {
-        msg := fmt.Sprintf("Failed to get profile url: %s", err)
-        return "", errors.New(msg)
+        return "", fmt.Errorf("Failed to get profile url: %w", err)
     }
-    return GetContactProfileUrlById(c.Id), nil
+    return c.GetContactProfileUrlById(contact.Id), nil
 }
 
-func GetTag(id string) (*RetrieveTag, error) {
-    apiUrl, apiToken := GetApiCredentials()
-
+func (c *Client) GetTag(id string) (*RetrieveTag, error) {
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_TAGS, id)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_TAGS, id)
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request query variables 
     //q := &url.Values{}
     //q.Set("limit", to.String(API_LIMIT_MAXIMUM))
     requestUrl := u.String()
-    r := DoApiRequestGet(requestUrl, apiToken)
+    r := c.DoApiRequestGet(requestUrl)
     if r.Error != nil {
-        return nil, fmt.Errorf("Failed retrieving tag with ID %s: %s", id, r.Error)
+        return nil, fmt.Errorf("Failed retrieving tag with ID %s: %w", id, r.Error)
     }
 
     // Unmarshal the message metedata
     t2 := &RetrieveTagContainer{}
     err = json.Unmarshal(r.Data, &t2)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
     t := t2.Tag
 
     return &t, nil
 }
 
-func GetTagsAsync(ids []string) ([]RetrieveTag, error) {
-    apiUrl, apiToken := GetApiCredentials()
+func (c *Client) GetTagsAsync(ids []string) ([]RetrieveTag, error) {
     var resultList []RetrieveTag // Replace result list with empty one later
 
     if DEBUG {
@@ -1136,7 +1050,7 @@ func GetTagsAsync(ids []string) ([]RetrieveTag, error) {
 
     // Use goroutine to request results concurrently
     // this buffered channel will block at the concurrency limit
-    semaphoreChan := make(chan struct{}, REQUEST_CONCURRENCY_LIMIT )
+    semaphoreChan := make(chan struct{}, c.concurrencyLimit())
     // this channel will not block and collect the http request results
     resultsChan := make(chan *ApiRequestResult)
 
@@ -1152,17 +1066,17 @@ func GetTagsAsync(ids []string) ([]RetrieveTag, error) {
             semaphoreChan <- struct{}{}
 
             // Build request URL
-            u, err := BuildRequestUrl(apiUrl, API_URL_TAGS, id)
+            u, err := BuildRequestUrl(c.ApiUrl, API_URL_TAGS, id)
             if err != nil {
                 r := &ApiRequestResult{Data: nil,
-                    Error: fmt.Errorf("Failed building request url: %s",  err)}
+                    Error: fmt.Errorf("Failed building request url: %w",  err)}
                 resultsChan <- r
                 <-semaphoreChan
                 return
             }
             // Do request
             requestUrl := u.String()
-            r := DoApiRequestGet(requestUrl, apiToken)
+            r := c.DoApiRequestGet(requestUrl)
             resultsChan <- r
             <-semaphoreChan
         }(i)
@@ -1214,30 +1128,28 @@ func GetTagsAsync(ids []string) ([]RetrieveTag, error) {
     return resultList, nil
 }
 
-func GetContactTags(id string) ([]RetrieveTag, error) {
-    apiUrl, apiToken := GetApiCredentials()
-
+func (c *Client) GetContactTags(id string) ([]RetrieveTag, error) {
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_CONTACTS, id, API_URL_CONTACT_TAGS)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, id, API_URL_CONTACT_TAGS)
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request query variables 
     //q := &url.Values{}
     //q.Set("limit", to.String(API_LIMIT_MAXIMUM))
     requestUrl := u.String()
-    r := DoApiRequestGet(requestUrl, apiToken)
+    r := c.DoApiRequestGet(requestUrl)
     if r.Error != nil {
         return nil, fmt.Errorf("Failed retrieving list of contact tags" +
-            " for contact with ID %s: %s", id, r.Error)
+            " for contact with ID %s: %w", id, r.Error)
     }
 
     // Unmarshal the message metedata
     l := &ListContactTags{}
     err = json.Unmarshal(r.Data, &l)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
 
     /*
@@ -1247,7 +1159,7 @@ func GetContactTags(id string) ([]RetrieveTag, error) {
         t, err := GetTag(v.Tag)
         if err != nil {
             return nil, fmt.Errorf("Failed fetching tag %d for contact" +
-                " '%s' from list: %s", i, id, err)
+                " '%s' from list: %w", i, id, err)
         }
         tags = append(tags, t)
     }
@@ -1257,9 +1169,9 @@ func GetContactTags(id string) ([]RetrieveTag, error) {
     for _, v := range l.ContactTags {
         ids = append(ids, v.Tag)
     }
-    tags, err := GetTagsAsync(ids)
+    tags, err := c.GetTagsAsync(ids)
     if err != nil {
-        return nil, fmt.Errorf("Failed fetching tags for contact '%s': %s", id, err)
+        return nil, fmt.Errorf("Failed fetching tags for contact '%s': %w", id, err)
     }
 
     return tags, nil
@@ -1289,27 +1201,26 @@ func GetContact(id string) (error {
 */
 
 //func UpdateContact(id string,  
-func UpdateContactEmail(id string, newEmail string) error {
-    apiUrl, apiToken := GetApiCredentials()
+func (c *Client) UpdateContactEmail(id string, newEmail string) error {
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_CONTACTS, id)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, id)
     if err != nil {
-        return fmt.Errorf("Failed building request url: %s", err)
+        return fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request data
-    var c UpdateContact
-    c.Contact.Email = newEmail;
-    json, err := json.Marshal(c)
+    var m UpdateContact
+    m.Contact.Email = newEmail;
+    json, err := json.Marshal(m)
     if err != nil {
-        return fmt.Errorf("Failed marshaling update contact request data: %s", err)
+        return fmt.Errorf("Failed marshaling update contact request data: %w", err)
     }
 
     // Send request
     requestUrl := u.String()
-    r := DoApiRequestPut(requestUrl, apiToken, json)
+    r := c.DoApiRequestPut(requestUrl, json)
     if r.Error != nil {
-        return fmt.Errorf("Failed updating data for contact with ID %s: %s",
+        return fmt.Errorf("Failed updating data for contact with ID %s: %w",
             id, r.Error)
     }
 
@@ -1317,39 +1228,44 @@ func UpdateContactEmail(id string, newEmail string) error {
     //l := &ListContactTags{}
     //err = json.Unmarshal(r.Data, &l)
     //if err != nil {
-    //    return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+    //    return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     //}
     return nil
 }
 
 // TODO create one UpdateContact function, unmarshal response, and all these functions will call it
-func UpdateContactCustomField(contact *ListContactsContact, field string, value string) error {
-    apiUrl, apiToken := GetApiCredentials()
+// The field can be given by ID, perstag, or title.
+func (c *Client) UpdateContactCustomField(contact *ListContactsContact, field string, value string) error {
+    fieldId, err := c.GetCustomFieldId(field)
+    if err != nil {
+        return fmt.Errorf("Failed finding custom field '%s': %w", field, err)
+    }
+
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_CONTACTS, contact.Id)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, contact.Id)
     if err != nil {
-        return fmt.Errorf("Failed building request url: %s", err)
+        return fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request data
-    var c UpdateContact
+    var m UpdateContact
     var f = UpdateContactContactFieldValue {
-        Field: field,
+        Field: fieldId,
         Value: value,
     }
 
-    c.Contact.Email = contact.Email
-    c.Contact.FieldValues = append(c.Contact.FieldValues, f)
-    json, err := json.Marshal(c)
+    m.Contact.Email = contact.Email
+    m.Contact.FieldValues = append(m.Contact.FieldValues, f)
+    json, err := json.Marshal(m)
     if err != nil {
-        return fmt.Errorf("Failed marshaling update contact request data: %s", err)
+        return fmt.Errorf("Failed marshaling update contact request data: %w", err)
     }
 
     // Send request
     requestUrl := u.String()
-    r := DoApiRequestPut(requestUrl, apiToken, json)
+    r := c.DoApiRequestPut(requestUrl, json)
     if r.Error != nil {
-        return fmt.Errorf("Failed updating data for contact with ID %s: %s",
+        return fmt.Errorf("Failed updating data for contact with ID %s: %w",
             contact.Id, r.Error)
     }
 
@@ -1357,7 +1273,7 @@ func UpdateContactCustomField(contact *ListContactsContact, field string, value
     //l := &ListContactTags{}
     //err = json.Unmarshal(r.Data, &l)
     //if err != nil {
-    //    return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+    //    return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     //}
     return nil
 }
@@ -1394,12 +1310,11 @@ func (c *UpdateContact) MarshalJSON() ([]byte, error) {
     return json, nil
 }
 
-func AddNoteToContact(id string, note string) error {
-    apiUrl, apiToken := GetApiCredentials()
+func (c *Client) AddNoteToContact(id string, note string) error {
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_NOTES)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_NOTES)
     if err != nil {
-        return fmt.Errorf("Failed building request url: %s", err)
+        return fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request data
@@ -1409,15 +1324,14 @@ func AddNoteToContact(id string, note string) error {
     n.Note.RelativeType = "Subscriber"
     json, err := json.Marshal(n)
     if err != nil {
-        return fmt.Errorf("Failed marshaling create note request data: %s", err)
+        return fmt.Errorf("Failed marshaling create note request data: %w", err)
     }
 
     // Send request
     requestUrl := u.String()
-    fmt.Printf("Here with url: %s", requestUrl)
-    r := DoApiRequestPost(requestUrl, apiToken, json)
+    r := c.DoApiRequestPost(requestUrl, json)
     if r.Error != nil {
-        return fmt.Errorf("Failed creating note for contact with ID %s: %s",
+        return fmt.Errorf("Failed creating note for contact with ID %s: %w",
             id, r.Error)
     }
 
@@ -1425,7 +1339,7 @@ func AddNoteToContact(id string, note string) error {
     //l := &ListContactTags{}
     //err = json.Unmarshal(r.Data, &l)
     //if err != nil {
-    //    return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+    //    return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     //}
     return nil
 }
@@ -1547,10 +1461,7 @@ type RetrieveContactListLinks struct {
     //Extra map[string]interface{}
 }
 
-type RetrieveContactDeal struct {
-    // Nothing yet
-    //Extra map[string]interface{}
-}
+type RetrieveContactDeal Deal
 
 type RetrieveContactFieldValue struct {
     Contact      string     `json:"contact"`
@@ -1709,6 +1620,8 @@ func (t *RetrieveTagContainer) UnmarshalJSON(jsonStr []byte) error {
 // Most list retrieval responses implement this interface
 type ListResponse interface {
     totalResults()  uint64
+    itemCount()     int // items in this page
+    truncateItems(n int) // drops all but the first n items
 }
 
 // List contact tags
@@ -1752,6 +1665,14 @@ func (l *ListContactTags) totalResults() uint64 {
     return uint64(len(l.ContactTags))
 }
 
+func (l *ListContactTags) itemCount() int {
+    return len(l.ContactTags)
+}
+
+func (l *ListContactTags) truncateItems(n int) {
+    l.ContactTags = l.ContactTags[:n]
+}
+
 // List all contacts
 type ListContacts struct {
     ScoreValues []string              `json:"scoreValues"`
@@ -1913,6 +1834,14 @@ func (l *ListContacts) totalResults() uint64 {
     return l.Metadata.Total
 }
 
+func (l *ListContacts) itemCount() int {
+    return len(l.Contacts)
+}
+
+func (l *ListContacts) truncateItems(n int) {
+    l.Contacts = l.Contacts[:n]
+}
+
 // List tags
 type ListTags struct {
     Tags        []ListTagsTag `json:"tags"`
@@ -1963,6 +1892,14 @@ func (l *ListTags) totalResults() uint64 {
     return l.Metadata.Total
 }
 
+func (l *ListTags) itemCount() int {
+    return len(l.Tags)
+}
+
+func (l *ListTags) truncateItems(n int) {
+    l.Tags = l.Tags[:n]
+}
+
 // List automations
 type ListAutomations struct {
     Automations []ListAutomationsAutomation `json:"automations"`
@@ -2031,6 +1968,14 @@ func (l *ListAutomations) totalResults() uint64 {
     return l.Metadata.Total
 }
 
+func (l *ListAutomations) itemCount() int {
+    return len(l.Automations)
+}
+
+func (l *ListAutomations) truncateItems(n int) {
+    l.Automations = l.Automations[:n]
+}
+
 type AutomationList []*ListAutomationsAutomation
 
 func (l *AutomationList) String() string {
@@ -2,15 +2,28 @@ package activecampaign_test
 
 import (
 	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign/actest"
     "testing"
 )
 
-// TODO support fetching this and remove hard coded id
+// Custom field holding the new email of a contact that needs a merge, the ID
+// matches the one in production
 var ChangedEmailCustomFieldId = "71"
-var TestSecretsPath = "ac_secrets.yml"
+var ChangedEmailCustomField = "CHANGED_EMAIL"
 
 func TestUpdateContactCustomField(t *testing.T) {
-    ac.SecretsFilePath = TestSecretsPath
+    s := actest.NewServer()
+    defer s.Close()
+    err := s.Seed(actest.Fixtures{
+        Fields: []actest.FieldFixture{{Id: ChangedEmailCustomFieldId,
+            Title: "Changed Email", Perstag: ChangedEmailCustomField}},
+        Contacts: []actest.ContactFixture{{Email: "tester@example.com"}},
+    })
+    if err != nil {
+        t.Fatalf("Seed() returned error: %v", err)
+    }
+    ac.SetDefaultClient(s.Client())
+    defer ac.SetDefaultClient(nil)
 
 	cases := []struct {
 		emailIn         string
@@ -18,26 +31,30 @@ func TestUpdateContactCustomField(t *testing.T) {
 		customValueIn   string
         wantError       bool
 	}{
-		{"brelix+25@gmail.com", ChangedEmailCustomFieldId, "testingggg221", false},
+		{"tester@example.com", ChangedEmailCustomFieldId, "testingggg221", false},
+		{"tester@example.com", ChangedEmailCustomField, "testingggg222", false},
+		{"tester@example.com", "NOT_A_FIELD", "testingggg221", true},
 	}
 	for _, c := range cases {
         // Propagate changes (email) through to system
         // - Active Campaign
         c1, err := ac.GetContactByEmail(c.emailIn)
-        gotError := err != nil
-        gotContact := c1 != nil
-        if gotError && !c.wantError {
-			t.Errorf("GetContactByEmail(%q) == (contact=%t, error=%t), want (contact=%t, error=%t), got err: %s",
-				c.emailIn, gotContact, gotError, true, c.wantError, err)
-            return
+        if err != nil {
+			t.Errorf("GetContactByEmail(%q) returned error: %s", c.emailIn, err)
+            continue
         }
 
         err = ac.UpdateContactCustomField(c1, c.customFieldIn, c.customValueIn)
-        gotError = err != nil
-        if gotError && !c.wantError {
-			t.Errorf("UpdateContactCustomField(%q [id=%s], %q, %q) == (error=%t), want (error=%t), got err: %s",
+        gotError := err != nil
+        if gotError != c.wantError {
+			t.Errorf("UpdateContactCustomField(%q [id=%s], %q, %q) == (error=%t), want (error=%t), got err: %v",
 				c.emailIn, c1.Id, c.customFieldIn, c.customValueIn, gotError, c.wantError, err)
-            return
+            continue
+        }
+        got := s.ContactFieldValues(c1.Id)[ChangedEmailCustomField]
+        if !gotError && got != c.customValueIn {
+			t.Errorf("UpdateContactCustomField(%q [id=%s], %q, %q) set value %q",
+				c.emailIn, c1.Id, c.customFieldIn, c.customValueIn, got)
         }
     }
 }
@@ -0,0 +1,167 @@
+package activecampaign
+
+import (
+    "encoding/json"
+    "fmt"
+    "log"
+)
+
+// Returns the single automation with exactly the given name
+func (c *Client) GetAutomationByName(name string) (*ListAutomationsAutomation, error) {
+    automations, err := c.GetAutomationsByName(name, true)
+    if err != nil {
+        return nil, err
+    }
+    if len(automations) > 1 {
+        return nil, fmt.Errorf("Found %d automations with name: %s", len(automations), name)
+    }
+    return &automations[0], nil
+}
+
+// Returns the contactAutomation associations for a contact, including
+// automations they've completed
+func (c *Client) GetContactAutomationAssociations(contactId string) ([]ListContactAutomationsContact, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, contactId, API_URL_CONTACT_AUTOMATIONS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    requestUrl := u.String()
+    r := c.DoApiRequestGet(requestUrl)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed retrieving list of contact automations" +
+            " for contact with ID %s: %w", contactId, r.Error)
+    }
+
+    // Unmarshal the message
+    l := &ListContactAutomations{}
+    err = json.Unmarshal(r.Data, &l)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return l.ContactAutomations, nil
+}
+
+// Enrolls the contact in the automation
+func (c *Client) AddContactToAutomation(contactId string, automationId string) error {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACT_AUTOMATIONS)
+    if err != nil {
+        return fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data
+    var m CreateContactAutomation
+    m.ContactAutomation.Contact = contactId
+    m.ContactAutomation.Automation = automationId
+    data, err := json.Marshal(m)
+    if err != nil {
+        return fmt.Errorf("Failed marshaling create contact automation request data: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestPost(requestUrl, data)
+    if r.Error != nil {
+        return fmt.Errorf("Failed adding contact with ID %s to automation %s: %w",
+            contactId, automationId, r.Error)
+    }
+    return nil
+}
+
+// Enrolls the contact in the automation with exactly the given name
+func (c *Client) AddContactToAutomationByName(contactId string, name string) error {
+    a, err := c.GetAutomationByName(name)
+    if err != nil {
+        return fmt.Errorf("Failed finding automation '%s': %w", name, err)
+    }
+    return c.AddContactToAutomation(contactId, a.Id)
+}
+
+// Deletes a single contactAutomation association by its ID
+func (c *Client) RemoveContactAutomation(contactAutomationId string) error {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACT_AUTOMATIONS, contactAutomationId)
+    if err != nil {
+        return fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestDelete(requestUrl)
+    if r.Error != nil {
+        return fmt.Errorf("Failed removing contact automation %s: %w",
+            contactAutomationId, r.Error)
+    }
+    return nil
+}
+
+// Removes the contact from the automation. Returns true if they were
+// removed, or false if they weren't currently in it.
+func (c *Client) RemoveContactFromAutomation(contactId string, automationId string) (bool, error) {
+    l, err := c.GetContactAutomationAssociations(contactId)
+    if err != nil {
+        return false, err
+    }
+
+    removed := false
+    for _, ca := range l {
+        if ca.Automation != automationId || ca.IsCompleted {
+            continue
+        }
+        err = c.RemoveContactAutomation(ca.Id)
+        if err != nil {
+            return removed, fmt.Errorf("Failed removing contact with ID %s from automation %s: %w",
+                contactId, automationId, err)
+        }
+        removed = true
+    }
+    if !removed && DEBUG {
+        log.Printf("Contact %s is not in automation %s", contactId, automationId)
+    }
+    return removed, nil
+}
+
+// Removes the contact from the automation with exactly the given name
+func (c *Client) RemoveContactFromAutomationByName(contactId string, name string) (bool, error) {
+    a, err := c.GetAutomationByName(name)
+    if err != nil {
+        return false, fmt.Errorf("Failed finding automation '%s': %w", name, err)
+    }
+    return c.RemoveContactFromAutomation(contactId, a.Id)
+}
+
+// Removes each of the given automation contacts (as returned by
+// GetAutomationContacts) from their automation. Returns the number removed,
+// and keeps going past failures so one bad contact doesn't stop the batch.
+func (c *Client) RemoveAutomationContacts(contacts []ListContactAutomationsContact) (int, error) {
+    count := 0
+    var errorStrings []string
+    for _, ca := range contacts {
+        err := c.RemoveContactAutomation(ca.Id)
+        if err != nil {
+            errorStrings = append(errorStrings, err.Error())
+            continue
+        }
+        count++
+    }
+    if len(errorStrings) > 0 {
+        return count, fmt.Errorf("Failed removing %d of %d automation contacts: %v",
+            len(errorStrings), len(contacts), errorStrings)
+    }
+    return count, nil
+}
+
+/*
+ * Messages and unmarshalers
+ */
+// Add a contact to an automation
+type CreateContactAutomation struct {
+    ContactAutomation   CreateContactAutomationContactAutomation    `json:"contactAutomation"`
+}
+
+type CreateContactAutomationContactAutomation struct {
+    Contact     string      `json:"contact"`
+    Automation  string      `json:"automation"`
+}
@@ -0,0 +1,331 @@
+package activecampaign
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+    "github.com/xiam/to"
+)
+
+// Contacts are sent to the bulk import endpoint in batches, each of which is
+// queued and processed by AC in the background, so we poll for the results.
+const (
+    BULK_IMPORT_BATCH_SIZE = 250 // most contacts AC takes per request
+    DEFAULT_BULK_IMPORT_POLL_INTERVAL = 5 * time.Second
+    DEFAULT_BULK_IMPORT_TIMEOUT = 10 * time.Minute // per batch
+    BULK_IMPORT_STATUS_COMPLETED = "completed"
+)
+
+// A contact to import. Tags are added (and created if missing), and empty
+// values are left unchanged on existing contacts.
+type BulkImportContact struct {
+    Email           string
+    FirstName       string
+    LastName        string
+    Phone           string
+    Tags            []string // names
+    Fields          map[string]string // custom field values by ID, perstag, or title
+    SubscribeLists  []string // list IDs
+    UnsubscribeLists []string
+}
+
+type BulkImportOptions struct {
+    BatchSize       int // defaults to BULK_IMPORT_BATCH_SIZE, which is also the max
+    PollInterval    time.Duration // defaults to DEFAULT_BULK_IMPORT_POLL_INTERVAL
+    Timeout         time.Duration // for each batch, defaults to DEFAULT_BULK_IMPORT_TIMEOUT
+    NoWait          bool // queue the batches and return without polling
+}
+
+// A contact that AC rejected. Rejected batches give the email, failures
+// reported after the import only give the contact ID.
+type BulkImportFailure struct {
+    Email       string
+    ContactId   string
+    Reason      string
+}
+
+type BulkImportResult struct {
+    BatchIds    []string
+    Queued      int
+    Imported    []string // contact IDs, only known when waiting for the batches
+    Failures    []BulkImportFailure
+}
+
+// Imports the contacts with AC's bulk import endpoint, splitting them into
+// batches and waiting for each to finish unless options.NoWait is set.
+// Contacts that fail don't stop the import, they're returned in Failures.
+// An error is only returned if a whole batch couldn't be imported, along
+// with the results so far.
+func (c *Client) BulkImportContacts(ctx context.Context, contacts []BulkImportContact,
+    options BulkImportOptions) (*BulkImportResult, error) {
+    batchSize := options.BatchSize
+    if batchSize < 1 || batchSize > BULK_IMPORT_BATCH_SIZE {
+        batchSize = BULK_IMPORT_BATCH_SIZE
+    }
+    result := &BulkImportResult{}
+
+    for start := 0; start < len(contacts); start += batchSize {
+        end := start + batchSize
+        if end > len(contacts) {
+            end = len(contacts)
+        }
+        batch := contacts[start:end]
+
+        r, err := c.startBulkImport(ctx, batch)
+        if err == nil && r.batchId == "" && len(r.failures) > 0 {
+            // AC rejects the whole batch if any contact is invalid, so try
+            // again without them
+            result.Failures = append(result.Failures, r.failures...)
+            remaining := withoutBulkImportFailures(batch, r.failures)
+            if len(remaining) < 1 || len(remaining) == len(batch) {
+                continue
+            }
+            batch = remaining
+            r, err = c.startBulkImport(ctx, batch)
+        }
+        if err != nil {
+            return result, fmt.Errorf("Failed importing contacts %d to %d of %d: %w",
+                start + 1, end, len(contacts), err)
+        }
+        result.Failures = append(result.Failures, r.failures...)
+        if r.batchId == "" {
+            continue // nothing was queued
+        }
+        result.BatchIds = append(result.BatchIds, r.batchId)
+        result.Queued += r.queued
+        if DEBUG {
+            log.Printf("Queued %d contacts in bulk import batch: %s", r.queued, r.batchId)
+        }
+        if options.NoWait {
+            continue
+        }
+
+        status, err := c.waitForBulkImport(ctx, r.batchId, options)
+        if err != nil {
+            return result, err
+        }
+        result.Imported = append(result.Imported, status.Success...)
+        for _, f := range status.Failure {
+            result.Failures = append(result.Failures, BulkImportFailure{ContactId: f,
+                Reason: "Failed during import"})
+        }
+    }
+    return result, nil
+}
+
+// Returns the status of a queued bulk import batch
+func (c *Client) GetBulkImportStatus(ctx context.Context, batchId string) (*RetrieveBulkImportStatus, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_BULK_IMPORT_STATUS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+    q := u.Query()
+    q.Set("batchId", batchId)
+    u.RawQuery = q.Encode()
+
+    requestUrl := u.String()
+    r := c.doApiRequestContext(ctx, "GET", requestUrl, nil, 200)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed retrieving status of bulk import batch %s: %w",
+            batchId, r.Error)
+    }
+
+    // Unmarshal the message
+    s := &RetrieveBulkImportStatus{}
+    err = json.Unmarshal(r.Data, &s)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return s, nil
+}
+
+type bulkImportBatch struct {
+    batchId     string
+    queued      int
+    failures    []BulkImportFailure
+}
+
+func (c *Client) startBulkImport(ctx context.Context, contacts []BulkImportContact) (*bulkImportBatch, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_BULK_IMPORT)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data
+    var m CreateBulkImport
+    for _, contact := range contacts {
+        ic := CreateBulkImportContact{
+            Email: contact.Email,
+            FirstName: contact.FirstName,
+            LastName: contact.LastName,
+            Phone: contact.Phone,
+            Tags: contact.Tags,
+        }
+        for name, value := range contact.Fields {
+            fieldId, err := c.GetCustomFieldId(name)
+            if err != nil {
+                return nil, fmt.Errorf("Failed finding custom field '%s': %w", name, err)
+            }
+            ic.Fields = append(ic.Fields, CreateBulkImportField{Id: to.Int64(fieldId), Value: value})
+        }
+        for _, id := range contact.SubscribeLists {
+            ic.Subscribe = append(ic.Subscribe, CreateBulkImportList{ListId: to.Int64(id)})
+        }
+        for _, id := range contact.UnsubscribeLists {
+            ic.Unsubscribe = append(ic.Unsubscribe, CreateBulkImportList{ListId: to.Int64(id)})
+        }
+        m.Contacts = append(m.Contacts, ic)
+    }
+    data, err := json.Marshal(m)
+    if err != nil {
+        return nil, fmt.Errorf("Failed marshaling bulk import request data: %w", err)
+    }
+
+    // Send request. Invalid contacts come back as a 400 listing each of them.
+    requestUrl := u.String()
+    r := c.doApiRequestContext(ctx, "POST", requestUrl, data, 200, 400)
+    if r.Error != nil {
+        return nil, r.Error
+    }
+
+    // Unmarshal the message
+    resp := &CreateBulkImportResponse{}
+    err = json.Unmarshal(r.Data, &resp)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    b := &bulkImportBatch{batchId: resp.BatchId, queued: resp.QueuedContacts}
+    for _, f := range resp.FailureReasons {
+        email := ""
+        if f.Contact >= 0 && f.Contact < len(contacts) {
+            email = contacts[f.Contact].Email
+        }
+        b.failures = append(b.failures, BulkImportFailure{Email: email, Reason: f.FailureReason})
+    }
+    if resp.Success != 1 && len(b.failures) < 1 {
+        return nil, fmt.Errorf("Bulk import was not accepted: %s", resp.Message)
+    }
+    return b, nil
+}
+
+func withoutBulkImportFailures(contacts []BulkImportContact,
+    failures []BulkImportFailure) []BulkImportContact {
+    failed := make(map[string]bool)
+    for _, f := range failures {
+        failed[strings.ToLower(f.Email)] = true
+    }
+    var l []BulkImportContact
+    for _, contact := range contacts {
+        if !failed[strings.ToLower(contact.Email)] {
+            l = append(l, contact)
+        }
+    }
+    return l
+}
+
+func (c *Client) waitForBulkImport(ctx context.Context, batchId string,
+    options BulkImportOptions) (*RetrieveBulkImportStatus, error) {
+    interval := options.PollInterval
+    if interval <= 0 {
+        interval = DEFAULT_BULK_IMPORT_POLL_INTERVAL
+    }
+    timeout := options.Timeout
+    if timeout <= 0 {
+        timeout = DEFAULT_BULK_IMPORT_TIMEOUT
+    }
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    for {
+        s, err := c.GetBulkImportStatus(ctx, batchId)
+        if err != nil {
+            return nil, err
+        }
+        if strings.EqualFold(s.Status, BULK_IMPORT_STATUS_COMPLETED) {
+            return s, nil
+        }
+        if DEBUG {
+            log.Printf("Bulk import batch %s is %s, checking again in %v", batchId, s.Status, interval)
+        }
+        if err := sleepContext(ctx, interval); err != nil {
+            return nil, fmt.Errorf("Gave up waiting for bulk import batch %s: %w", batchId, err)
+        }
+    }
+}
+
+/*
+ * Messages and unmarshalers
+ */
+// Bulk import contacts
+type CreateBulkImport struct {
+    Contacts    []CreateBulkImportContact   `json:"contacts"`
+}
+
+type CreateBulkImportContact struct {
+    Email       string                  `json:"email"`
+    FirstName   string                  `json:"first_name,omitempty"`
+    LastName    string                  `json:"last_name,omitempty"`
+    Phone       string                  `json:"phone,omitempty"`
+    Tags        []string                `json:"tags,omitempty"`
+    Fields      []CreateBulkImportField `json:"fields,omitempty"`
+    Subscribe   []CreateBulkImportList  `json:"subscribe,omitempty"`
+    Unsubscribe []CreateBulkImportList  `json:"unsubscribe,omitempty"`
+}
+
+type CreateBulkImportField struct {
+    Id          int64       `json:"id"`
+    Value       string      `json:"value"`
+}
+
+type CreateBulkImportList struct {
+    ListId      int64       `json:"listid"`
+}
+
+type CreateBulkImportResponse struct {
+    Success         int         `json:"success"`
+    QueuedContacts  int         `json:"queued_contacts"`
+    BatchId         string      `json:"batchId"`
+    Message         string      `json:"message"`
+    FailureReasons  []CreateBulkImportFailureReason `json:"failureReasons"`
+}
+
+type CreateBulkImportFailureReason struct {
+    Contact         int         `json:"contact"` // index in the request
+    FailureReason   string      `json:"failureReason"`
+}
+
+// Bulk import batch status
+type RetrieveBulkImportStatus struct {
+    Status      string      `json:"status"`
+    Success     []string    `json:"success"` // contact IDs
+    Failure     []string    `json:"failure"` // contact IDs
+}
+
+// AC sends the IDs as numbers or strings depending on the account
+func (s *RetrieveBulkImportStatus) UnmarshalJSON(jsonStr []byte) error {
+    var raw struct {
+        Status      string          `json:"status"`
+        Success     []interface{}   `json:"success"`
+        Failure     []interface{}   `json:"failure"`
+    }
+    err := json.Unmarshal(jsonStr, &raw)
+    if err != nil {
+        return err
+    }
+
+    *s = RetrieveBulkImportStatus{Status: raw.Status}
+    for _, v := range raw.Success {
+        s.Success = append(s.Success, to.String(v))
+    }
+    for _, v := range raw.Failure {
+        s.Failure = append(s.Failure, to.String(v))
+    }
+
+    return nil
+}
@@ -0,0 +1,88 @@
+package activecampaign_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func TestBulkImportContacts(t *testing.T) {
+	var batches [][]string
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/3/import/bulk_import":
+			body, _ := ioutil.ReadAll(r.Body)
+			var m ac.CreateBulkImport
+			json.Unmarshal(body, &m)
+			var emails []string
+			for _, c := range m.Contacts {
+				emails = append(emails, c.Email)
+			}
+			batches = append(batches, emails)
+			// Reject the whole batch if it has an invalid contact
+			for i, e := range emails {
+				if e == "bad" {
+					w.WriteHeader(http.StatusBadRequest)
+					fmt.Fprintf(w, `{"success":0,"message":"Invalid contacts",`+
+						`"failureReasons":[{"contact":%d,"failureReason":"Invalid email address"}]}`, i)
+					return
+				}
+			}
+			fmt.Fprintf(w, `{"success":1,"queued_contacts":%d,"batchId":"b%d"}`, len(emails), len(batches))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/3/import/info":
+			polls++
+			if polls%2 == 1 {
+				fmt.Fprint(w, `{"status":"processing"}`)
+				return
+			}
+			failure := ""
+			if polls == 2 {
+				failure = `"99"`
+			}
+			fmt.Fprintf(w, `{"status":"completed","success":[%d,"%d"],"failure":[%s]}`, polls, polls+100,
+				failure)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	contacts := []ac.BulkImportContact{
+		{Email: "a@example.com", Tags: []string{"SJC_Enrolled"}},
+		{Email: "bad"},
+		{Email: "b@example.com"},
+	}
+	r, err := client.BulkImportContacts(context.Background(), contacts, ac.BulkImportOptions{
+		BatchSize:    2,
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("BulkImportContacts() returned error: %v", err)
+	}
+
+	wantBatches := `[[a@example.com bad] [a@example.com] [b@example.com]]`
+	if fmt.Sprint(batches) != wantBatches {
+		t.Errorf("Posted batches %v, want %s", batches, wantBatches)
+	}
+	if fmt.Sprint(r.BatchIds) != "[b2 b3]" || r.Queued != 2 {
+		t.Errorf("Got batches %v with %d queued", r.BatchIds, r.Queued)
+	}
+	if fmt.Sprint(r.Imported) != "[2 102 4 104]" {
+		t.Errorf("Got imported %v", r.Imported)
+	}
+	if len(r.Failures) != 2 || r.Failures[0].Email != "bad" ||
+		r.Failures[0].Reason != "Invalid email address" ||
+		r.Failures[1].Email != "" || r.Failures[1].ContactId != "99" {
+		t.Errorf("Got failures %+v", r.Failures)
+	}
+}
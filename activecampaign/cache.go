@@ -0,0 +1,289 @@
+package activecampaign
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Tags, automations, custom fields and lists rarely change, so a client with
+// a Cache keeps them in a local file instead of paging through them for
+// every lookup. A lookup that misses refreshes that kind of item once, in
+// case it was added since the cache was saved.
+const (
+    CACHE_TAGS = "tags"
+    CACHE_AUTOMATIONS = "automations"
+    CACHE_FIELDS = "fields"
+    CACHE_LISTS = "lists"
+    DEFAULT_CACHE_TTL = 24 * time.Hour
+)
+
+// Used by DefaultClient() when set
+var CacheFilePath = ""
+var CacheTTL = DEFAULT_CACHE_TTL
+
+// A file-backed cache of account metadata. Use a separate file for each
+// account.
+type MetadataCache struct {
+    FilePath    string
+    TTL         time.Duration // defaults to DEFAULT_CACHE_TTL
+
+    mutex       sync.Mutex
+    loaded      bool
+    entries     map[string]*cacheEntry
+    refreshed   map[string]bool // kinds fetched since the cache was loaded
+}
+
+type cacheEntry struct {
+    FetchedAt   time.Time       `json:"fetched_at"`
+    Data        json.RawMessage `json:"data"`
+}
+
+func NewMetadataCache(filePath string, ttl time.Duration) *MetadataCache {
+    return &MetadataCache{FilePath: filePath, TTL: ttl}
+}
+
+// Unmarshals the cached items of the given kind into v. Returns false if
+// there are none or they've expired.
+func (m *MetadataCache) Get(kind string, v interface{}) bool {
+    m.mutex.Lock()
+    defer m.mutex.Unlock()
+    m.load()
+
+    e, ok := m.entries[kind]
+    if !ok || time.Since(e.FetchedAt) > m.ttl() {
+        return false
+    }
+    if err := json.Unmarshal(e.Data, v); err != nil {
+        log.Printf("Ignoring bad cache entry for %s in '%s': %s", kind, m.FilePath, err)
+        return false
+    }
+    return true
+}
+
+// Replaces the cached items of the given kind and saves the cache file
+func (m *MetadataCache) Put(kind string, v interface{}) error {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return fmt.Errorf("Failed marshaling cached %s: %w", kind, err)
+    }
+
+    m.mutex.Lock()
+    defer m.mutex.Unlock()
+    m.load()
+    m.entries[kind] = &cacheEntry{FetchedAt: time.Now(), Data: data}
+    m.refreshed[kind] = true
+    return m.save()
+}
+
+// Whether the given kind was fetched from the API since the cache was loaded
+func (m *MetadataCache) Refreshed(kind string) bool {
+    m.mutex.Lock()
+    defer m.mutex.Unlock()
+    return m.refreshed[kind]
+}
+
+// Removes the given kinds (or everything if none are given) from the cache
+func (m *MetadataCache) Invalidate(kinds ...string) error {
+    m.mutex.Lock()
+    defer m.mutex.Unlock()
+    m.load()
+    if len(kinds) < 1 {
+        m.entries = make(map[string]*cacheEntry)
+        m.refreshed = make(map[string]bool)
+    }
+    for _, kind := range kinds {
+        delete(m.entries, kind)
+        delete(m.refreshed, kind)
+    }
+    return m.save()
+}
+
+func (m *MetadataCache) ttl() time.Duration {
+    if m.TTL <= 0 {
+        return DEFAULT_CACHE_TTL
+    }
+    return m.TTL
+}
+
+// Reads the cache file the first time it's needed. A missing or bad file
+// just starts an empty cache.
+func (m *MetadataCache) load() {
+    if m.loaded {
+        return
+    }
+    m.loaded = true
+    m.entries = make(map[string]*cacheEntry)
+    m.refreshed = make(map[string]bool)
+
+    data, err := ioutil.ReadFile(m.FilePath)
+    if err != nil {
+        if !os.IsNotExist(err) {
+            log.Printf("Failed reading cache file '%s': %s", m.FilePath, err)
+        }
+        return
+    }
+    if err := json.Unmarshal(data, &m.entries); err != nil {
+        log.Printf("Ignoring bad cache file '%s': %s", m.FilePath, err)
+        m.entries = make(map[string]*cacheEntry)
+    }
+}
+
+// Writes to a temporary file first so other runs never see a partial file
+func (m *MetadataCache) save() error {
+    if m.FilePath == "" {
+        return nil
+    }
+    data, err := json.Marshal(m.entries)
+    if err != nil {
+        return fmt.Errorf("Failed marshaling cache: %w", err)
+    }
+    f, err := ioutil.TempFile(filepath.Dir(m.FilePath), filepath.Base(m.FilePath) + ".tmp")
+    if err != nil {
+        return fmt.Errorf("Failed creating cache file: %w", err)
+    }
+    _, err = f.Write(data)
+    if closeErr := f.Close(); err == nil {
+        err = closeErr
+    }
+    if err == nil {
+        err = os.Rename(f.Name(), m.FilePath)
+    }
+    if err != nil {
+        os.Remove(f.Name())
+        return fmt.Errorf("Failed writing cache file '%s': %w", m.FilePath, err)
+    }
+    return nil
+}
+
+// Clears the client's cached metadata, see MetadataCache.Invalidate()
+func (c *Client) InvalidateCache(kinds ...string) error {
+    if len(kinds) < 1 || containsString(kinds, CACHE_FIELDS) {
+        c.fieldsMutex.Lock()
+        c.fields = nil
+        c.fieldsMutex.Unlock()
+    }
+    if c.Cache == nil {
+        return nil
+    }
+    return c.Cache.Invalidate(kinds...)
+}
+
+// Whether a lookup that missed should refresh the given kind and try again
+func (c *Client) shouldRefreshCache(kind string) bool {
+    return c.Cache != nil && !c.Cache.Refreshed(kind)
+}
+
+func (c *Client) putCache(kind string, v interface{}) {
+    if c.Cache == nil {
+        return
+    }
+    if err := c.Cache.Put(kind, v); err != nil {
+        log.Printf("Failed caching %s: %s", kind, err)
+    }
+}
+
+// Returns all tags, from the cache if possible
+func (c *Client) getCachedTags(refresh bool) ([]ListTagsTag, error) {
+    var tags []ListTagsTag
+    if !refresh && c.Cache.Get(CACHE_TAGS, &tags) {
+        return tags, nil
+    }
+    tags, err := c.GetAllTags()
+    if err != nil {
+        return nil, err
+    }
+    c.putCache(CACHE_TAGS, tags)
+    return tags, nil
+}
+
+// Same as findTagByName(), but searches the cached tags
+func (c *Client) findCachedTagByName(tag string) (*ListTagsTag, []string, error) {
+    tags, err := c.getCachedTags(false)
+    if err != nil {
+        return nil, nil, err
+    }
+    t, tagNames := matchTagName(tags, tag)
+    if t == nil && c.shouldRefreshCache(CACHE_TAGS) {
+        tags, err = c.getCachedTags(true)
+        if err != nil {
+            return nil, nil, err
+        }
+        t, tagNames = matchTagName(tags, tag)
+    }
+    return t, tagNames, nil
+}
+
+// Returns the tag named exactly tag, or the names of the tags containing it
+func matchTagName(tags []ListTagsTag, tag string) (*ListTagsTag, []string) {
+    var tagNames []string
+    for i, t := range tags {
+        if t.Tag == tag {
+            return &tags[i], nil
+        }
+        if strings.Contains(strings.ToLower(t.Tag), strings.ToLower(tag)) {
+            tagNames = append(tagNames, t.Tag)
+        }
+    }
+    return nil, tagNames
+}
+
+// Returns all automations, from the cache if possible
+func (c *Client) getCachedAutomations(refresh bool) ([]ListAutomationsAutomation, error) {
+    var automations []ListAutomationsAutomation
+    if !refresh && c.Cache.Get(CACHE_AUTOMATIONS, &automations) {
+        return automations, nil
+    }
+    r, err := c.GetAutomationsAsync(QueryParameters{FetchAll: true, Limit: API_LIMIT_MAXIMUM})
+    if err != nil {
+        return nil, err
+    }
+    c.putCache(CACHE_AUTOMATIONS, r.Automations)
+    return r.Automations, nil
+}
+
+// Same as GetAutomationsByName(), but searches the cached automations
+func (c *Client) getCachedAutomationsByName(name string, exactMatch bool) ([]ListAutomationsAutomation, error) {
+    automations, err := c.getCachedAutomations(false)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to get automations by name '%s': %w", name, err)
+    }
+    l := matchAutomationName(automations, name, exactMatch)
+    if len(l) < 1 && c.shouldRefreshCache(CACHE_AUTOMATIONS) {
+        automations, err = c.getCachedAutomations(true)
+        if err != nil {
+            return nil, fmt.Errorf("Failed to get automations by name '%s': %w", name, err)
+        }
+        l = matchAutomationName(automations, name, exactMatch)
+    }
+    if len(l) < 1 {
+        return nil, newNotFoundError("No automations found with name: %s", name)
+    }
+    return l, nil
+}
+
+func matchAutomationName(automations []ListAutomationsAutomation, name string,
+    exactMatch bool) []ListAutomationsAutomation {
+    var l []ListAutomationsAutomation
+    for _, a := range automations {
+        if (exactMatch && a.Name == name) || (!exactMatch && strings.Contains(a.Name, name)) {
+            l = append(l, a)
+        }
+    }
+    return l
+}
+
+func containsString(l []string, s string) bool {
+    for _, v := range l {
+        if v == s {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,90 @@
+package activecampaign_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func TestMetadataCache(t *testing.T) {
+	requests := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/api/3/tags":
+			fmt.Fprint(w, `{"tags":[{"id":"1","tag":"SJC_Enrolled"},{"id":"2","tag":"SJC_Cancelled"}],`+
+				`"meta":{"total":"2"}}`)
+		case "/api/3/automations":
+			fmt.Fprint(w, `{"automations":[{"id":"5","name":"SJC_Enrolled"},{"id":"6","name":"SJC_Renewal"}],`+
+				`"meta":{"total":"2"}}`)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	cachePath := filepath.Join(t.TempDir(), "ac_cache.json")
+
+	client := ac.NewClient(ts.URL, "test-token")
+	client.Cache = ac.NewMetadataCache(cachePath, time.Hour)
+	for i := 0; i < 3; i++ {
+		tag, err := client.GetTagByName("SJC_Cancelled")
+		if err != nil || tag.Id != "2" {
+			t.Fatalf("GetTagByName() == (%+v, %v)", tag, err)
+		}
+		a, err := client.GetAutomationsByName("SJC_Renewal", true)
+		if err != nil || len(a) != 1 || a[0].Id != "6" {
+			t.Fatalf("GetAutomationsByName() == (%+v, %v)", a, err)
+		}
+	}
+	// Misses refresh once per run
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetTagByName("SJC_Missing"); err == nil {
+			t.Errorf("GetTagByName() of missing tag returned no error")
+		}
+	}
+	if requests["/api/3/tags"] != 1 || requests["/api/3/automations"] != 1 {
+		t.Errorf("Got requests %v, want 1 for tags and automations", requests)
+	}
+
+	// Another run reads the cache file, and refreshes once on a miss
+	client = ac.NewClient(ts.URL, "test-token")
+	client.Cache = ac.NewMetadataCache(cachePath, time.Hour)
+	if _, err := client.GetTagByName("SJC_Enrolled"); err != nil {
+		t.Errorf("GetTagByName() from cache file returned error: %v", err)
+	}
+	_, err := client.GetAutomationsByName("SJC_Missing", true)
+	if !errors.Is(err, ac.ErrNotFound) {
+		t.Errorf("GetAutomationsByName() of missing automation == %v, want ErrNotFound", err)
+	}
+	if requests["/api/3/tags"] != 1 || requests["/api/3/automations"] != 2 {
+		t.Errorf("Got requests %v, want 1 for tags and 2 for automations", requests)
+	}
+
+	// Invalidating fetches them again
+	if err := client.InvalidateCache(ac.CACHE_TAGS); err != nil {
+		t.Fatalf("InvalidateCache() returned error: %v", err)
+	}
+	if _, err := client.GetTagByName("SJC_Enrolled"); err != nil {
+		t.Errorf("GetTagByName() after invalidating returned error: %v", err)
+	}
+	if requests["/api/3/tags"] != 2 {
+		t.Errorf("Got %d tag requests after invalidating, want 2", requests["/api/3/tags"])
+	}
+
+	// Expired entries are fetched again
+	client = ac.NewClient(ts.URL, "test-token")
+	client.Cache = ac.NewMetadataCache(cachePath, time.Nanosecond)
+	if _, err := client.GetTagByName("SJC_Enrolled"); err != nil {
+		t.Errorf("GetTagByName() with expired cache returned error: %v", err)
+	}
+	if requests["/api/3/tags"] != 3 {
+		t.Errorf("Got %d tag requests with expired cache, want 3", requests["/api/3/tags"])
+	}
+}
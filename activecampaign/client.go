@@ -0,0 +1,267 @@
+package activecampaign
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "log"
+    "net/http"
+    "net/http/httptrace"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "gopkg.in/yaml.v2"
+)
+
+const (
+    DEFAULT_REQUEST_TIMEOUT = 30 * time.Second
+)
+
+// Client holds everything needed to talk to one ActiveCampaign account.
+// Point ApiUrl at an httptest server or another account to redirect all
+// requests made through it.
+type Client struct {
+    ApiUrl              string // includes API_URL_SUFFIX
+    ApiToken            string
+    AccountId           string // used for admin (activehosted.com) links
+    UserAgent           string
+    HttpClient          *http.Client
+    ConcurrencyLimit    int
+    EventKey            string // for TrackEvent()
+    EventActId          string
+    EventTrackingUrl    string // defaults to EVENT_TRACKING_URL
+    WebhookSecret       string // expected ?secret= on incoming webhooks
+    RateLimit           float64 // requests per second, 0 to disable
+    RateBurst           int
+    MaxAttempts         int // total tries per request, including retries
+    RetryBackoff        time.Duration // first retry delay, doubled each time
+    Cache               *MetadataCache // optional, see cache.go
+
+    limiterOnce         sync.Once
+    limiter             *tokenBucket
+    fieldsMutex         sync.Mutex
+    fields              []ListFieldsField // cached by GetCustomFields()
+}
+
+// Creates a client for the given account URL (e.g. https://<account>.api-us1.com)
+// and API token. The v3 API suffix is added automatically.
+func NewClient(apiUrl string, apiToken string) *Client {
+    apiUrl = strings.TrimSuffix(apiUrl, "/")
+    return &Client{
+        ApiUrl: fmt.Sprintf("%s%s", apiUrl, API_URL_SUFFIX),
+        ApiToken: apiToken,
+        UserAgent: USER_AGENT,
+        HttpClient: &http.Client{Timeout: DEFAULT_REQUEST_TIMEOUT},
+        ConcurrencyLimit: REQUEST_CONCURRENCY_LIMIT,
+        RateLimit: DEFAULT_RATE_LIMIT,
+        RateBurst: DEFAULT_RATE_BURST,
+        MaxAttempts: DEFAULT_MAX_ATTEMPTS,
+        RetryBackoff: DEFAULT_RETRY_BACKOFF,
+    }
+}
+
+// Creates a client from a YAML secrets file (see SecretsConfig).
+func NewClientFromSecrets(filePath string) (*Client, error) {
+    yamlFile, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        return nil, fmt.Errorf("Failed reading secrets file '%s': %w", filePath, err)
+    }
+    var s SecretsConfig
+    err = yaml.Unmarshal(yamlFile, &s)
+    if err != nil {
+        return nil, fmt.Errorf("Failed unmarshaling secrets file '%s': %w", filePath, err)
+    }
+    if s.ApiUrl == "" || s.ApiToken == "" {
+        return nil, fmt.Errorf("Secrets file '%s' is missing API_URL or API_TOKEN", filePath)
+    }
+
+    c := NewClient(s.ApiUrl, s.ApiToken)
+    c.AccountId = s.AccountId
+    c.EventKey = s.EventKey
+    c.EventActId = s.EventActId
+    c.WebhookSecret = s.WebhookSecret
+    return c, nil
+}
+
+func (c *Client) concurrencyLimit() int {
+    if c.ConcurrencyLimit < 1 {
+        return 1
+    }
+    return c.ConcurrencyLimit
+}
+
+func (c *Client) httpClient() *http.Client {
+    if c.HttpClient == nil {
+        return http.DefaultClient
+    }
+    return c.HttpClient
+}
+
+// Checks that the client's URL and token are accepted by the API
+func (c *Client) Authenticate() error {
+    r := c.DoApiRequestGet(c.ApiUrl)
+    return r.Error
+}
+
+// Sends a request and reads the response body, waiting on the rate limiter
+// first. Network errors, 429s and 5xx responses are retried with backoff (or
+// after Retry-After) up to MaxAttempts. POSTs are only retried on 429s or if
+// they never reached the server, so nothing gets created twice. Any other
+// status than the expected ones is turned into an error by HandleBadResponse().
+func (c *Client) doApiRequest(method string, requestUrl string, data []byte,
+    expectedStatusCodes ...int) (*ApiRequestResult) {
+    return c.doApiRequestContext(context.Background(), method, requestUrl, data,
+        expectedStatusCodes...)
+}
+
+// Same as doApiRequest(), but gives up waiting or retrying once the context
+// is done
+func (c *Client) doApiRequestContext(ctx context.Context, method string, requestUrl string,
+    data []byte, expectedStatusCodes ...int) (*ApiRequestResult) {
+    r := &ApiRequestResult{Data: nil, Error: nil}
+    limiter := c.rateLimiter()
+    attempts := c.maxAttempts()
+
+    for attempt := 1; ; attempt++ {
+        if limiter != nil {
+            if err := limiter.Wait(ctx); err != nil {
+                r.Error = err
+                return r
+            }
+        }
+        resp, body, err := c.sendApiRequest(ctx, method, requestUrl, data)
+        if err != nil && ctx.Err() != nil {
+            r.Error = ctx.Err()
+            return r
+        }
+        if err != nil {
+            if attempt >= attempts || !isRetryableError(method, err) {
+                r.Error = err
+                if attempt > 1 {
+                    r.Error = fmt.Errorf("Request failed after %d attempts: %w", attempt, err)
+                }
+                return r
+            }
+            wait := c.retryBackoff(attempt)
+            if DEBUG {
+                log.Printf("Retrying %s %s in %v (attempt %d of %d): %s", method,
+                    requestUrl, wait, attempt, attempts, err)
+            }
+            if err := sleepContext(ctx, wait); err != nil {
+                r.Error = err
+                return r
+            }
+            continue
+        }
+        r.StatusCode = resp.StatusCode
+
+        for _, code := range expectedStatusCodes {
+            if resp.StatusCode == code {
+                r.Data = body
+                return r
+            }
+        }
+
+        if isRetryableStatus(method, resp.StatusCode) && attempt < attempts {
+            wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"))
+            if ok {
+                if limiter != nil {
+                    limiter.Hold(wait)
+                }
+            } else {
+                wait = c.retryBackoff(attempt)
+            }
+            if DEBUG {
+                log.Printf("Retrying %s %s in %v (attempt %d of %d): got status %d", method,
+                    requestUrl, wait, attempt, attempts, resp.StatusCode)
+            }
+            if err := sleepContext(ctx, wait); err != nil {
+                r.Error = err
+                return r
+            }
+            continue
+        }
+
+        r.Error = HandleBadResponse(resp, body, expectedStatusCodes[0])
+        return r
+    }
+}
+
+// Makes a single attempt at a request and reads the whole response body
+func (c *Client) sendApiRequest(ctx context.Context, method string, requestUrl string,
+    data []byte) (*http.Response, []byte, error) {
+    var reqBody io.Reader
+    if data != nil {
+        reqBody = bytes.NewReader(data)
+    }
+    req, err := http.NewRequest(method, requestUrl, reqBody)
+    if err != nil {
+        return nil, nil, err
+    }
+    // Set from the transport's goroutine once the request has been written
+    var sent int32
+    trace := &httptrace.ClientTrace{
+        WroteRequest: func(info httptrace.WroteRequestInfo) {
+            atomic.StoreInt32(&sent, 1)
+        },
+    }
+    req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
+    if data != nil {
+        req.Header.Set("Content-Type", "application/json; charset=utf-8")
+    }
+    userAgent := c.UserAgent
+    if userAgent == "" {
+        userAgent = USER_AGENT
+    }
+    req.Header.Set("User-Agent", userAgent)
+    req.Header.Set("Api-Token", c.ApiToken)
+
+    resp, err := c.httpClient().Do(req)
+    if err != nil {
+        return nil, nil, &TransportError{Method: method, Url: requestUrl, Err: err,
+            Sent: atomic.LoadInt32(&sent) == 1}
+    }
+
+    defer resp.Body.Close()
+
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return nil, nil, &TransportError{Method: method, Url: requestUrl, Err: err, Sent: true}
+    }
+    return resp, body, nil
+}
+
+// Handled a POST request with JSON data
+func (c *Client) DoApiRequestPost(requestUrl string, data []byte) (*ApiRequestResult) {
+    if DEBUG {
+        log.Printf("Posting %d bytes of data to url: %s", len(data), requestUrl)
+    }
+    return c.doApiRequest(http.MethodPost, requestUrl, data, 201)
+}
+
+// Handled a PUT request with JSON data
+func (c *Client) DoApiRequestPut(requestUrl string, data []byte) (*ApiRequestResult) {
+    if DEBUG {
+        log.Printf("Putting %d bytes of data to url: %s", len(data), requestUrl)
+    }
+    return c.doApiRequest(http.MethodPut, requestUrl, data, 200)
+}
+
+// Handled a DELETE request
+func (c *Client) DoApiRequestDelete(requestUrl string) (*ApiRequestResult) {
+    if DEBUG {
+        log.Printf("Deleting url: %s", requestUrl)
+    }
+    return c.doApiRequest(http.MethodDelete, requestUrl, nil, 200)
+}
+
+func (c *Client) DoApiRequestGet(requestUrl string) (*ApiRequestResult) {
+    if DEBUG {
+        log.Println(fmt.Sprintf("Querying url: %s", requestUrl))
+    }
+    return c.doApiRequest(http.MethodGet, requestUrl, nil, 200)
+}
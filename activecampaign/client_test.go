@@ -0,0 +1,134 @@
+package activecampaign_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func TestNewClientFromSecretsMissingFile(t *testing.T) {
+	c, err := ac.NewClientFromSecrets("does_not_exist_secrets.yml")
+	if err == nil || c != nil {
+		t.Errorf("NewClientFromSecrets(%q) == (client=%t, error=%t), want (client=false, error=true)",
+			"does_not_exist_secrets.yml", c != nil, err != nil)
+	}
+}
+
+func TestClientGetContactByEmail(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Api-Token") != "test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"No Result found for Subscriber with id 0"}`)
+			return
+		}
+		if r.URL.Path != "/api/3/contacts" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("email") == "tester@example.com" {
+			fmt.Fprint(w, `{"contacts":[{"email":"tester@example.com","id":"42"}],"meta":{"total":"1","page_input":{"limit":20,"offset":0}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"contacts":[],"meta":{"total":"0","page_input":{"limit":20,"offset":0}}}`)
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		token     string
+		in        string
+		wantId    string
+		wantError bool
+	}{
+		{"test-token", "tester@example.com", "42", false},
+		{"test-token", "nobody@example.com", "", true},
+		{"bad-token", "tester@example.com", "", true},
+	}
+	for _, c := range cases {
+		client := ac.NewClient(ts.URL, c.token)
+		r, err := client.GetContactByEmail(c.in)
+		gotError := err != nil
+		if gotError != c.wantError {
+			t.Errorf("GetContactByEmail(%q) with token %q == (error=%t), want (error=%t), got err: %v",
+				c.in, c.token, gotError, c.wantError, err)
+			continue
+		}
+		if !gotError && r.Id != c.wantId {
+			t.Errorf("GetContactByEmail(%q) == (id=%s), want (id=%s)", c.in, r.Id, c.wantId)
+		}
+	}
+}
+
+func TestBuildQueryWithParamsFilters(t *testing.T) {
+	status := ac.LIST_STATUS_ACTIVE
+	since := time.Date(2020, 5, 1, 12, 0, 0, 0, time.FixedZone("PDT", -7*60*60))
+	cases := []struct {
+		in        ac.QueryParameters
+		want      string
+		wantError bool
+	}{
+		{ac.QueryParameters{TagId: 12, UpdatedAfter: since},
+			"filters%5Bupdated_after%5D=2020-05-01T12%3A00%3A00-07%3A00&tagid=12", false},
+		{ac.QueryParameters{ListId: 3, ListStatus: &status, AutomationId: 9},
+			"listid=3&seriesid=9&status=1", false},
+		{ac.QueryParameters{EmailLike: "@example.com", OrderBy: ac.CONTACT_ORDER_CREATED, OrderDesc: true},
+			"email_like=%40example.com&orders%5Bcdate%5D=DESC", false},
+		{ac.QueryParameters{OrderBy: "bogus"}, "", true},
+	}
+	for _, c := range cases {
+		q, err := ac.BuildQueryWithParams(c.in)
+		if (err != nil) != c.wantError {
+			t.Errorf("BuildQueryWithParams(%+v) returned error: %v", c.in, err)
+			continue
+		}
+		if err == nil && q.Encode() != c.want {
+			t.Errorf("BuildQueryWithParams(%+v) == %q, want %q", c.in, q.Encode(), c.want)
+		}
+	}
+}
+
+func TestSearchContacts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/3/contacts" || q.Get("tagid") != "7" || q.Get("filters[updated_after]") == "" {
+			t.Errorf("Unexpected request: %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if q.Get("filters[created_after]") != "" {
+			fmt.Fprint(w, `{"contacts":[],"meta":{"total":"0"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"contacts":[{"email":"a@example.com","id":"1"},{"email":"b@example.com","id":"2"}],`+
+			`"meta":{"total":"2"}}`)
+	}))
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	week := time.Now().AddDate(0, 0, -7)
+	cases := []struct {
+		paramsIn   ac.QueryParameters
+		wantEmails []string
+	}{
+		{ac.QueryParameters{TagId: 7, UpdatedAfter: week}, []string{"a@example.com", "b@example.com"}},
+		{ac.QueryParameters{TagId: 7, UpdatedAfter: week, CreatedAfter: week}, []string{}},
+	}
+	for _, c := range cases {
+		contacts, err := client.SearchContacts(c.paramsIn)
+		if err != nil {
+			t.Errorf("SearchContacts(%+v) returned error: %v", c.paramsIn, err)
+			continue
+		}
+		var gotEmails []string
+		for _, contact := range contacts {
+			gotEmails = append(gotEmails, contact.Email)
+		}
+		if contacts == nil || strings.Join(gotEmails, ",") != strings.Join(c.wantEmails, ",") {
+			t.Errorf("SearchContacts(%+v) == %v, want %v", c.paramsIn, gotEmails, c.wantEmails)
+		}
+	}
+}
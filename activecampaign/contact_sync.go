@@ -0,0 +1,92 @@
+package activecampaign
+
+import (
+    "encoding/json"
+    "fmt"
+    "log"
+
+    "bitbucket.org/dagoodma/dagoodma-go/util"
+)
+
+// Contact details for creating or updating a contact with CreateOrUpdateContact().
+// Empty values are left unchanged on existing contacts.
+type ContactDetails struct {
+    Email       string
+    FirstName   string
+    LastName    string
+    Phone       string
+    Fields      map[string]string // custom field values by ID, perstag, or title
+}
+
+// Creates a contact, or updates the existing contact with the same email.
+// Returns the contact and whether it was newly created.
+func (c *Client) CreateOrUpdateContact(d ContactDetails) (*ListContactsContact, bool, error) {
+    if !util.EmailLooksValid(d.Email) {
+        return nil, false, fmt.Errorf("Invalid email given: %s", d.Email)
+    }
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACT_SYNC)
+    if err != nil {
+        return nil, false, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data
+    var m SyncContact
+    m.Contact.Email = d.Email
+    m.Contact.FirstName = d.FirstName
+    m.Contact.LastName = d.LastName
+    m.Contact.Phone = d.Phone
+    for name, value := range d.Fields {
+        fieldId, err := c.GetCustomFieldId(name)
+        if err != nil {
+            return nil, false, fmt.Errorf("Failed finding custom field '%s': %w", name, err)
+        }
+        f := UpdateContactContactFieldValue{Field: fieldId, Value: value}
+        m.Contact.FieldValues = append(m.Contact.FieldValues, f)
+    }
+    data, err := json.Marshal(m)
+    if err != nil {
+        return nil, false, fmt.Errorf("Failed marshaling sync contact request data: %w", err)
+    }
+
+    // Send request. AC responds with 201 for new contacts and 200 for updates.
+    requestUrl := u.String()
+    if DEBUG {
+        log.Printf("Syncing contact '%s' to url: %s", d.Email, requestUrl)
+    }
+    r := c.doApiRequest("POST", requestUrl, data, 201, 200)
+    if r.Error != nil {
+        return nil, false, fmt.Errorf("Failed syncing contact '%s': %w", d.Email, r.Error)
+    }
+
+    // Unmarshal the message
+    l := &SyncContactResponse{}
+    err = json.Unmarshal(r.Data, &l)
+    if err != nil {
+        return nil, false, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+
+    return &l.Contact, r.StatusCode == 201, nil
+}
+
+/*
+ * Messages and unmarshalers
+ */
+// Create or update a contact by email
+type SyncContact struct {
+    Contact     SyncContactContact  `json:"contact"`
+}
+
+type SyncContactContact struct {
+    Email       string      `json:"email"`
+    FirstName   string      `json:"firstName,omitempty"`
+    LastName    string      `json:"lastName,omitempty"`
+    Phone       string      `json:"phone,omitempty"`
+    FieldValues []UpdateContactContactFieldValue    `json:"fieldValues,omitempty"`
+}
+
+type SyncContactResponse struct {
+    FieldValues []RetrieveContactFieldValue `json:"fieldValues"`
+    Contact     ListContactsContact         `json:"contact"`
+}
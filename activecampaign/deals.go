@@ -0,0 +1,455 @@
+package activecampaign
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "net/url"
+    "strings"
+
+    "github.com/xiam/to"
+)
+
+// Deal statuses
+const (
+    DEAL_STATUS_OPEN = 0
+    DEAL_STATUS_WON = 1
+    DEAL_STATUS_LOST = 2
+)
+
+const (
+    DEFAULT_DEAL_CURRENCY = "usd"
+    DEFAULT_DEAL_OWNER = "1" // the account's admin user
+)
+
+// Details for a deal created or updated by pipeline and stage name. Value is
+// in cents.
+type DealDetails struct {
+    ContactId   string
+    Title       string
+    Description string
+    Pipeline    string // name
+    Stage       string // name, in the pipeline
+    Value       int64
+    Currency    string // defaults to DEFAULT_DEAL_CURRENCY
+    Owner       string // user ID, defaults to DEFAULT_DEAL_OWNER
+    Status      int // one of DEAL_STATUS_*
+}
+
+// Returns all pipelines (deal groups) in the account
+func (c *Client) GetPipelines() ([]ListDealGroupsDealGroup, error) {
+    var resultList []ListDealGroupsDealGroup
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_DEAL_GROUPS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Iterate through all pages
+    it := c.NewPageIterator(context.Background(), u, nil,
+        func() ListResponse { return &ListDealGroups{} }, PageIteratorOptions{})
+    defer it.Close()
+    for it.Next() {
+        l := it.Page().(*ListDealGroups)
+        resultList = append(resultList, l.DealGroups...)
+    }
+    if err := it.Err(); err != nil {
+        return nil, fmt.Errorf("Failed fetching pipelines: %w", err)
+    }
+    if DEBUG {
+        log.Printf("Fetched %d pipelines.", len(resultList))
+    }
+    return resultList, nil
+}
+
+// Finds a pipeline by name. An exact match is preferred, otherwise the names
+// are compared case-insensitively.
+func (c *Client) GetPipelineByName(name string) (*ListDealGroupsDealGroup, error) {
+    pipelines, err := c.GetPipelines()
+    if err != nil {
+        return nil, err
+    }
+    for i, p := range pipelines {
+        if p.Title == name {
+            return &pipelines[i], nil
+        }
+    }
+    for i, p := range pipelines {
+        if strings.EqualFold(p.Title, name) {
+            return &pipelines[i], nil
+        }
+    }
+    return nil, newNotFoundError("Could not find pipeline with name: %s", name)
+}
+
+// Returns the stages in a pipeline
+func (c *Client) GetPipelineStages(pipelineId string) ([]ListDealStagesDealStage, error) {
+    var resultList []ListDealStagesDealStage
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_DEAL_STAGES)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+    q := &url.Values{}
+    q.Set("filters[d_groupid]", pipelineId)
+
+    // Iterate through all pages
+    it := c.NewPageIterator(context.Background(), u, q,
+        func() ListResponse { return &ListDealStages{} }, PageIteratorOptions{})
+    defer it.Close()
+    for it.Next() {
+        l := it.Page().(*ListDealStages)
+        resultList = append(resultList, l.DealStages...)
+    }
+    if err := it.Err(); err != nil {
+        return nil, fmt.Errorf("Failed fetching stages for pipeline %s: %w", pipelineId, err)
+    }
+    return resultList, nil
+}
+
+// Finds a pipeline and one of its stages by name, compared like
+// GetPipelineByName()
+func (c *Client) GetPipelineStageByName(pipelineName string, stageName string) (*ListDealGroupsDealGroup,
+    *ListDealStagesDealStage, error) {
+    p, err := c.GetPipelineByName(pipelineName)
+    if err != nil {
+        return nil, nil, err
+    }
+    stages, err := c.GetPipelineStages(p.Id)
+    if err != nil {
+        return nil, nil, err
+    }
+    for i, s := range stages {
+        if s.Title == stageName {
+            return p, &stages[i], nil
+        }
+    }
+    for i, s := range stages {
+        if strings.EqualFold(s.Title, stageName) {
+            return p, &stages[i], nil
+        }
+    }
+    return nil, nil, newNotFoundError("Could not find stage '%s' in pipeline: %s",
+        stageName, pipelineName)
+}
+
+// Returns all of a contact's deals
+func (c *Client) GetContactDeals(contactId string) ([]Deal, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, contactId, API_URL_DEALS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    requestUrl := u.String()
+    r := c.DoApiRequestGet(requestUrl)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed retrieving deals for contact with ID %s: %w",
+            contactId, r.Error)
+    }
+
+    // Unmarshal the message
+    l := &ListDeals{}
+    err = json.Unmarshal(r.Data, &l)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return l.Deals, nil
+}
+
+// Creates a deal for a contact in the named pipeline and stage
+func (c *Client) CreateContactDeal(d DealDetails) (*Deal, error) {
+    if len(d.ContactId) < 1 || len(d.Title) < 1 {
+        return nil, fmt.Errorf("Deal needs a contact and a title")
+    }
+    p, s, err := c.GetPipelineStageByName(d.Pipeline, d.Stage)
+    if err != nil {
+        return nil, fmt.Errorf("Failed finding deal stage: %w", err)
+    }
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_DEALS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data
+    var m CreateDeal
+    m.Deal.Contact = d.ContactId
+    m.Deal.Title = d.Title
+    m.Deal.Description = d.Description
+    m.Deal.Group = p.Id
+    m.Deal.Stage = s.Id
+    m.Deal.Value = d.Value
+    m.Deal.Currency = dealCurrency(d.Currency)
+    m.Deal.Owner = d.Owner
+    if m.Deal.Owner == "" {
+        m.Deal.Owner = DEFAULT_DEAL_OWNER
+    }
+    m.Deal.Status = d.Status
+    data, err := json.Marshal(m)
+    if err != nil {
+        return nil, fmt.Errorf("Failed marshaling create deal request data: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestPost(requestUrl, data)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed creating deal '%s' for contact with ID %s: %w",
+            d.Title, d.ContactId, r.Error)
+    }
+
+    // Unmarshal the message
+    deal := &RetrieveDeal{}
+    err = json.Unmarshal(r.Data, &deal)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return &deal.Deal, nil
+}
+
+// Moves a deal to the named stage, which may be in another pipeline
+func (c *Client) MoveDealToStage(dealId string, pipelineName string, stageName string) error {
+    p, s, err := c.GetPipelineStageByName(pipelineName, stageName)
+    if err != nil {
+        return fmt.Errorf("Failed finding deal stage: %w", err)
+    }
+    return c.updateDeal(dealId, UpdateDealDeal{Group: p.Id, Stage: s.Id})
+}
+
+// Sets a deal's value (in cents) and currency
+func (c *Client) UpdateDealValue(dealId string, value int64, currency string) error {
+    return c.updateDeal(dealId, UpdateDealDeal{Value: &value, Currency: dealCurrency(currency)})
+}
+
+// Sets a deal's status (see DEAL_STATUS_*)
+func (c *Client) UpdateDealStatus(dealId string, status int) error {
+    return c.updateDeal(dealId, UpdateDealDeal{Status: &status})
+}
+
+// Updates the contact's deal with the same title in the pipeline, or creates
+// one if they don't have it. Returns the deal and whether it was created.
+func (c *Client) CreateOrUpdateContactDeal(d DealDetails) (*Deal, bool, error) {
+    p, s, err := c.GetPipelineStageByName(d.Pipeline, d.Stage)
+    if err != nil {
+        return nil, false, fmt.Errorf("Failed finding deal stage: %w", err)
+    }
+    deals, err := c.GetContactDeals(d.ContactId)
+    if err != nil {
+        return nil, false, err
+    }
+    for _, existing := range deals {
+        if existing.Group != p.Id || existing.Title != d.Title {
+            continue
+        }
+        status := d.Status
+        m := UpdateDealDeal{
+            Stage: s.Id,
+            Value: &d.Value,
+            Currency: dealCurrency(d.Currency),
+            Status: &status,
+        }
+        err = c.updateDeal(existing.Id, m)
+        if err != nil {
+            return nil, false, err
+        }
+        existing.Stage = s.Id
+        existing.Value = to.String(d.Value)
+        existing.Currency = m.Currency
+        existing.Status = to.String(d.Status)
+        return &existing, false, nil
+    }
+    deal, err := c.CreateContactDeal(d)
+    if err != nil {
+        return nil, false, err
+    }
+    return deal, true, nil
+}
+
+func (c *Client) updateDeal(dealId string, d UpdateDealDeal) error {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_DEALS, dealId)
+    if err != nil {
+        return fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data
+    data, err := json.Marshal(UpdateDeal{Deal: d})
+    if err != nil {
+        return fmt.Errorf("Failed marshaling update deal request data: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestPut(requestUrl, data)
+    if r.Error != nil {
+        return fmt.Errorf("Failed updating deal with ID %s: %w", dealId, r.Error)
+    }
+    return nil
+}
+
+func dealCurrency(currency string) string {
+    if currency == "" {
+        return DEFAULT_DEAL_CURRENCY
+    }
+    return strings.ToLower(currency)
+}
+
+/*
+ * Messages and unmarshalers
+ */
+// A deal. Value is in cents.
+type Deal struct {
+    Id              string      `json:"id"`
+    Title           string      `json:"title"`
+    Description     string      `json:"description"`
+    Contact         string      `json:"contact"`
+    Group           string      `json:"group"` // pipeline ID
+    Stage           string      `json:"stage"`
+    Owner           string      `json:"owner"`
+    Value           string      `json:"value"`
+    Currency        string      `json:"currency"`
+    Status          string      `json:"status"`
+    CreationDate    string      `json:"cdate"`
+    ModifiedDate    string      `json:"mdate"`
+}
+
+func (d *Deal) ValueCents() int64 {
+    return to.Int64(d.Value)
+}
+
+// List a contact's deals
+type ListDeals struct {
+    Deals       []Deal      `json:"deals"`
+}
+
+// Retrieve or create a deal
+type RetrieveDeal struct {
+    Deal        Deal        `json:"deal"`
+}
+
+type CreateDeal struct {
+    Deal        CreateDealDeal  `json:"deal"`
+}
+
+type CreateDealDeal struct {
+    Contact     string      `json:"contact"`
+    Title       string      `json:"title"`
+    Description string      `json:"description,omitempty"`
+    Group       string      `json:"group"`
+    Stage       string      `json:"stage"`
+    Owner       string      `json:"owner"`
+    Value       int64       `json:"value"`
+    Currency    string      `json:"currency"`
+    Status      int         `json:"status"`
+}
+
+// Only the fields that are set get updated
+type UpdateDeal struct {
+    Deal        UpdateDealDeal  `json:"deal"`
+}
+
+type UpdateDealDeal struct {
+    Group       string      `json:"group,omitempty"`
+    Stage       string      `json:"stage,omitempty"`
+    Value       *int64      `json:"value,omitempty"`
+    Currency    string      `json:"currency,omitempty"`
+    Status      *int        `json:"status,omitempty"`
+}
+
+// List pipelines
+type ListDealGroups struct {
+    DealGroups  []ListDealGroupsDealGroup   `json:"dealGroups"`
+    Metadata    ListDealsMetadata           `json:"meta"`
+}
+
+type _ListDealGroups ListDealGroups
+
+type ListDealGroupsDealGroup struct {
+    Id              string      `json:"id"`
+    Title           string      `json:"title"`
+    Currency        string      `json:"currency"`
+    Stages          []string    `json:"stages"`
+    CreationDate    string      `json:"cdate"`
+    UpdateDate      string      `json:"udate"`
+}
+
+type ListDealsMetadata struct {
+    TotalRaw  string `json:"total"`
+    Total     uint64
+}
+
+func (l *ListDealGroups) UnmarshalJSON(jsonStr []byte) error {
+    l2 := _ListDealGroups{}
+
+    err := json.Unmarshal(jsonStr, &l2)
+    if err != nil {
+        return err
+    }
+
+    l2.Metadata.Total = to.Uint64(l2.Metadata.TotalRaw)
+
+    *l = ListDealGroups(l2)
+
+    return nil
+}
+
+func (l *ListDealGroups) totalResults() uint64 {
+    return l.Metadata.Total
+}
+
+func (l *ListDealGroups) itemCount() int {
+    return len(l.DealGroups)
+}
+
+func (l *ListDealGroups) truncateItems(n int) {
+    l.DealGroups = l.DealGroups[:n]
+}
+
+// List pipeline stages
+type ListDealStages struct {
+    DealStages  []ListDealStagesDealStage   `json:"dealStages"`
+    Metadata    ListDealsMetadata           `json:"meta"`
+}
+
+type _ListDealStages ListDealStages
+
+type ListDealStagesDealStage struct {
+    Id              string      `json:"id"`
+    Title           string      `json:"title"`
+    Group           string      `json:"group"`
+    Order           string      `json:"order"`
+    CreationDate    string      `json:"cdate"`
+    UpdateDate      string      `json:"udate"`
+}
+
+func (l *ListDealStages) UnmarshalJSON(jsonStr []byte) error {
+    l2 := _ListDealStages{}
+
+    err := json.Unmarshal(jsonStr, &l2)
+    if err != nil {
+        return err
+    }
+
+    l2.Metadata.Total = to.Uint64(l2.Metadata.TotalRaw)
+
+    *l = ListDealStages(l2)
+
+    return nil
+}
+
+func (l *ListDealStages) totalResults() uint64 {
+    return l.Metadata.Total
+}
+
+func (l *ListDealStages) itemCount() int {
+    return len(l.DealStages)
+}
+
+func (l *ListDealStages) truncateItems(n int) {
+    l.DealStages = l.DealStages[:n]
+}
@@ -0,0 +1,108 @@
+package activecampaign_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func newDealServer(t *testing.T, contactDeals string, bodies *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/3/dealGroups":
+			fmt.Fprint(w, `{"dealGroups":[{"id":"1","title":"Studio Journey"},{"id":"2","title":"Other"}],`+
+				`"meta":{"total":"2"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/3/dealStages":
+			if r.URL.Query().Get("filters[d_groupid]") != "1" {
+				t.Errorf("Stages requested for pipeline %q", r.URL.Query().Get("filters[d_groupid]"))
+			}
+			fmt.Fprint(w, `{"dealStages":[{"id":"10","title":"Enrolled","group":"1"},`+
+				`{"id":"11","title":"Renewing","group":"1"}],"meta":{"total":"2"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/3/contacts/5/deals":
+			fmt.Fprint(w, contactDeals)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/3/deals":
+			body, _ := ioutil.ReadAll(r.Body)
+			*bodies = append(*bodies, "POST "+string(body))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"deal":{"id":"99","title":"Teachable sale 1","value":"9700"}}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/api/3/deals/42":
+			body, _ := ioutil.ReadAll(r.Body)
+			*bodies = append(*bodies, "PUT "+string(body))
+			fmt.Fprint(w, `{"deal":{"id":"42"}}`)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestCreateContactDeal(t *testing.T) {
+	var bodies []string
+	ts := newDealServer(t, `{"deals":[]}`, &bodies)
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	d := ac.DealDetails{
+		ContactId: "5",
+		Title:     "Teachable sale 1",
+		Pipeline:  "studio journey",
+		Stage:     "Enrolled",
+		Value:     9700,
+		Currency:  "USD",
+		Status:    ac.DEAL_STATUS_WON,
+	}
+	deal, created, err := client.CreateOrUpdateContactDeal(d)
+	if err != nil {
+		t.Fatalf("CreateOrUpdateContactDeal() returned error: %v", err)
+	}
+	if !created || deal.Id != "99" || deal.ValueCents() != 9700 {
+		t.Errorf("CreateOrUpdateContactDeal() == (%+v, %t)", deal, created)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("Got requests %v, want 1 POST", bodies)
+	}
+	var m ac.CreateDeal
+	json.Unmarshal([]byte(bodies[0][len("POST "):]), &m)
+	want := ac.CreateDealDeal{Contact: "5", Title: "Teachable sale 1", Group: "1", Stage: "10",
+		Owner: ac.DEFAULT_DEAL_OWNER, Value: 9700, Currency: "usd", Status: ac.DEAL_STATUS_WON}
+	if m.Deal != want {
+		t.Errorf("Posted deal %+v, want %+v", m.Deal, want)
+	}
+
+	d.Stage = "Missing"
+	_, err = client.CreateContactDeal(d)
+	if !errors.Is(err, ac.ErrNotFound) {
+		t.Errorf("CreateContactDeal() with missing stage == %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateContactDeal(t *testing.T) {
+	var bodies []string
+	ts := newDealServer(t, `{"deals":[{"id":"42","title":"Studio Journey renewal","group":"1","stage":"10"}]}`, &bodies)
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	deal, created, err := client.CreateOrUpdateContactDeal(ac.DealDetails{
+		ContactId: "5",
+		Title:     "Studio Journey renewal",
+		Pipeline:  "Studio Journey",
+		Stage:     "Renewing",
+		Value:     3600,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateContactDeal() returned error: %v", err)
+	}
+	if created || deal.Id != "42" || deal.Stage != "11" {
+		t.Errorf("CreateOrUpdateContactDeal() == (%+v, %t)", deal, created)
+	}
+	want := `PUT {"deal":{"stage":"11","value":3600,"currency":"usd","status":0}}`
+	if len(bodies) != 1 || bodies[0] != want {
+		t.Errorf("Got requests %v, want [%s]", bodies, want)
+	}
+}
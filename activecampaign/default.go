@@ -0,0 +1,590 @@
+package activecampaign
+
+import (
+    "context"
+)
+
+// Package-level functions that use a shared default client loaded from
+// SecretsFilePath. These keep the older call style working, but new code
+// should create its own Client.
+
+var defaultClient *Client
+
+// Returns the default client, loading it from SecretsFilePath the first
+// time it's needed.
+func DefaultClient() (*Client, error) {
+    if defaultClient != nil {
+        return defaultClient, nil
+    }
+    c, err := NewClientFromSecrets(SecretsFilePath)
+    if err != nil {
+        return nil, err
+    }
+    if CacheFilePath != "" {
+        c.Cache = NewMetadataCache(CacheFilePath, CacheTTL)
+    }
+    if SAVE_API_KEY {
+        defaultClient = c
+    }
+    return c, nil
+}
+
+// Replaces the client used by the package-level functions. Passing nil
+// will cause it to be reloaded from SecretsFilePath.
+func SetDefaultClient(c *Client) {
+    defaultClient = c
+}
+
+func GetContactsAsync(params QueryParameters) (*ListContacts, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactsAsync(params)
+}
+
+func GetContacts(params QueryParameters) (*ListContacts, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContacts(params)
+}
+
+func SearchContacts(params QueryParameters) ([]ListContactsContact, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.SearchContacts(params)
+}
+
+func SearchContactsByTag(tag string, params QueryParameters) ([]ListContactsContact, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.SearchContactsByTag(tag, params)
+}
+
+func GetContact(params QueryParameters) (*RetrieveContact, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContact(params)
+}
+
+func GetAutomationsAsync(params QueryParameters) (*ListAutomations, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetAutomationsAsync(params)
+}
+
+func GetTagByName(tag string) (*ListTagsTag, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetTagByName(tag)
+}
+
+func GetAllTags() ([]ListTagsTag, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetAllTags()
+}
+
+func InvalidateCache(kinds ...string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.InvalidateCache(kinds...)
+}
+
+func GetContactsByTag(tag string) ([]ListContactsContact, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactsByTag(tag)
+}
+
+func GetAutomationContacts(automation *ListAutomationsAutomation) ([]ListContactAutomationsContact, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetAutomationContacts(automation)
+}
+
+func GetAutomationContactsInfo(contacts []ListContactAutomationsContact) ([]ListContactsContact, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetAutomationContactsInfo(contacts)
+}
+
+func GetAutomationsByName(name string, exactMatch bool) ([]ListAutomationsAutomation, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetAutomationsByName(name, exactMatch)
+}
+
+func GetContactByEmail(email string) (*ListContactsContact, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactByEmail(email)
+}
+
+func GetContactById(id string) (*RetrieveContact, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactById(id)
+}
+
+// Returns an empty string if the default client could not be loaded
+func GetContactProfileUrlById(id string) string {
+    c, err := DefaultClient()
+    if err != nil {
+        return ""
+    }
+    return c.GetContactProfileUrlById(id)
+}
+
+func GetContactProfileUrlByEmail(email string) (string, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return "", err
+    }
+    return c.GetContactProfileUrlByEmail(email)
+}
+
+func GetTag(id string) (*RetrieveTag, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetTag(id)
+}
+
+func GetTagsAsync(ids []string) ([]RetrieveTag, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetTagsAsync(ids)
+}
+
+func GetContactTags(id string) ([]RetrieveTag, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactTags(id)
+}
+
+func UpdateContactEmail(id string, newEmail string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.UpdateContactEmail(id, newEmail)
+}
+
+func UpdateContactCustomField(contact *ListContactsContact, field string, value string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.UpdateContactCustomField(contact, field, value)
+}
+
+func AddNoteToContact(id string, note string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.AddNoteToContact(id, note)
+}
+
+func CreateTagWithName(name string, description string) (*RetrieveTag, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.CreateTagWithName(name, description)
+}
+
+func AddTagToContact(contactId string, tagId string) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.AddTagToContact(contactId, tagId)
+}
+
+func AddTagToContactByName(contactId string, tag string, createMissing bool) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.AddTagToContactByName(contactId, tag, createMissing)
+}
+
+func RemoveTagFromContact(contactId string, tagId string) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.RemoveTagFromContact(contactId, tagId)
+}
+
+func RemoveTagFromContactByName(contactId string, tag string) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.RemoveTagFromContactByName(contactId, tag)
+}
+
+func AddTagsToContact(contactId string, tags []string, createMissing bool) (int, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return 0, err
+    }
+    return c.AddTagsToContact(contactId, tags, createMissing)
+}
+
+func RemoveTagsFromContact(contactId string, tags []string) (int, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return 0, err
+    }
+    return c.RemoveTagsFromContact(contactId, tags)
+}
+
+func GetCustomFields() ([]ListFieldsField, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetCustomFields()
+}
+
+func GetCustomFieldByName(name string) (*ListFieldsField, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetCustomFieldByName(name)
+}
+
+func GetContactFieldValues(contactId string) (map[string]string, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactFieldValues(contactId)
+}
+
+func GetContactFieldValue(contactId string, field string) (string, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return "", err
+    }
+    return c.GetContactFieldValue(contactId, field)
+}
+
+func CreateOrUpdateContact(d ContactDetails) (*ListContactsContact, bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, false, err
+    }
+    return c.CreateOrUpdateContact(d)
+}
+
+func GetLists() ([]ListListsList, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetLists()
+}
+
+func GetListByName(name string) (*ListListsList, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetListByName(name)
+}
+
+func GetContactLists(contactId string) ([]RetrieveContactList, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactLists(contactId)
+}
+
+func GetContactListStatus(contactId string, listId string) (int, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return -1, err
+    }
+    return c.GetContactListStatus(contactId, listId)
+}
+
+func UpdateContactListStatus(contactId string, listId string, status int) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.UpdateContactListStatus(contactId, listId, status)
+}
+
+func SubscribeContactToList(contactId string, listId string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.SubscribeContactToList(contactId, listId)
+}
+
+func UnsubscribeContactFromList(contactId string, listId string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.UnsubscribeContactFromList(contactId, listId)
+}
+
+func SubscribeContactToListByName(contactId string, listName string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.SubscribeContactToListByName(contactId, listName)
+}
+
+func UnsubscribeContactFromListByName(contactId string, listName string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.UnsubscribeContactFromListByName(contactId, listName)
+}
+
+func GetAutomationByName(name string) (*ListAutomationsAutomation, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetAutomationByName(name)
+}
+
+func GetContactAutomationAssociations(contactId string) ([]ListContactAutomationsContact, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactAutomationAssociations(contactId)
+}
+
+func AddContactToAutomation(contactId string, automationId string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.AddContactToAutomation(contactId, automationId)
+}
+
+func AddContactToAutomationByName(contactId string, name string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.AddContactToAutomationByName(contactId, name)
+}
+
+func RemoveContactAutomation(contactAutomationId string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.RemoveContactAutomation(contactAutomationId)
+}
+
+func RemoveContactFromAutomation(contactId string, automationId string) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.RemoveContactFromAutomation(contactId, automationId)
+}
+
+func RemoveContactFromAutomationByName(contactId string, name string) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.RemoveContactFromAutomationByName(contactId, name)
+}
+
+func RemoveAutomationContacts(contacts []ListContactAutomationsContact) (int, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return 0, err
+    }
+    return c.RemoveAutomationContacts(contacts)
+}
+
+func IsEventTrackingEnabled() (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.IsEventTrackingEnabled()
+}
+
+func TrackEvent(email string, event string, eventData string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.TrackEvent(email, event, eventData)
+}
+
+func MergeContacts(fromId string, toId string, options MergeOptions) (*MergePlan, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.MergeContacts(fromId, toId, options)
+}
+
+func DeleteContact(id string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.DeleteContact(id)
+}
+
+func GetContactNotes(contactId string) ([]Note, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactNotes(contactId)
+}
+
+func GetNote(id string) (*Note, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetNote(id)
+}
+
+func UpdateNoteText(id string, note string) (*Note, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.UpdateNoteText(id, note)
+}
+
+func DeleteNote(id string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.DeleteNote(id)
+}
+
+func AddNoteToContactOnce(contactId string, note string) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.AddNoteToContactOnce(contactId, note)
+}
+
+func GetPipelineByName(name string) (*ListDealGroupsDealGroup, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetPipelineByName(name)
+}
+
+func GetContactDeals(contactId string) ([]Deal, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetContactDeals(contactId)
+}
+
+func CreateContactDeal(d DealDetails) (*Deal, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.CreateContactDeal(d)
+}
+
+func CreateOrUpdateContactDeal(d DealDetails) (*Deal, bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, false, err
+    }
+    return c.CreateOrUpdateContactDeal(d)
+}
+
+func MoveDealToStage(dealId string, pipelineName string, stageName string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.MoveDealToStage(dealId, pipelineName, stageName)
+}
+
+func UpdateDealValue(dealId string, value int64, currency string) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.UpdateDealValue(dealId, value, currency)
+}
+
+func UpdateDealStatus(dealId string, status int) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.UpdateDealStatus(dealId, status)
+}
+
+func BulkImportContacts(ctx context.Context, contacts []BulkImportContact,
+    options BulkImportOptions) (*BulkImportResult, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.BulkImportContacts(ctx, contacts, options)
+}
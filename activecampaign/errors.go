@@ -0,0 +1,96 @@
+package activecampaign
+
+import (
+    "errors"
+    "fmt"
+)
+
+// Kinds of API failures. Check for them with errors.Is(), or use errors.As()
+// with *ApiError or *TransportError for the details.
+var (
+    ErrNotFound = errors.New("Not found")
+    ErrConflict = errors.New("Conflict") // duplicate, e.g. email already taken
+    ErrValidation = errors.New("Validation failed")
+    ErrUnauthorized = errors.New("Unauthorized")
+    ErrRateLimited = errors.New("Rate limited")
+    ErrTransport = errors.New("Transport failure")
+)
+
+// An error response from the API, or a lookup that found nothing
+type ApiError struct {
+    Kind        error // one of the Err* values above, or nil if unknown
+    StatusCode  int // 0 when the error didn't come from a response
+    Message     string
+    Errors      []ErrorResponseError // per-field errors from 422 responses
+}
+
+func (e *ApiError) Error() string {
+    if e.StatusCode == 0 {
+        return e.Message
+    }
+    return fmt.Sprintf("(%d): %s", e.StatusCode, e.Message)
+}
+
+func (e *ApiError) Unwrap() error {
+    return e.Kind
+}
+
+// Returns the per-field errors for the given source pointer
+// (e.g. "/data/attributes/email")
+func (e *ApiError) FieldErrors(pointer string) []ErrorResponseError {
+    var l []ErrorResponseError
+    for _, v := range e.Errors {
+        if v.Source.Pointer == pointer {
+            l = append(l, v)
+        }
+    }
+    return l
+}
+
+// A request that never got a response (connection refused, timeout, etc.)
+type TransportError struct {
+    Method  string
+    Url     string
+    Err     error
+    Sent    bool // whether the request was written before it failed
+}
+
+func (e *TransportError) Error() string {
+    return fmt.Sprintf("Failed sending %s request to %s: %s", e.Method, e.Url, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+    return e.Err
+}
+
+func (e *TransportError) Is(target error) bool {
+    return target == ErrTransport
+}
+
+// Creates an error for a lookup that found nothing
+func newNotFoundError(format string, args ...interface{}) error {
+    return &ApiError{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Returns the kind of error for a response status, looking at the error
+// codes for 422s since AC reports duplicates that way
+func errorKindForStatus(statusCode int, errs []ErrorResponseError) error {
+    switch statusCode {
+    case 401, 403:
+        return ErrUnauthorized
+    case 404:
+        return ErrNotFound
+    case 409:
+        return ErrConflict
+    case 422:
+        for _, e := range errs {
+            if e.Code == "duplicate" {
+                return ErrConflict
+            }
+        }
+        return ErrValidation
+    case 429:
+        return ErrRateLimited
+    }
+    return nil
+}
@@ -0,0 +1,93 @@
+package activecampaign_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func TestApiErrorKinds(t *testing.T) {
+	cases := []struct {
+		status   int
+		body     string
+		wantKind error
+	}{
+		{http.StatusUnauthorized, `{"message":"An invalid Api-Token was provided"}`, ac.ErrUnauthorized},
+		{http.StatusForbidden, `{"message":"You do not have permission"}`, ac.ErrUnauthorized},
+		{http.StatusNotFound, `{"message":"No Result found for Subscriber with id 5"}`, ac.ErrNotFound},
+		{http.StatusConflict, `{}`, ac.ErrConflict},
+		{http.StatusUnprocessableEntity, `{"errors":[{"title":"Email address already exists in the system","code":"duplicate","source":{"pointer":"/data/attributes/email"}}]}`, ac.ErrConflict},
+		{http.StatusUnprocessableEntity, `{"errors":[{"title":"Contact Email Address is not valid.","code":"email_invalid","source":{"pointer":"/data/attributes/email"}}]}`, ac.ErrValidation},
+		{http.StatusTooManyRequests, ``, ac.ErrRateLimited},
+		{http.StatusTeapot, ``, nil},
+	}
+	for _, c := range cases {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+			fmt.Fprint(w, c.body)
+		}))
+		client := ac.NewClient(ts.URL, "test-token")
+		client.MaxAttempts = 1
+		r := client.DoApiRequestGet(client.ApiUrl)
+		ts.Close()
+
+		var e *ac.ApiError
+		if !errors.As(r.Error, &e) {
+			t.Errorf("DoApiRequestGet() with status %d == %v, want *ApiError", c.status, r.Error)
+			continue
+		}
+		if e.StatusCode != c.status || e.Kind != c.wantKind {
+			t.Errorf("DoApiRequestGet() with status %d == (status=%d, kind=%v), want (status=%d, kind=%v)",
+				c.status, e.StatusCode, e.Kind, c.status, c.wantKind)
+		}
+		if c.wantKind != nil && !errors.Is(r.Error, c.wantKind) {
+			t.Errorf("errors.Is(%v, %v) == false, want true", r.Error, c.wantKind)
+		}
+	}
+}
+
+func TestApiErrorFieldErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"errors":[{"title":"Contact Email Address is not valid.","code":"email_invalid","source":{"pointer":"/data/attributes/email"}}]}`)
+	}))
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	err := client.UpdateContactEmail("42", "tester@example.com")
+	var e *ac.ApiError
+	if !errors.As(err, &e) {
+		t.Fatalf("UpdateContactEmail() == %v, want an *ApiError in the chain", err)
+	}
+	if l := e.FieldErrors("/data/attributes/email"); len(l) != 1 || l[0].Code != "email_invalid" {
+		t.Errorf("FieldErrors(%q) == %+v, want one email_invalid error", "/data/attributes/email", l)
+	}
+}
+
+func TestGetContactByEmailErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"contacts":[],"meta":{"total":"0","page_input":{"limit":20,"offset":0}}}`)
+	}))
+	client := ac.NewClient(ts.URL, "test-token")
+	client.MaxAttempts = 1
+
+	_, err := client.GetContactByEmail("nobody@example.com")
+	if !errors.Is(err, ac.ErrNotFound) {
+		t.Errorf("GetContactByEmail() for missing contact == %v, want ErrNotFound", err)
+	}
+
+	// Nothing listening anymore
+	ts.Close()
+	_, err = client.GetContactByEmail("nobody@example.com")
+	if !errors.Is(err, ac.ErrTransport) || errors.Is(err, ac.ErrNotFound) {
+		t.Errorf("GetContactByEmail() with server down == %v, want ErrTransport", err)
+	}
+	var e *ac.TransportError
+	if !errors.As(err, &e) || e.Method != http.MethodGet {
+		t.Errorf("GetContactByEmail() with server down == %v, want *TransportError for GET", err)
+	}
+}
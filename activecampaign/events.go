@@ -0,0 +1,148 @@
+package activecampaign
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "net/http"
+    "net/url"
+    "strings"
+
+    "bitbucket.org/dagoodma/dagoodma-go/util"
+)
+
+// Events are posted to the tracking endpoint rather than the v3 API, using
+// the account's event key and actid (Settings > Tracking > Event Tracking).
+const (
+    EVENT_TRACKING_URL = "https://trackcmp.net/event"
+)
+
+var ErrEventTrackingDisabled = errors.New("Event tracking is not enabled for this account")
+
+// Returns whether event tracking is turned on for the account
+func (c *Client) IsEventTrackingEnabled() (bool, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_EVENT_TRACKING)
+    if err != nil {
+        return false, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    requestUrl := u.String()
+    r := c.DoApiRequestGet(requestUrl)
+    if r.Error != nil {
+        return false, fmt.Errorf("Failed retrieving event tracking status: %w", r.Error)
+    }
+
+    // Unmarshal the message
+    s := &RetrieveEventTracking{}
+    err = json.Unmarshal(r.Data, &s)
+    if err != nil {
+        return false, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return s.EventTracking.Enabled, nil
+}
+
+// Records the named event for the contact with the given email. The event
+// data is optional and shows up alongside the event in the contact's
+// activity, and can be matched on by automation triggers.
+func (c *Client) TrackEvent(email string, event string, eventData string) error {
+    if !util.EmailLooksValid(email) {
+        return fmt.Errorf("Invalid email given: %s", email)
+    }
+    if len(event) < 1 {
+        return fmt.Errorf("No event name given to track for: %s", email)
+    }
+    if c.EventKey == "" || c.EventActId == "" {
+        return fmt.Errorf("%w: client is missing an event key or actid" +
+            " (EVENT_KEY and EVENT_ACTID in secrets)", ErrEventTrackingDisabled)
+    }
+
+    // Build request data
+    visit, err := json.Marshal(TrackEventVisit{Email: email})
+    if err != nil {
+        return fmt.Errorf("Failed marshaling event visit data: %w", err)
+    }
+    v := url.Values{}
+    v.Set("actid", c.EventActId)
+    v.Set("key", c.EventKey)
+    v.Set("event", event)
+    if eventData != "" {
+        v.Set("eventdata", eventData)
+    }
+    v.Set("visit", string(visit))
+
+    // Send request
+    requestUrl := c.EventTrackingUrl
+    if requestUrl == "" {
+        requestUrl = EVENT_TRACKING_URL
+    }
+    if DEBUG {
+        log.Printf("Tracking event '%s' for '%s' at url: %s", event, email, requestUrl)
+    }
+    req, err := http.NewRequest(http.MethodPost, requestUrl, strings.NewReader(v.Encode()))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    userAgent := c.UserAgent
+    if userAgent == "" {
+        userAgent = USER_AGENT
+    }
+    req.Header.Set("User-Agent", userAgent)
+
+    resp, err := c.httpClient().Do(req)
+    if err != nil {
+        return fmt.Errorf("Failed tracking event '%s' for '%s': %w", event, email,
+            &TransportError{Method: http.MethodPost, Url: requestUrl, Err: err})
+    }
+    defer resp.Body.Close()
+
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return err
+    }
+    if resp.StatusCode != 200 {
+        return fmt.Errorf("Failed tracking event '%s' for '%s': unexpected response status '%d'",
+            event, email, resp.StatusCode)
+    }
+
+    // Unmarshal the message
+    r := &TrackEventResponse{}
+    err = json.Unmarshal(body, &r)
+    if err != nil {
+        return fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    if r.Success != 1 {
+        // The tracking endpoint doesn't say why, so check the account setting
+        enabled, err := c.IsEventTrackingEnabled()
+        if err == nil && !enabled {
+            return ErrEventTrackingDisabled
+        }
+        return fmt.Errorf("Failed tracking event '%s' for '%s': %s", event, email, r.Message)
+    }
+    return nil
+}
+
+/*
+ * Messages and unmarshalers
+ */
+// Event tracking status
+type RetrieveEventTracking struct {
+    EventTracking   RetrieveEventTrackingEventTracking  `json:"eventTracking"`
+}
+
+type RetrieveEventTrackingEventTracking struct {
+    Enabled     bool    `json:"enabled"`
+}
+
+// Track an event (the visit parameter)
+type TrackEventVisit struct {
+    Email       string  `json:"email"`
+}
+
+type TrackEventResponse struct {
+    Success     int     `json:"success"`
+    Message     string  `json:"message"`
+}
@@ -0,0 +1,69 @@
+package activecampaign_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func TestTrackEvent(t *testing.T) {
+	trackingEnabled := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/event":
+			if !trackingEnabled {
+				fmt.Fprint(w, `{"success":0,"message":""}`)
+				return
+			}
+			if r.FormValue("key") != "test-key" || r.FormValue("actid") != "123" {
+				fmt.Fprint(w, `{"success":0,"message":"Invalid key"}`)
+				return
+			}
+			if r.FormValue("visit") != `{"email":"tester@example.com"}` {
+				fmt.Fprint(w, `{"success":0,"message":"Invalid visit"}`)
+				return
+			}
+			fmt.Fprint(w, `{"success":1,"message":"Event spawned"}`)
+		case "/api/3/eventTracking":
+			fmt.Fprintf(w, `{"eventTracking":{"enabled":%t}}`, trackingEnabled)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		enabled     bool
+		key         string
+		email       string
+		wantError   bool
+		wantDisable bool
+	}{
+		{true, "test-key", "tester@example.com", false, false},
+		{true, "bad-key", "tester@example.com", true, false},
+		{true, "", "tester@example.com", true, false},
+		{true, "test-key", "not an email", true, false},
+		{false, "test-key", "tester@example.com", true, true},
+	}
+	for _, c := range cases {
+		trackingEnabled = c.enabled
+		client := ac.NewClient(ts.URL, "test-token")
+		client.EventKey = c.key
+		client.EventActId = "123"
+		client.EventTrackingUrl = ts.URL + "/event"
+		err := client.TrackEvent(c.email, "joined_school", "")
+		gotError := err != nil
+		if gotError != c.wantError {
+			t.Errorf("TrackEvent(%q) with key %q == (error=%t), want (error=%t), got err: %v",
+				c.email, c.key, gotError, c.wantError, err)
+			continue
+		}
+		if c.wantDisable && err != ac.ErrEventTrackingDisabled {
+			t.Errorf("TrackEvent(%q) with tracking disabled == %v, want %v",
+				c.email, err, ac.ErrEventTrackingDisabled)
+		}
+	}
+}
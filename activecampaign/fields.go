@@ -0,0 +1,244 @@
+package activecampaign
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "regexp"
+    "strings"
+
+    "github.com/xiam/to"
+)
+
+var RegexFieldId = regexp.MustCompile(`^\d+$`)
+
+// Returns all custom fields in the account. The list is fetched once and
+// cached on the client, use RefreshCustomFields() to fetch it again.
+func (c *Client) GetCustomFields() ([]ListFieldsField, error) {
+    c.fieldsMutex.Lock()
+    fields := c.fields
+    c.fieldsMutex.Unlock()
+    if fields != nil {
+        return fields, nil
+    }
+    if c.Cache != nil && c.Cache.Get(CACHE_FIELDS, &fields) {
+        c.fieldsMutex.Lock()
+        c.fields = fields
+        c.fieldsMutex.Unlock()
+        return fields, nil
+    }
+    return c.RefreshCustomFields()
+}
+
+// Fetches all custom fields and replaces the cached list
+func (c *Client) RefreshCustomFields() ([]ListFieldsField, error) {
+    var resultList []ListFieldsField
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_FIELDS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request query variables
+    var p QueryParameters
+    q, err := BuildQueryWithParams(p)
+    if err != nil {
+        return nil, fmt.Errorf("Failed parsing query parameters '%#v': %w", p, err)
+    }
+
+    // Iterate through all pages. Unlike the other list functions we fail on
+    // any error, since a partial field list would cause bad lookups.
+    it := c.NewPageIterator(context.Background(), u, q,
+        func() ListResponse { return &ListFields{} }, PageIteratorOptions{})
+    defer it.Close()
+    for it.Next() {
+        l := it.Page().(*ListFields)
+        resultList = append(resultList, l.Fields...)
+    }
+    if err := it.Err(); err != nil {
+        return nil, fmt.Errorf("Failed fetching custom fields: %w", err)
+    }
+    if len(resultList) < 1 {
+        return nil, newNotFoundError("Could not find any custom fields")
+    }
+    if DEBUG {
+        log.Printf("Fetched %d custom fields.", len(resultList))
+    }
+
+    c.fieldsMutex.Lock()
+    c.fields = resultList
+    c.fieldsMutex.Unlock()
+    c.putCache(CACHE_FIELDS, resultList)
+    return resultList, nil
+}
+
+// Finds a custom field by its personalization tag (e.g. "CHANGED_EMAIL" or
+// "%CHANGED_EMAIL%") or its title. Both are compared case-insensitively and
+// perstag matches take priority.
+func (c *Client) GetCustomFieldByName(name string) (*ListFieldsField, error) {
+    fields, err := c.GetCustomFields()
+    if err != nil {
+        return nil, err
+    }
+    f := matchCustomFieldName(fields, name)
+    if f == nil && c.shouldRefreshCache(CACHE_FIELDS) {
+        fields, err = c.RefreshCustomFields()
+        if err != nil {
+            return nil, err
+        }
+        f = matchCustomFieldName(fields, name)
+    }
+    if f == nil {
+        return nil, newNotFoundError("Could not find custom field with name: %s", name)
+    }
+    return f, nil
+}
+
+func matchCustomFieldName(fields []ListFieldsField, name string) *ListFieldsField {
+    perstag := normalizePerstag(name)
+    for i, f := range fields {
+        if normalizePerstag(f.PersonalizationTag) == perstag {
+            return &fields[i]
+        }
+    }
+    for i, f := range fields {
+        if strings.EqualFold(f.Title, name) {
+            return &fields[i]
+        }
+    }
+    return nil
+}
+
+// Returns the ID of a custom field given its ID, perstag, or title
+func (c *Client) GetCustomFieldId(field string) (string, error) {
+    if RegexFieldId.MatchString(field) {
+        return field, nil
+    }
+    f, err := c.GetCustomFieldByName(field)
+    if err != nil {
+        return "", err
+    }
+    return f.Id, nil
+}
+
+// Returns all of a contact's custom field values keyed by field perstag
+func (c *Client) GetContactFieldValues(contactId string) (map[string]string, error) {
+    contact, err := c.GetContactById(contactId)
+    if err != nil {
+        return nil, err
+    }
+    fields, err := c.GetCustomFields()
+    if err != nil {
+        return nil, err
+    }
+
+    perstagById := make(map[string]string)
+    for _, f := range fields {
+        perstagById[f.Id] = f.PersonalizationTag
+    }
+
+    values := make(map[string]string)
+    for _, v := range contact.FieldValues {
+        name, ok := perstagById[v.Field]
+        if !ok {
+            // Field was added since we cached the list
+            name = v.Field
+        }
+        values[name] = v.Value
+    }
+    return values, nil
+}
+
+// Returns the value of one of a contact's custom fields given its ID,
+// perstag, or title. An empty string is returned when the value isn't set.
+func (c *Client) GetContactFieldValue(contactId string, field string) (string, error) {
+    fieldId, err := c.GetCustomFieldId(field)
+    if err != nil {
+        return "", err
+    }
+    contact, err := c.GetContactById(contactId)
+    if err != nil {
+        return "", err
+    }
+    for _, v := range contact.FieldValues {
+        if v.Field == fieldId {
+            return v.Value, nil
+        }
+    }
+    return "", nil
+}
+
+func normalizePerstag(s string) string {
+    return strings.ToUpper(strings.Trim(strings.TrimSpace(s), "%"))
+}
+
+/*
+ * Messages and unmarshalers
+ */
+// List custom fields
+type ListFields struct {
+    Fields      []ListFieldsField   `json:"fields"`
+    Metadata    ListFieldsMetadata  `json:"meta"`
+}
+
+type _ListFields ListFields
+
+type ListFieldsField struct {
+    Title               string      `json:"title"`
+    Description         string      `json:"descript"`
+    Type                string      `json:"type"`
+    IsRequired          string      `json:"isrequired"`
+    PersonalizationTag  string      `json:"perstag"`
+    DefaultValue        string      `json:"defval"`
+    ShowInList          string      `json:"show_in_list"`
+    Rows                string      `json:"rows"`
+    Cols                string      `json:"cols"`
+    Visible             string      `json:"visible"`
+    Service             string      `json:"service"`
+    OrderNumber         string      `json:"ordernum"`
+    CreationDate        string      `json:"cdate"`
+    UpdateDate          string      `json:"udate"`
+    Options             []string    `json:"options"`
+    Relations           []string    `json:"relations"`
+    Links               ListFieldsFieldLinks    `json:"links"`
+    Id                  string      `json:"id"`
+}
+
+type ListFieldsFieldLinks struct {
+    Options     string  `json:"options"`
+    Relations   string  `json:"relations"`
+}
+
+type ListFieldsMetadata struct {
+    TotalRaw  string `json:"total"`
+    Total     uint64
+}
+
+func (l *ListFields) UnmarshalJSON(jsonStr []byte) error {
+    l2 := _ListFields{}
+
+    err := json.Unmarshal(jsonStr, &l2)
+    if err != nil {
+        return err
+    }
+
+    l2.Metadata.Total = to.Uint64(l2.Metadata.TotalRaw)
+
+    *l = ListFields(l2)
+
+    return nil
+}
+
+func (l *ListFields) totalResults() uint64 {
+    return l.Metadata.Total
+}
+
+func (l *ListFields) itemCount() int {
+    return len(l.Fields)
+}
+
+func (l *ListFields) truncateItems(n int) {
+    l.Fields = l.Fields[:n]
+}
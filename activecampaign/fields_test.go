@@ -0,0 +1,50 @@
+package activecampaign_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func TestGetCustomFieldByName(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"fields":[`+
+			`{"title":"Changed Email","perstag":"CHANGED_EMAIL","id":"71"},`+
+			`{"title":"Rainmaker ID","perstag":"RID","id":"8"}],`+
+			`"meta":{"total":"2"}}`)
+	}))
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	cases := []struct {
+		in        string
+		wantId    string
+		wantError bool
+	}{
+		{"CHANGED_EMAIL", "71", false},
+		{"%changed_email%", "71", false},
+		{"Rainmaker ID", "8", false},
+		{"rainmaker id", "8", false},
+		{"71", "71", false},
+		{"MISSING", "", true},
+	}
+	for _, c := range cases {
+		id, err := client.GetCustomFieldId(c.in)
+		gotError := err != nil
+		if gotError != c.wantError || id != c.wantId {
+			t.Errorf("GetCustomFieldId(%q) == (%q, error=%t), want (%q, error=%t)",
+				c.in, id, gotError, c.wantId, c.wantError)
+		}
+	}
+
+	// The first page holds everything, then it's cached
+	if requests != 1 {
+		t.Errorf("GetCustomFieldId() made %d requests, expected the field list to be cached after 1",
+			requests)
+	}
+}
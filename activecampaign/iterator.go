@@ -0,0 +1,227 @@
+package activecampaign
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "math"
+    "net/url"
+
+    "github.com/xiam/to"
+)
+
+type PageIteratorOptions struct {
+    MaxItems    int // stop after this many items, 0 for all of them
+    Concurrency int // pages fetched ahead at once, defaults to ConcurrencyLimit
+}
+
+// Walks the pages of a list endpoint in offset order. Pages are fetched
+// ahead concurrently, but only a bounded number are held at once, so large
+// pulls don't keep every response in memory. Use it like:
+//
+//  it := c.NewPageIterator(ctx, u, q, func() ListResponse { return &ListContacts{} }, opts)
+//  defer it.Close()
+//  for it.Next() {
+//      l := it.Page().(*ListContacts)
+//      ...
+//  }
+//  if err := it.Err(); err != nil {
+//      ...
+//  }
+type PageIterator struct {
+    client      *Client
+    ctx         context.Context
+    cancel      context.CancelFunc
+    url         *url.URL
+    query       url.Values
+    newPage     func() ListResponse
+    options     PageIteratorOptions
+
+    started     bool
+    pages       chan chan pageResult // in offset order
+    page        ListResponse
+    items       int
+    err         error
+}
+
+type pageResult struct {
+    page    ListResponse
+    err     error
+}
+
+// Creates an iterator over the list endpoint at u with query q. newPage must
+// return an empty response for each page to be unmarshaled into.
+func (c *Client) NewPageIterator(ctx context.Context, u *url.URL, q *url.Values,
+    newPage func() ListResponse, options PageIteratorOptions) *PageIterator {
+    ctx, cancel := context.WithCancel(ctx)
+    query := url.Values{}
+    if q != nil {
+        for k, v := range *q {
+            query[k] = v
+        }
+    }
+    u2 := *u
+    return &PageIterator{
+        client: c,
+        ctx: ctx,
+        cancel: cancel,
+        url: &u2,
+        query: query,
+        newPage: newPage,
+        options: options,
+    }
+}
+
+// Advances to the next page. Returns false when there are no more pages, the
+// item limit was reached, or an error occurred (see Err()).
+func (it *PageIterator) Next() bool {
+    if it.err != nil {
+        return false
+    }
+    if !it.started {
+        it.started = true
+        // The first page tells us how many more there are
+        p, err := it.fetchPage(0)
+        if err != nil {
+            it.fail(err)
+            return false
+        }
+        it.startFetching(p.totalResults())
+        return it.setPage(p)
+    }
+    if it.pages == nil || it.reachedMaxItems() {
+        return false
+    }
+
+    var ch chan pageResult
+    var ok bool
+    select {
+    case ch, ok = <-it.pages:
+    case <-it.ctx.Done():
+        it.fail(it.ctx.Err())
+        return false
+    }
+    if !ok {
+        return false
+    }
+    var r pageResult
+    select {
+    case r = <-ch:
+    case <-it.ctx.Done():
+        it.fail(it.ctx.Err())
+        return false
+    }
+    if r.err != nil {
+        it.fail(r.err)
+        return false
+    }
+    return it.setPage(r.page)
+}
+
+// Returns the current page. Type assert it to the type returned by newPage.
+func (it *PageIterator) Page() ListResponse {
+    return it.page
+}
+
+// Returns the first error that stopped the iterator, if any
+func (it *PageIterator) Err() error {
+    return it.err
+}
+
+// Stops any pages still being fetched. Safe to call more than once.
+func (it *PageIterator) Close() {
+    it.cancel()
+}
+
+func (it *PageIterator) fail(err error) {
+    it.err = err
+    it.page = nil
+    it.cancel()
+}
+
+func (it *PageIterator) reachedMaxItems() bool {
+    return it.options.MaxItems > 0 && it.items >= it.options.MaxItems
+}
+
+func (it *PageIterator) setPage(p ListResponse) bool {
+    n := p.itemCount()
+    if n < 1 {
+        it.page = nil
+        it.cancel()
+        return false
+    }
+    if it.options.MaxItems > 0 && it.items + n > it.options.MaxItems {
+        n = it.options.MaxItems - it.items
+        p.truncateItems(n)
+    }
+    it.items += n
+    it.page = p
+    if it.reachedMaxItems() {
+        it.cancel()
+    }
+    return true
+}
+
+// Starts fetching the remaining pages in the background. Each page gets its
+// own result channel, queued in order, and the queue's size bounds how far
+// ahead we fetch.
+func (it *PageIterator) startFetching(total uint64) {
+    if it.options.MaxItems > 0 && total > uint64(it.options.MaxItems) {
+        total = uint64(it.options.MaxItems)
+    }
+    pageTotal := int(math.Ceil(float64(total) / float64(API_LIMIT_MAXIMUM)))
+    if DEBUG {
+        log.Printf("Iterating over %d pages from endpoint '%s' with %d total results.",
+            pageTotal, it.url.Path, total)
+    }
+
+    concurrency := it.options.Concurrency
+    if concurrency < 1 {
+        concurrency = it.client.concurrencyLimit()
+    }
+    it.pages = make(chan chan pageResult, concurrency)
+
+    go func() {
+        defer close(it.pages)
+        for page := 1; page < pageTotal; page++ {
+            ch := make(chan pageResult, 1)
+            select {
+            case it.pages <- ch:
+            case <-it.ctx.Done():
+                return
+            }
+            go func(page int) {
+                p, err := it.fetchPage(page)
+                ch <- pageResult{page: p, err: err}
+            }(page)
+        }
+    }()
+}
+
+func (it *PageIterator) fetchPage(page int) (ListResponse, error) {
+    offset := page * API_LIMIT_MAXIMUM
+    q := url.Values{}
+    for k, v := range it.query {
+        q[k] = v
+    }
+    q.Set("offset", to.String(offset))
+    q.Set("limit", to.String(API_LIMIT_MAXIMUM))
+    u := *it.url
+    u.RawQuery = q.Encode()
+    if DEBUG {
+        log.Printf("Querying url: %s", u.String())
+    }
+
+    r := it.client.doApiRequestContext(it.ctx, "GET", u.String(), nil, 200)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed fetching page at offset %d from '%s': %w",
+            offset, it.url.Path, r.Error)
+    }
+    p := it.newPage()
+    err := json.Unmarshal(r.Data, p)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return p, nil
+}
@@ -0,0 +1,111 @@
+package activecampaign_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+// Serves total contacts with IDs 1..total, failing at failOffset if it's set
+func newContactsServer(total int, failOffset int, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if failOffset > 0 && offset == failOffset {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var contacts []string
+		for i := offset + 1; i <= offset+limit && i <= total; i++ {
+			contacts = append(contacts, fmt.Sprintf(`{"id":"%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"contacts":[%s],"meta":{"total":"%d","page_input":{"limit":%d,"offset":%d}}}`,
+			strings.Join(contacts, ","), total, limit, offset)
+	}))
+}
+
+func TestPageIterator(t *testing.T) {
+	cases := []struct {
+		total        int
+		maxItems     int
+		failOffset   int
+		wantItems    int
+		wantRequests int32
+		wantError    bool
+	}{
+		{0, 0, 0, 0, 1, false},
+		{50, 0, 0, 50, 1, false},
+		{250, 0, 0, 250, 3, false},
+		{250, 150, 0, 150, 2, false},
+		{250, 100, 0, 100, 1, false},
+		{350, 0, 200, 200, -1, true},
+	}
+	for _, c := range cases {
+		var requests int32
+		ts := newContactsServer(c.total, c.failOffset, &requests)
+		client := ac.NewClient(ts.URL, "test-token")
+		client.RateLimit = 0
+		client.MaxAttempts = 1
+		u, _ := ac.BuildRequestUrl(client.ApiUrl, ac.API_URL_CONTACTS)
+
+		it := client.NewPageIterator(context.Background(), u, nil,
+			func() ac.ListResponse { return &ac.ListContacts{} },
+			ac.PageIteratorOptions{MaxItems: c.maxItems})
+		items := 0
+		inOrder := true
+		for it.Next() {
+			for _, contact := range it.Page().(*ac.ListContacts).Contacts {
+				items++
+				if contact.Id != strconv.Itoa(items) {
+					inOrder = false
+				}
+			}
+		}
+		it.Close()
+		ts.Close()
+
+		gotError := it.Err() != nil
+		if gotError != c.wantError || items != c.wantItems || !inOrder {
+			t.Errorf("PageIterator over %d (max %d) == (items=%d, inOrder=%t, error=%t), want (items=%d, inOrder=true, error=%t), got err: %v",
+				c.total, c.maxItems, items, inOrder, gotError, c.wantItems, c.wantError, it.Err())
+		}
+		if c.wantRequests > 0 && requests != c.wantRequests {
+			t.Errorf("PageIterator over %d (max %d) made %d requests, want %d",
+				c.total, c.maxItems, requests, c.wantRequests)
+		}
+	}
+}
+
+func TestPageIteratorCancel(t *testing.T) {
+	var requests int32
+	ts := newContactsServer(1000, 0, &requests)
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+	client.RateLimit = 0
+	u, _ := ac.BuildRequestUrl(client.ApiUrl, ac.API_URL_CONTACTS)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := client.NewPageIterator(ctx, u, nil,
+		func() ac.ListResponse { return &ac.ListContacts{} }, ac.PageIteratorOptions{})
+	defer it.Close()
+	pages := 0
+	for it.Next() {
+		pages++
+		if pages == 2 {
+			cancel()
+		}
+	}
+	if it.Err() != context.Canceled {
+		t.Errorf("PageIterator after cancel == (pages=%d, err=%v), want err=%v",
+			pages, it.Err(), context.Canceled)
+	}
+}
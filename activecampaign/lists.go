@@ -0,0 +1,304 @@
+package activecampaign
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "strings"
+
+    "github.com/xiam/to"
+)
+
+// Contact list statuses
+const (
+    LIST_STATUS_UNCONFIRMED = 0
+    LIST_STATUS_ACTIVE = 1
+    LIST_STATUS_UNSUBSCRIBED = 2
+    LIST_STATUS_BOUNCED = 3
+)
+
+// Returns all lists in the account, from the cache if the client has one
+func (c *Client) GetLists() ([]ListListsList, error) {
+    var lists []ListListsList
+    if c.Cache != nil && c.Cache.Get(CACHE_LISTS, &lists) {
+        return lists, nil
+    }
+    return c.RefreshLists()
+}
+
+// Fetches all lists and replaces the cached ones
+func (c *Client) RefreshLists() ([]ListListsList, error) {
+    var resultList []ListListsList
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_LISTS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request query variables
+    var p QueryParameters
+    q, err := BuildQueryWithParams(p)
+    if err != nil {
+        return nil, fmt.Errorf("Failed parsing query parameters '%#v': %w", p, err)
+    }
+
+    // Iterate through all pages
+    it := c.NewPageIterator(context.Background(), u, q,
+        func() ListResponse { return &ListLists{} }, PageIteratorOptions{})
+    defer it.Close()
+    for it.Next() {
+        l := it.Page().(*ListLists)
+        resultList = append(resultList, l.Lists...)
+    }
+    if err := it.Err(); err != nil {
+        return nil, fmt.Errorf("Failed fetching lists: %w", err)
+    }
+    if len(resultList) < 1 {
+        return nil, newNotFoundError("Could not find any lists")
+    }
+    if DEBUG {
+        log.Printf("Fetched %d lists.", len(resultList))
+    }
+    c.putCache(CACHE_LISTS, resultList)
+    return resultList, nil
+}
+
+// Finds a list by name. An exact match is preferred, otherwise the names are
+// compared case-insensitively.
+func (c *Client) GetListByName(name string) (*ListListsList, error) {
+    lists, err := c.GetLists()
+    if err != nil {
+        return nil, err
+    }
+    l := matchListName(lists, name)
+    if l == nil && c.shouldRefreshCache(CACHE_LISTS) {
+        lists, err = c.RefreshLists()
+        if err != nil {
+            return nil, err
+        }
+        l = matchListName(lists, name)
+    }
+    if l == nil {
+        return nil, newNotFoundError("Could not find list with name: %s", name)
+    }
+    return l, nil
+}
+
+func matchListName(lists []ListListsList, name string) *ListListsList {
+    for i, l := range lists {
+        if l.Name == name {
+            return &lists[i]
+        }
+    }
+    for i, l := range lists {
+        if strings.EqualFold(l.Name, name) {
+            return &lists[i]
+        }
+    }
+    return nil
+}
+
+// Returns the contact's status in each list they've been added to
+func (c *Client) GetContactLists(contactId string) ([]RetrieveContactList, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, contactId, API_URL_CONTACT_LISTS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    requestUrl := u.String()
+    r := c.DoApiRequestGet(requestUrl)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed retrieving lists for contact with ID %s: %w",
+            contactId, r.Error)
+    }
+
+    // Unmarshal the message
+    l := &ListContactLists{}
+    err = json.Unmarshal(r.Data, &l)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return l.ContactLists, nil
+}
+
+// Returns the contact's status in the given list, or -1 if they were never
+// added to it
+func (c *Client) GetContactListStatus(contactId string, listId string) (int, error) {
+    lists, err := c.GetContactLists(contactId)
+    if err != nil {
+        return -1, err
+    }
+    for _, l := range lists {
+        if l.List == listId {
+            return to.Int(l.Status), nil
+        }
+    }
+    return -1, nil
+}
+
+// Sets the contact's status in a list (see LIST_STATUS_*)
+func (c *Client) UpdateContactListStatus(contactId string, listId string, status int) error {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACT_LISTS)
+    if err != nil {
+        return fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data
+    var m UpdateContactList
+    m.ContactList.Contact = contactId
+    m.ContactList.List = listId
+    m.ContactList.Status = status
+    data, err := json.Marshal(m)
+    if err != nil {
+        return fmt.Errorf("Failed marshaling update contact list request data: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.doApiRequest("POST", requestUrl, data, 200, 201)
+    if r.Error != nil {
+        return fmt.Errorf("Failed updating status of contact with ID %s in list %s: %w",
+            contactId, listId, r.Error)
+    }
+    return nil
+}
+
+func (c *Client) SubscribeContactToList(contactId string, listId string) error {
+    return c.UpdateContactListStatus(contactId, listId, LIST_STATUS_ACTIVE)
+}
+
+func (c *Client) UnsubscribeContactFromList(contactId string, listId string) error {
+    return c.UpdateContactListStatus(contactId, listId, LIST_STATUS_UNSUBSCRIBED)
+}
+
+func (c *Client) SubscribeContactToListByName(contactId string, listName string) error {
+    l, err := c.GetListByName(listName)
+    if err != nil {
+        return err
+    }
+    return c.SubscribeContactToList(contactId, l.Id)
+}
+
+func (c *Client) UnsubscribeContactFromListByName(contactId string, listName string) error {
+    l, err := c.GetListByName(listName)
+    if err != nil {
+        return err
+    }
+    return c.UnsubscribeContactFromList(contactId, l.Id)
+}
+
+/*
+ * Messages and unmarshalers
+ */
+// List all lists
+type ListLists struct {
+    Lists       []ListListsList     `json:"lists"`
+    Metadata    ListListsMetadata   `json:"meta"`
+}
+
+type _ListLists ListLists
+
+type ListListsList struct {
+    StringId            string  `json:"stringid"`
+    UserId              string  `json:"userid"`
+    Name                string  `json:"name"`
+    CreationDate        string  `json:"cdate"`
+    UseTracking         string  `json:"p_use_tracking"`
+    UseAnalyticsRead    string  `json:"p_use_analytics_read"`
+    UseAnalyticsLink    string  `json:"p_use_analytics_link"`
+    UseTwitter          string  `json:"p_use_twitter"`
+    UseFacebook         string  `json:"p_use_facebook"`
+    EmbedImage          string  `json:"p_embed_image"`
+    UseCaptcha          string  `json:"p_use_captcha"`
+    SendLastBroadcast   string  `json:"send_last_broadcast"`
+    Private             string  `json:"private"`
+    AnalyticsDomains    string  `json:"analytics_domains"`
+    AnalyticsSource     string  `json:"analytics_source"`
+    AnalyticsUa         string  `json:"analytics_ua"`
+    TwitterToken        string  `json:"twitter_token"`
+    TwitterTokenSecret  string  `json:"twitter_token_secret"`
+    FacebookSession     string  `json:"facebook_session"`
+    CarbonCopy          string  `json:"carboncopy"`
+    SubscriptionNotify  string  `json:"subscription_notify"`
+    UnsubscriptionNotify string `json:"unsubscription_notify"`
+    RequireName         string  `json:"require_name"`
+    GetUnsubscribeReason string `json:"get_unsubscribe_reason"`
+    ToName              string  `json:"to_name"`
+    OptinOptout         string  `json:"optinoptout"`
+    SenderName          string  `json:"sender_name"`
+    SenderAddress1      string  `json:"sender_addr1"`
+    SenderAddress2      string  `json:"sender_addr2"`
+    SenderCity          string  `json:"sender_city"`
+    SenderState         string  `json:"sender_state"`
+    SenderZip           string  `json:"sender_zip"`
+    SenderCountry       string  `json:"sender_country"`
+    SenderPhone         string  `json:"sender_phone"`
+    SenderUrl           string  `json:"sender_url"`
+    SenderReminder      string  `json:"sender_reminder"`
+    FullAddress         string  `json:"fulladdress"`
+    OptinMessageId      string  `json:"optinmessageid"`
+    OptoutConf          string  `json:"optoutconf"`
+    DeleteStamp         string  `json:"deletestamp"`
+    UpdateDate          string  `json:"udate"`
+    Links               ListListsListLinks  `json:"links"`
+    Id                  string  `json:"id"`
+    User                string  `json:"user"`
+}
+
+type ListListsListLinks struct {
+    ContactGoalLists    string  `json:"contactGoalLists"`
+    User                string  `json:"user"`
+    AddressLists        string  `json:"addressLists"`
+}
+
+type ListListsMetadata struct {
+    TotalRaw  string `json:"total"`
+    Total     uint64
+}
+
+func (l *ListLists) UnmarshalJSON(jsonStr []byte) error {
+    l2 := _ListLists{}
+
+    err := json.Unmarshal(jsonStr, &l2)
+    if err != nil {
+        return err
+    }
+
+    l2.Metadata.Total = to.Uint64(l2.Metadata.TotalRaw)
+
+    *l = ListLists(l2)
+
+    return nil
+}
+
+func (l *ListLists) totalResults() uint64 {
+    return l.Metadata.Total
+}
+
+func (l *ListLists) itemCount() int {
+    return len(l.Lists)
+}
+
+func (l *ListLists) truncateItems(n int) {
+    l.Lists = l.Lists[:n]
+}
+
+// List a contact's lists
+type ListContactLists struct {
+    ContactLists    []RetrieveContactList   `json:"contactLists"`
+}
+
+// Subscribe or unsubscribe a contact from a list
+type UpdateContactList struct {
+    ContactList     UpdateContactListContactList    `json:"contactList"`
+}
+
+type UpdateContactListContactList struct {
+    List        string  `json:"list"`
+    Contact     string  `json:"contact"`
+    Status      int     `json:"status"`
+}
@@ -0,0 +1,157 @@
+package activecampaign_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign/actest"
+)
+
+func TestGetListByName(t *testing.T) {
+	requests := 0
+	lists := []string{`{"id":"1","name":"newsletter"}`, `{"id":"2","name":"Newsletter"}`}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/3/lists" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests++
+		fmt.Fprintf(w, `{"lists":[%s],"meta":{"total":"%d"}}`, strings.Join(lists, ","), len(lists))
+	}))
+	defer ts.Close()
+	cachePath := filepath.Join(t.TempDir(), "ac_cache.json")
+
+	client := ac.NewClient(ts.URL, "test-token")
+	client.Cache = ac.NewMetadataCache(cachePath, time.Hour)
+	all, err := client.GetLists()
+	if err != nil || len(all) != 2 {
+		t.Fatalf("GetLists() == (%+v, %v), want 2 lists", all, err)
+	}
+
+	// Another run reads the cache file, which doesn't have Students yet
+	lists = append(lists, `{"id":"3","name":"Students"}`)
+	client = ac.NewClient(ts.URL, "test-token")
+	client.Cache = ac.NewMetadataCache(cachePath, time.Hour)
+
+	cases := []struct {
+		nameIn    string
+		wantId    string
+		wantError bool
+	}{
+		{"Newsletter", "2", false}, // exact match wins
+		{"newsletter", "1", false},
+		{"NEWSLETTER", "1", false},
+		{"Students", "3", false}, // only there after refreshing
+		{"Missing", "", true},
+	}
+	for _, c := range cases {
+		l, err := client.GetListByName(c.nameIn)
+		gotError := err != nil
+		if gotError != c.wantError {
+			t.Errorf("GetListByName(%q) == (error=%t), want (error=%t), got err: %v",
+				c.nameIn, gotError, c.wantError, err)
+			continue
+		}
+		if gotError {
+			if !errors.Is(err, ac.ErrNotFound) {
+				t.Errorf("GetListByName(%q) == %v, want ErrNotFound", c.nameIn, err)
+			}
+			continue
+		}
+		if l.Id != c.wantId {
+			t.Errorf("GetListByName(%q) == %s, want %s", c.nameIn, l.Id, c.wantId)
+		}
+	}
+	// One fetch to fill the cache, and one refresh for the first miss
+	if requests != 2 {
+		t.Errorf("Got %d list requests, want 2", requests)
+	}
+}
+
+func TestUpdateContactListStatus(t *testing.T) {
+	var got []ac.UpdateContactListContactList
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/3/contactLists" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var m ac.UpdateContactList
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Errorf("Failed decoding request body: %v", err)
+		}
+		got = append(got, m.ContactList)
+		fmt.Fprint(w, `{"contactList":{}}`)
+	}))
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	if err := client.SubscribeContactToList("42", "3"); err != nil {
+		t.Errorf("SubscribeContactToList() returned error: %v", err)
+	}
+	if err := client.UnsubscribeContactFromList("42", "3"); err != nil {
+		t.Errorf("UnsubscribeContactFromList() returned error: %v", err)
+	}
+	want := []ac.UpdateContactListContactList{
+		{List: "3", Contact: "42", Status: ac.LIST_STATUS_ACTIVE},
+		{List: "3", Contact: "42", Status: ac.LIST_STATUS_UNSUBSCRIBED},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Sent %+v, want %+v", got, want)
+	}
+}
+
+func TestContactListStatus(t *testing.T) {
+	s := actest.NewServer()
+	defer s.Close()
+	err := s.Seed(actest.Fixtures{
+		Lists: []actest.ListFixture{{Id: "3", Name: "Newsletter"}, {Id: "4", Name: "Students"}},
+		Contacts: []actest.ContactFixture{{Id: "42", Email: "tester@example.com",
+			Lists: []string{"Newsletter"}}},
+	})
+	if err != nil {
+		t.Fatalf("Seed() returned error: %v", err)
+	}
+	client := s.Client()
+
+	cases := []struct {
+		listIdIn   string
+		wantStatus int
+	}{
+		{"3", ac.LIST_STATUS_ACTIVE},
+		{"4", -1}, // never added
+	}
+	for _, c := range cases {
+		status, err := client.GetContactListStatus("42", c.listIdIn)
+		if err != nil || status != c.wantStatus {
+			t.Errorf("GetContactListStatus(%q) == (%d, %v), want %d", c.listIdIn, status, err,
+				c.wantStatus)
+		}
+	}
+
+	if err := client.UnsubscribeContactFromListByName("42", "Newsletter"); err != nil {
+		t.Errorf("UnsubscribeContactFromListByName() returned error: %v", err)
+	}
+	if err := client.SubscribeContactToListByName("42", "students"); err != nil {
+		t.Errorf("SubscribeContactToListByName() returned error: %v", err)
+	}
+	if got := s.ContactListStatus("42", "Newsletter"); got != ac.LIST_STATUS_UNSUBSCRIBED {
+		t.Errorf("Newsletter status == %d, want %d", got, ac.LIST_STATUS_UNSUBSCRIBED)
+	}
+	if got := s.ContactListStatus("42", "Students"); got != ac.LIST_STATUS_ACTIVE {
+		t.Errorf("Students status == %d, want %d", got, ac.LIST_STATUS_ACTIVE)
+	}
+	err = client.SubscribeContactToListByName("42", "Missing")
+	if !errors.Is(err, ac.ErrNotFound) {
+		t.Errorf("SubscribeContactToListByName() of missing list == %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,297 @@
+package activecampaign
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "strings"
+
+    "github.com/xiam/to"
+)
+
+// What to do with the old contact once it's been merged
+const (
+    MERGE_KEEP_OLD_CONTACT = iota
+    MERGE_FLAG_OLD_CONTACT // tag it with MergeOptions.FlagTag
+    MERGE_DELETE_OLD_CONTACT
+)
+
+const (
+    DEFAULT_MERGE_FLAG_TAG = "Merged_Duplicate"
+)
+
+// Returned when the old contact already has the merge flag tag, e.g. when a
+// webhook that merged it is delivered again
+var ErrAlreadyMerged = errors.New("Contact was already merged")
+
+type MergeOptions struct {
+    DryRun          bool // only return the plan, don't change anything
+    OldContact      int // one of MERGE_*_OLD_CONTACT
+    FlagTag         string // defaults to DEFAULT_MERGE_FLAG_TAG
+}
+
+func (o *MergeOptions) flagTag() string {
+    if o.FlagTag == "" {
+        return DEFAULT_MERGE_FLAG_TAG
+    }
+    return o.FlagTag
+}
+
+// The changes a merge makes (or would make, for a dry run) to the surviving
+// contact
+type MergePlan struct {
+    FromId          string
+    FromEmail       string
+    ToId            string
+    ToEmail         string
+    Tags            []string // tag IDs to add
+    Lists           map[string]int // list ID to status to set
+    FieldValues     map[string]string // field ID to value, only fields still empty
+    Notes           []string // notes to copy
+    MergeNote       string // recorded on the surviving contact
+    OldContact      int
+}
+
+func (p *MergePlan) String() string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "Merge contact '%s' (%s) into '%s' (%s):\n", p.FromEmail, p.FromId,
+        p.ToEmail, p.ToId)
+    fmt.Fprintf(&b, "  Add %d tags: %v\n", len(p.Tags), p.Tags)
+    fmt.Fprintf(&b, "  Set %d list statuses: %v\n", len(p.Lists), p.Lists)
+    fmt.Fprintf(&b, "  Set %d custom fields: %v\n", len(p.FieldValues), p.FieldValues)
+    fmt.Fprintf(&b, "  Copy %d notes\n", len(p.Notes))
+    switch p.OldContact {
+    case MERGE_FLAG_OLD_CONTACT:
+        b.WriteString("  Flag the old contact\n")
+    case MERGE_DELETE_OLD_CONTACT:
+        b.WriteString("  Delete the old contact\n")
+    }
+    return b.String()
+}
+
+// Merges the contact with ID fromId into the one with ID toId, copying over
+// tags, list memberships, custom field values the surviving contact hasn't
+// set, and notes, then recording a note about the merge. Unsubscribes on the
+// old contact carry over so they're never resubscribed. Returns the plan,
+// which for a dry run is all that happens. An old contact that already has
+// the flag tag isn't merged again, and ErrAlreadyMerged is returned.
+func (c *Client) MergeContacts(fromId string, toId string, options MergeOptions) (*MergePlan, error) {
+    if fromId == toId {
+        return nil, fmt.Errorf("Can't merge contact with ID %s into itself", fromId)
+    }
+    merged, err := c.contactHasTagName(fromId, options.flagTag())
+    if err != nil {
+        return nil, fmt.Errorf("Failed checking if contact %s was already merged: %w", fromId, err)
+    }
+    if merged {
+        return nil, fmt.Errorf("Contact %s has tag '%s': %w", fromId, options.flagTag(),
+            ErrAlreadyMerged)
+    }
+    plan, err := c.planMerge(fromId, toId, options)
+    if err != nil {
+        return nil, fmt.Errorf("Failed planning merge of contact %s into %s: %w", fromId, toId, err)
+    }
+    if options.DryRun {
+        return plan, nil
+    }
+
+    for _, tagId := range plan.Tags {
+        _, err = c.AddTagToContact(toId, tagId)
+        if err != nil {
+            return plan, fmt.Errorf("Failed copying tags to contact %s: %w", toId, err)
+        }
+    }
+    for listId, status := range plan.Lists {
+        err = c.UpdateContactListStatus(toId, listId, status)
+        if err != nil {
+            return plan, fmt.Errorf("Failed copying lists to contact %s: %w", toId, err)
+        }
+    }
+    if len(plan.FieldValues) > 0 {
+        err = c.updateContactFieldValues(toId, plan.FieldValues)
+        if err != nil {
+            return plan, fmt.Errorf("Failed copying custom fields to contact %s: %w", toId, err)
+        }
+    }
+    for _, n := range plan.Notes {
+        err = c.AddNoteToContact(toId, n)
+        if err != nil {
+            return plan, fmt.Errorf("Failed copying notes to contact %s: %w", toId, err)
+        }
+    }
+    _, err = c.AddNoteToContactOnce(toId, plan.MergeNote)
+    if err != nil {
+        return plan, fmt.Errorf("Failed adding merge note to contact %s: %w", toId, err)
+    }
+
+    switch options.OldContact {
+    case MERGE_FLAG_OLD_CONTACT:
+        _, err = c.AddTagToContactByName(fromId, options.flagTag(), true)
+        if err != nil {
+            return plan, fmt.Errorf("Failed flagging old contact %s: %w", fromId, err)
+        }
+        _, err = c.AddNoteToContactOnce(fromId, fmt.Sprintf("Merged into contact '%s' (%s)",
+            plan.ToEmail, toId))
+        if err != nil {
+            return plan, fmt.Errorf("Failed adding merge note to old contact %s: %w", fromId, err)
+        }
+    case MERGE_DELETE_OLD_CONTACT:
+        err = c.DeleteContact(fromId)
+        if err != nil {
+            return plan, fmt.Errorf("Failed deleting old contact %s: %w", fromId, err)
+        }
+    }
+    if DEBUG {
+        log.Printf("Merged contact %s into %s", fromId, toId)
+    }
+    return plan, nil
+}
+
+func (c *Client) planMerge(fromId string, toId string, options MergeOptions) (*MergePlan, error) {
+    from, err := c.GetContactById(fromId)
+    if err != nil {
+        return nil, err
+    }
+    into, err := c.GetContactById(toId)
+    if err != nil {
+        return nil, err
+    }
+    plan := &MergePlan{
+        FromId: fromId,
+        FromEmail: from.Contact.Email,
+        ToId: toId,
+        ToEmail: into.Contact.Email,
+        Lists: make(map[string]int),
+        FieldValues: make(map[string]string),
+        OldContact: options.OldContact,
+    }
+
+    // Tags
+    fromTags, err := c.GetContactTagAssociations(fromId)
+    if err != nil {
+        return nil, err
+    }
+    toTags, err := c.GetContactTagAssociations(toId)
+    if err != nil {
+        return nil, err
+    }
+    hasTag := make(map[string]bool)
+    for _, t := range toTags {
+        hasTag[t.Tag] = true
+    }
+    for _, t := range fromTags {
+        if !hasTag[t.Tag] {
+            hasTag[t.Tag] = true
+            plan.Tags = append(plan.Tags, t.Tag)
+        }
+    }
+
+    // Lists, where an unsubscribe always wins
+    toStatus := make(map[string]int)
+    for _, l := range into.Lists {
+        toStatus[l.List] = to.Int(l.Status)
+    }
+    for _, l := range from.Lists {
+        status := to.Int(l.Status)
+        current, ok := toStatus[l.List]
+        if !ok || (status == LIST_STATUS_UNSUBSCRIBED && current != status) {
+            plan.Lists[l.List] = status
+        }
+    }
+
+    // Custom fields the surviving contact doesn't have
+    toValues := make(map[string]string)
+    for _, v := range into.FieldValues {
+        toValues[v.Field] = v.Value
+    }
+    for _, v := range from.FieldValues {
+        if v.Value != "" && toValues[v.Field] == "" {
+            plan.FieldValues[v.Field] = v.Value
+        }
+    }
+
+    // Notes
+    notes, err := c.GetContactNotes(fromId)
+    if err != nil {
+        return nil, err
+    }
+    for _, n := range notes {
+        plan.Notes = append(plan.Notes, fmt.Sprintf("(Merged from '%s', %s) %s",
+            plan.FromEmail, n.CreationDate, n.Note))
+    }
+
+    plan.MergeNote = fmt.Sprintf("Merged contact '%s' (%s) into this contact: copied %d tags," +
+        " %d list statuses, %d custom fields, and %d notes.", plan.FromEmail, fromId,
+        len(plan.Tags), len(plan.Lists), len(plan.FieldValues), len(plan.Notes))
+    return plan, nil
+}
+
+// Whether the contact has the tag with the given name. A tag that doesn't
+// exist yet means they can't have it.
+func (c *Client) contactHasTagName(contactId string, tag string) (bool, error) {
+    t, _, err := c.findTagByName(tag)
+    if err != nil || t == nil {
+        return false, err
+    }
+    ct, err := c.getContactTag(contactId, t.Id)
+    if err != nil {
+        return false, err
+    }
+    return ct != nil, nil
+}
+
+// Sets several custom fields (by field ID) on a contact in one request
+func (c *Client) updateContactFieldValues(contactId string, values map[string]string) error {
+    contact, err := c.GetContactById(contactId)
+    if err != nil {
+        return err
+    }
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, contactId)
+    if err != nil {
+        return fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data, keeping their current details
+    var m UpdateContact
+    m.Contact.Email = contact.Contact.Email
+    m.Contact.FirstName = contact.Contact.FirstName
+    m.Contact.LastName = contact.Contact.LastName
+    m.Contact.Phone = contact.Contact.Phone
+    for field, value := range values {
+        m.Contact.FieldValues = append(m.Contact.FieldValues,
+            UpdateContactContactFieldValue{Field: field, Value: value})
+    }
+    data, err := json.Marshal(m)
+    if err != nil {
+        return fmt.Errorf("Failed marshaling update contact request data: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestPut(requestUrl, data)
+    if r.Error != nil {
+        return fmt.Errorf("Failed updating data for contact with ID %s: %w",
+            contactId, r.Error)
+    }
+    return nil
+}
+
+// Deletes the contact with the given ID
+func (c *Client) DeleteContact(id string) error {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, id)
+    if err != nil {
+        return fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestDelete(requestUrl)
+    if r.Error != nil {
+        return fmt.Errorf("Failed deleting contact with ID %s: %w", id, r.Error)
+    }
+    return nil
+}
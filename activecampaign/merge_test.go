@@ -0,0 +1,132 @@
+package activecampaign_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+// Serves an old contact (1) and the one it's merged into (2), recording
+// every request that changes something. A flagged old contact has the merge
+// flag tag (30).
+func newMergeServer(t *testing.T, flagged bool) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var writes []string
+	responses := map[string]string{
+		"/api/3/contacts/1": `{"contact":{"email":"old@example.com","id":"1"},` +
+			`"contactLists":[{"list":"3","status":"1"},{"list":"4","status":"2"},{"list":"5","status":"1"}],` +
+			`"fieldValues":[{"field":"8","value":"987"},{"field":"9","value":"old"},{"field":"10","value":""}]}`,
+		"/api/3/contacts/2": `{"contact":{"email":"new@example.com","firstName":"Jane","id":"2"},` +
+			`"contactLists":[{"list":"4","status":"1"},{"list":"5","status":"1"}],` +
+			`"fieldValues":[{"field":"9","value":"new"}]}`,
+		"/api/3/contacts/1/contactTags": `{"contactTags":[{"tag":"20","id":"100"},{"tag":"21","id":"101"}]}`,
+		"/api/3/contacts/2/contactTags": `{"contactTags":[{"tag":"21","id":"102"}]}`,
+		"/api/3/contacts/1/notes":       `{"notes":[{"id":"7","note":"Webhook updated contact","cdate":"2020-05-01"}]}`,
+		"/api/3/contacts/2/notes":       `{"notes":[]}`,
+		"/api/3/tags":                   `{"tags":[{"id":"30","tag":"Merged_Duplicate"}],"meta":{"total":"1"}}`,
+	}
+	if flagged {
+		responses["/api/3/contacts/1/contactTags"] = `{"contactTags":[{"tag":"20","id":"100"},{"tag":"30","id":"103"}]}`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			mu.Lock()
+			writes = append(writes, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			switch r.Method {
+			case http.MethodPost:
+				w.WriteHeader(http.StatusCreated)
+			}
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	return ts, &writes
+}
+
+func TestMergeContactsDryRun(t *testing.T) {
+	ts, writes := newMergeServer(t, false)
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	plan, err := client.MergeContacts("1", "2", ac.MergeOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("MergeContacts() returned error: %v", err)
+	}
+	if len(*writes) > 0 {
+		t.Errorf("MergeContacts() dry run made changes: %v", *writes)
+	}
+	if !reflect.DeepEqual(plan.Tags, []string{"20"}) {
+		t.Errorf("plan.Tags == %v, want [20]", plan.Tags)
+	}
+	// List 4 unsubscribed wins over subscribed, list 5 is already set
+	wantLists := map[string]int{"3": ac.LIST_STATUS_ACTIVE, "4": ac.LIST_STATUS_UNSUBSCRIBED}
+	if !reflect.DeepEqual(plan.Lists, wantLists) {
+		t.Errorf("plan.Lists == %v, want %v", plan.Lists, wantLists)
+	}
+	// Field 9 is kept on the surviving contact and empty values are skipped
+	wantFields := map[string]string{"8": "987"}
+	if !reflect.DeepEqual(plan.FieldValues, wantFields) {
+		t.Errorf("plan.FieldValues == %v, want %v", plan.FieldValues, wantFields)
+	}
+	if len(plan.Notes) != 1 || plan.MergeNote == "" {
+		t.Errorf("plan.Notes == %v, MergeNote == %q", plan.Notes, plan.MergeNote)
+	}
+}
+
+func TestMergeContacts(t *testing.T) {
+	ts, writes := newMergeServer(t, false)
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	_, err := client.MergeContacts("1", "2", ac.MergeOptions{OldContact: ac.MERGE_DELETE_OLD_CONTACT})
+	if err != nil {
+		t.Fatalf("MergeContacts() returned error: %v", err)
+	}
+	got := append([]string{}, *writes...)
+	sort.Strings(got)
+	want := []string{
+		"DELETE /api/3/contacts/1",
+		"POST /api/3/contactLists",
+		"POST /api/3/contactLists",
+		"POST /api/3/contactTags",
+		"POST /api/3/notes",
+		"POST /api/3/notes",
+		"PUT /api/3/contacts/2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeContacts() made requests %v, want %v", got, want)
+	}
+
+	if _, err := client.MergeContacts("1", "1", ac.MergeOptions{}); err == nil {
+		t.Errorf("MergeContacts() into itself returned no error")
+	}
+}
+
+func TestMergeContactsAlreadyMerged(t *testing.T) {
+	ts, writes := newMergeServer(t, true)
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	_, err := client.MergeContacts("1", "2", ac.MergeOptions{OldContact: ac.MERGE_FLAG_OLD_CONTACT})
+	if !errors.Is(err, ac.ErrAlreadyMerged) {
+		t.Errorf("MergeContacts() of a flagged contact == %v, want ErrAlreadyMerged", err)
+	}
+	if len(*writes) > 0 {
+		t.Errorf("MergeContacts() of a flagged contact made changes: %v", *writes)
+	}
+}
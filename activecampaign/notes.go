@@ -0,0 +1,244 @@
+package activecampaign
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/xiam/to"
+)
+
+// Layouts AC uses for note dates
+var noteTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05"}
+
+// Returns all of a contact's notes, oldest first
+func (c *Client) GetContactNotes(contactId string) ([]Note, error) {
+    var resultList []Note
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, contactId, API_URL_NOTES)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Iterate through all pages
+    it := c.NewPageIterator(context.Background(), u, nil,
+        func() ListResponse { return &ListNotes{} }, PageIteratorOptions{})
+    defer it.Close()
+    for it.Next() {
+        l := it.Page().(*ListNotes)
+        resultList = append(resultList, l.Notes...)
+    }
+    if err := it.Err(); err != nil {
+        return nil, fmt.Errorf("Failed retrieving notes for contact with ID %s: %w",
+            contactId, err)
+    }
+
+    // Notes with dates we can't parse keep their place at the end
+    sort.SliceStable(resultList, func(i, j int) bool {
+        ti, err := resultList[i].CreatedTime()
+        if err != nil {
+            return false
+        }
+        tj, err := resultList[j].CreatedTime()
+        if err != nil {
+            return true
+        }
+        return ti.Before(tj)
+    })
+    return resultList, nil
+}
+
+func (c *Client) GetNote(id string) (*Note, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_NOTES, id)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    requestUrl := u.String()
+    r := c.DoApiRequestGet(requestUrl)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed retrieving note with ID %s: %w", id, r.Error)
+    }
+
+    // Unmarshal the message
+    n := &RetrieveNote{}
+    err = json.Unmarshal(r.Data, &n)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return &n.Note, nil
+}
+
+// Replaces the text of a note and returns the updated note
+func (c *Client) UpdateNoteText(id string, note string) (*Note, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_NOTES, id)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data
+    var m UpdateNote
+    m.Note.Note = note
+    data, err := json.Marshal(m)
+    if err != nil {
+        return nil, fmt.Errorf("Failed marshaling update note request data: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestPut(requestUrl, data)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed updating note with ID %s: %w", id, r.Error)
+    }
+
+    // Unmarshal the message
+    n := &RetrieveNote{}
+    err = json.Unmarshal(r.Data, &n)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return &n.Note, nil
+}
+
+func (c *Client) DeleteNote(id string) error {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_NOTES, id)
+    if err != nil {
+        return fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestDelete(requestUrl)
+    if r.Error != nil {
+        return fmt.Errorf("Failed deleting note with ID %s: %w", id, r.Error)
+    }
+    return nil
+}
+
+// Returns the contact's note with exactly the given text (ignoring
+// surrounding whitespace), or nil if they don't have one
+func (c *Client) FindContactNote(contactId string, note string) (*Note, error) {
+    notes, err := c.GetContactNotes(contactId)
+    if err != nil {
+        return nil, err
+    }
+    note = strings.TrimSpace(note)
+    for i, n := range notes {
+        if strings.TrimSpace(n.Note) == note {
+            return &notes[i], nil
+        }
+    }
+    return nil, nil
+}
+
+// Adds the note unless the contact already has one with the same text, so
+// retried webhooks don't write it twice. Returns true if it was added.
+func (c *Client) AddNoteToContactOnce(contactId string, note string) (bool, error) {
+    existing, err := c.FindContactNote(contactId, note)
+    if err != nil {
+        return false, err
+    }
+    if existing != nil {
+        return false, nil
+    }
+    err = c.AddNoteToContact(contactId, note)
+    if err != nil {
+        return false, err
+    }
+    return true, nil
+}
+
+/*
+ * Messages and unmarshalers
+ */
+// A note on a contact (or deal). UserId is the AC user that wrote it, which
+// is the API token's user for notes added by webhooks.
+type Note struct {
+    Id              string      `json:"id"`
+    Note            string      `json:"note"`
+    RelativeId      string      `json:"relid"`
+    RelativeType    string      `json:"reltype"`
+    UserId          string      `json:"userid"`
+    CreationDate    string      `json:"cdate"`
+    ModifiedDate    string      `json:"mdate"`
+}
+
+func (n *Note) CreatedTime() (time.Time, error) {
+    return parseNoteTime(n.CreationDate)
+}
+
+func (n *Note) ModifiedTime() (time.Time, error) {
+    return parseNoteTime(n.ModifiedDate)
+}
+
+func parseNoteTime(s string) (time.Time, error) {
+    var err error
+    for _, layout := range noteTimeLayouts {
+        var t time.Time
+        t, err = time.Parse(layout, s)
+        if err == nil {
+            return t, nil
+        }
+    }
+    return time.Time{}, fmt.Errorf("Failed parsing note time '%s': %w", s, err)
+}
+
+// List notes
+type ListNotes struct {
+    Notes       []Note              `json:"notes"`
+    Metadata    ListNotesMetadata   `json:"meta"`
+}
+
+type _ListNotes ListNotes
+
+type ListNotesMetadata struct {
+    TotalRaw  string `json:"total"`
+    Total     uint64
+}
+
+func (l *ListNotes) UnmarshalJSON(jsonStr []byte) error {
+    l2 := _ListNotes{}
+
+    err := json.Unmarshal(jsonStr, &l2)
+    if err != nil {
+        return err
+    }
+
+    l2.Metadata.Total = to.Uint64(l2.Metadata.TotalRaw)
+
+    *l = ListNotes(l2)
+
+    return nil
+}
+
+func (l *ListNotes) totalResults() uint64 {
+    return l.Metadata.Total
+}
+
+func (l *ListNotes) itemCount() int {
+    return len(l.Notes)
+}
+
+func (l *ListNotes) truncateItems(n int) {
+    l.Notes = l.Notes[:n]
+}
+
+// Retrieve or update a note
+type RetrieveNote struct {
+    Note        Note        `json:"note"`
+}
+
+type UpdateNote struct {
+    Note        UpdateNoteNote  `json:"note"`
+}
+
+type UpdateNoteNote struct {
+    Note        string      `json:"note"`
+}
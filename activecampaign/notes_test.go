@@ -0,0 +1,111 @@
+package activecampaign_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func TestNotes(t *testing.T) {
+	posts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/3/contacts/1/notes":
+			fmt.Fprint(w, `{"notes":[`+
+				`{"id":"7","note":"Webhook updated contact","relid":"1","reltype":"Subscriber",`+
+				`"userid":"2","cdate":"2020-05-01T10:00:00-05:00","mdate":"2020-05-02T10:00:00-05:00"}]}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/api/3/notes/7":
+			body, _ := ioutil.ReadAll(r.Body)
+			var m ac.UpdateNote
+			json.Unmarshal(body, &m)
+			fmt.Fprintf(w, `{"note":{"id":"7","note":%q,"userid":"2"}}`, m.Note.Note)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/3/notes/7":
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/3/notes":
+			posts++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	notes, err := client.GetContactNotes("1")
+	if err != nil {
+		t.Fatalf("GetContactNotes() returned error: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Id != "7" || notes[0].UserId != "2" {
+		t.Fatalf("GetContactNotes() == %+v", notes)
+	}
+	created, err := notes[0].CreatedTime()
+	want := time.Date(2020, 5, 1, 15, 0, 0, 0, time.UTC)
+	if err != nil || !created.Equal(want) {
+		t.Errorf("CreatedTime() == (%v, %v), want %v", created, err, want)
+	}
+
+	n, err := client.UpdateNoteText("7", "Edited")
+	if err != nil || n.Note != "Edited" {
+		t.Errorf("UpdateNoteText() == (%+v, %v), want note text 'Edited'", n, err)
+	}
+	if err := client.DeleteNote("7"); err != nil {
+		t.Errorf("DeleteNote() returned error: %v", err)
+	}
+
+	cases := []struct {
+		note      string
+		wantAdded bool
+	}{
+		{"Webhook updated contact", false},
+		{"  Webhook updated contact\n", false},
+		{"Something new", true},
+	}
+	for _, c := range cases {
+		added, err := client.AddNoteToContactOnce("1", c.note)
+		if err != nil || added != c.wantAdded {
+			t.Errorf("AddNoteToContactOnce(%q) == (%t, %v), want %t", c.note, added, err, c.wantAdded)
+		}
+	}
+	if posts != 1 {
+		t.Errorf("AddNoteToContactOnce() posted %d notes, want 1", posts)
+	}
+}
+
+func TestGetContactNotesPages(t *testing.T) {
+	// 150 notes over two pages, newest first
+	total := 150
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var l []string
+		for i := offset; i < offset+limit && i < total; i++ {
+			created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, total-i)
+			l = append(l, fmt.Sprintf(`{"id":"%d","cdate":%q}`, i, created.Format(time.RFC3339)))
+		}
+		fmt.Fprintf(w, `{"notes":[%s],"meta":{"total":"%d"}}`, strings.Join(l, ","), total)
+	}))
+	defer ts.Close()
+	client := ac.NewClient(ts.URL, "test-token")
+
+	notes, err := client.GetContactNotes("1")
+	if err != nil {
+		t.Fatalf("GetContactNotes() returned error: %v", err)
+	}
+	if len(notes) != total {
+		t.Fatalf("GetContactNotes() returned %d notes, want %d", len(notes), total)
+	}
+	if notes[0].Id != "149" || notes[total-1].Id != "0" {
+		t.Errorf("GetContactNotes() == [%s ... %s], want oldest (149) first", notes[0].Id,
+			notes[total-1].Id)
+	}
+}
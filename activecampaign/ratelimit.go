@@ -0,0 +1,190 @@
+package activecampaign
+
+import (
+    "context"
+    "errors"
+    "math/rand"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// AC allows 5 requests per second per account. Requests over the limit get a
+// 429, so every request made through a Client waits on its token bucket and
+// is retried with backoff when the API pushes back.
+const (
+    DEFAULT_RATE_LIMIT = 5 // requests per second
+    DEFAULT_RATE_BURST = 5
+    DEFAULT_MAX_ATTEMPTS = 5
+    DEFAULT_RETRY_BACKOFF = 500 * time.Millisecond
+    MAX_RETRY_BACKOFF = 30 * time.Second
+    MAX_RETRY_AFTER = 60 * time.Second // longest we'll wait when the API says to
+)
+
+// A token bucket shared by all goroutines using the same client
+type tokenBucket struct {
+    mutex       sync.Mutex
+    rate        float64 // tokens added per second
+    burst       float64
+    tokens      float64
+    last        time.Time
+    holdUntil   time.Time // set when the API asks us to back off
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+    if burst < 1 {
+        burst = 1
+    }
+    return &tokenBucket{
+        rate: rate,
+        burst: float64(burst),
+        tokens: float64(burst),
+        last: time.Now(),
+    }
+}
+
+// Blocks until a token is available or the context is done
+func (b *tokenBucket) Wait(ctx context.Context) error {
+    for {
+        b.mutex.Lock()
+        now := time.Now()
+        if now.Before(b.holdUntil) {
+            wait := b.holdUntil.Sub(now)
+            b.mutex.Unlock()
+            if err := sleepContext(ctx, wait); err != nil {
+                return err
+            }
+            continue
+        }
+
+        // Refill
+        b.tokens += now.Sub(b.last).Seconds() * b.rate
+        if b.tokens > b.burst {
+            b.tokens = b.burst
+        }
+        b.last = now
+
+        if b.tokens >= 1 {
+            b.tokens--
+            b.mutex.Unlock()
+            return nil
+        }
+        wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+        b.mutex.Unlock()
+        if err := sleepContext(ctx, wait); err != nil {
+            return err
+        }
+    }
+}
+
+// Sleeps for the duration, returning early with the context's error if it's
+// done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+    t := time.NewTimer(d)
+    defer t.Stop()
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-t.C:
+        return nil
+    }
+}
+
+// Stops handing out tokens for the given duration, so a Retry-After from
+// one request slows down all of them
+func (b *tokenBucket) Hold(d time.Duration) {
+    b.mutex.Lock()
+    defer b.mutex.Unlock()
+    until := time.Now().Add(d)
+    if until.After(b.holdUntil) {
+        b.holdUntil = until
+    }
+    b.tokens = 0
+}
+
+// Returns the client's rate limiter, or nil if rate limiting is disabled
+func (c *Client) rateLimiter() *tokenBucket {
+    if c.RateLimit <= 0 {
+        return nil
+    }
+    c.limiterOnce.Do(func() {
+        burst := c.RateBurst
+        if burst < 1 {
+            burst = DEFAULT_RATE_BURST
+        }
+        c.limiter = newTokenBucket(c.RateLimit, burst)
+    })
+    return c.limiter
+}
+
+func (c *Client) maxAttempts() int {
+    if c.MaxAttempts < 1 {
+        return 1
+    }
+    return c.MaxAttempts
+}
+
+// Exponential backoff with jitter: a random duration between half and all
+// of base*2^(attempt-1), capped at MAX_RETRY_BACKOFF
+func (c *Client) retryBackoff(attempt int) time.Duration {
+    base := c.RetryBackoff
+    if base <= 0 {
+        base = DEFAULT_RETRY_BACKOFF
+    }
+    d := base
+    for i := 1; i < attempt && d < MAX_RETRY_BACKOFF; i++ {
+        d *= 2
+    }
+    if d > MAX_RETRY_BACKOFF {
+        d = MAX_RETRY_BACKOFF
+    }
+    half := d / 2
+    return half + time.Duration(rand.Int63n(int64(half) + 1))
+}
+
+// Whether a response status is worth retrying. POSTs aren't idempotent (the
+// server may have created the note, tag, deal etc. before failing), so only
+// 429s are retried for them.
+func isRetryableStatus(method string, statusCode int) bool {
+    if method == http.MethodPost {
+        return statusCode == http.StatusTooManyRequests
+    }
+    return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// Whether a failed attempt is worth retrying. POSTs are only retried if the
+// request never made it to the server.
+func isRetryableError(method string, err error) bool {
+    var te *TransportError
+    if method == http.MethodPost {
+        return errors.As(err, &te) && !te.Sent
+    }
+    return true
+}
+
+// Parses a Retry-After header given in seconds or as an HTTP date, capped at
+// MAX_RETRY_AFTER. Returns false if the header is missing or invalid.
+func parseRetryAfter(value string) (time.Duration, bool) {
+    if value == "" {
+        return 0, false
+    }
+    var d time.Duration
+    if seconds, err := strconv.Atoi(value); err == nil {
+        if seconds < 0 {
+            return 0, false
+        }
+        d = time.Duration(seconds) * time.Second
+    } else if t, err := http.ParseTime(value); err == nil {
+        d = time.Until(t)
+        if d < 0 {
+            d = 0
+        }
+    } else {
+        return 0, false
+    }
+    if d > MAX_RETRY_AFTER {
+        d = MAX_RETRY_AFTER
+    }
+    return d, true
+}
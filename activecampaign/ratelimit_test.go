@@ -0,0 +1,127 @@
+package activecampaign_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func TestDoApiRequestRetries(t *testing.T) {
+	cases := []struct {
+		name         string
+		method       string
+		failures     int // responses with failStatus before a success
+		failStatus   int // 0 to drop the connection instead
+		retryAfter   string
+		maxAttempts  int
+		wantRequests int
+		wantError    bool
+	}{
+		{"no failures", http.MethodGet, 0, 0, "", 3, 1, false},
+		{"rate limited once", http.MethodGet, 1, http.StatusTooManyRequests, "0", 3, 2, false},
+		{"server errors then success", http.MethodGet, 2, http.StatusBadGateway, "", 3, 3, false},
+		{"server errors past max attempts", http.MethodGet, 5, http.StatusInternalServerError, "", 3, 3, true},
+		{"not found is not retried", http.MethodGet, 5, http.StatusNotFound, "", 3, 1, true},
+		{"dropped connection", http.MethodGet, 1, 0, "", 3, 2, false},
+		{"post rate limited once", http.MethodPost, 1, http.StatusTooManyRequests, "0", 3, 2, false},
+		{"post server error is not retried", http.MethodPost, 1, http.StatusBadGateway, "", 3, 1, true},
+		{"post dropped connection is not retried", http.MethodPost, 1, 0, "", 3, 1, true},
+	}
+	for _, c := range cases {
+		requests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests <= c.failures {
+				if c.failStatus == 0 {
+					conn, _, _ := w.(http.Hijacker).Hijack()
+					conn.Close()
+					return
+				}
+				if c.retryAfter != "" {
+					w.Header().Set("Retry-After", c.retryAfter)
+				}
+				w.WriteHeader(c.failStatus)
+				return
+			}
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			}
+			fmt.Fprint(w, `{}`)
+		}))
+		client := ac.NewClient(ts.URL, "test-token")
+		client.MaxAttempts = c.maxAttempts
+		client.RetryBackoff = time.Millisecond
+		var r *ac.ApiRequestResult
+		if c.method == http.MethodPost {
+			r = client.DoApiRequestPost(client.ApiUrl, []byte(`{}`))
+		} else {
+			r = client.DoApiRequestGet(client.ApiUrl)
+		}
+		ts.Close()
+
+		gotError := r.Error != nil
+		if gotError != c.wantError || requests != c.wantRequests {
+			t.Errorf("%s: %s == (requests=%d, error=%t), want (requests=%d, error=%t), got err: %v",
+				c.name, c.method, requests, gotError, c.wantRequests, c.wantError, r.Error)
+		}
+	}
+}
+
+// Fails the first request without sending it
+type unsentTransport struct {
+	failures int
+}
+
+func (u *unsentTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if u.failures > 0 {
+		u.failures--
+		return nil, errors.New("dial tcp: connection refused")
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestDoApiRequestRetriesUnsentPost(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	client := ac.NewClient(ts.URL, "test-token")
+	client.HttpClient = &http.Client{Transport: &unsentTransport{failures: 1}}
+	client.RetryBackoff = time.Millisecond
+	r := client.DoApiRequestPost(client.ApiUrl, []byte(`{}`))
+	if r.Error != nil || requests != 1 {
+		t.Errorf("DoApiRequestPost() == (requests=%d, error=%v), want (requests=1, error=nil)",
+			requests, r.Error)
+	}
+}
+
+func TestDoApiRequestRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	client := ac.NewClient(ts.URL, "test-token")
+	client.RateLimit = 20
+	client.RateBurst = 1
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		r := client.DoApiRequestGet(client.ApiUrl)
+		if r.Error != nil {
+			t.Fatalf("DoApiRequestGet() failed: %s", r.Error)
+		}
+	}
+	// One request from the burst, then four more at 50ms each
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("5 requests at 20/s with a burst of 1 took %v, want at least 200ms", elapsed)
+	}
+}
@@ -0,0 +1,259 @@
+package activecampaign
+
+import (
+    "encoding/json"
+    "fmt"
+    "log"
+)
+
+// Returns the contactTag associations (not the tags themselves) for a contact
+func (c *Client) GetContactTagAssociations(contactId string) ([]ListContactTagsTag, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACTS, contactId, API_URL_CONTACT_TAGS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    requestUrl := u.String()
+    r := c.DoApiRequestGet(requestUrl)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed retrieving list of contact tags" +
+            " for contact with ID %s: %w", contactId, r.Error)
+    }
+
+    // Unmarshal the message metedata
+    l := &ListContactTags{}
+    err = json.Unmarshal(r.Data, &l)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return l.ContactTags, nil
+}
+
+// Returns the contactTag association for the given tag ID, or nil if the
+// contact doesn't have the tag.
+func (c *Client) getContactTag(contactId string, tagId string) (*ListContactTagsTag, error) {
+    l, err := c.GetContactTagAssociations(contactId)
+    if err != nil {
+        return nil, err
+    }
+    for _, v := range l {
+        if v.Tag == tagId {
+            return &v, nil
+        }
+    }
+    return nil, nil
+}
+
+// Creates a new contact tag with the given name
+func (c *Client) CreateTagWithName(name string, description string) (*RetrieveTag, error) {
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_TAGS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data
+    var m CreateTag
+    m.Tag.Tag = name
+    m.Tag.TagType = "contact"
+    m.Tag.Description = description
+    data, err := json.Marshal(m)
+    if err != nil {
+        return nil, fmt.Errorf("Failed marshaling create tag request data: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestPost(requestUrl, data)
+    if r.Error != nil {
+        return nil, fmt.Errorf("Failed creating tag '%s': %w", name, r.Error)
+    }
+
+    // Unmarshal the message metedata
+    t2 := &RetrieveTagContainer{}
+    err = json.Unmarshal(r.Data, &t2)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    t := t2.Tag
+    c.InvalidateCache(CACHE_TAGS)
+
+    return &t, nil
+}
+
+// Resolves a tag name to its ID, optionally creating the tag when it
+// doesn't exist. Returns an empty ID if the tag doesn't exist and wasn't
+// created.
+func (c *Client) getTagIdByName(tag string, createMissing bool) (string, error) {
+    t, _, err := c.findTagByName(tag)
+    if err != nil {
+        return "", err
+    }
+    if t != nil {
+        return t.Id, nil
+    }
+    if !createMissing {
+        return "", nil
+    }
+
+    if DEBUG {
+        log.Printf("Creating missing tag: %s", tag)
+    }
+    t2, err := c.CreateTagWithName(tag, "")
+    if err != nil {
+        return "", err
+    }
+    return t2.Id, nil
+}
+
+// Applies the tag to the contact. Returns true if the tag was added, or
+// false if the contact already had it.
+func (c *Client) AddTagToContact(contactId string, tagId string) (bool, error) {
+    existing, err := c.getContactTag(contactId, tagId)
+    if err != nil {
+        return false, err
+    }
+    if existing != nil {
+        if DEBUG {
+            log.Printf("Contact %s already has tag %s", contactId, tagId)
+        }
+        return false, nil
+    }
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACT_TAGS)
+    if err != nil {
+        return false, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Build request data
+    var m CreateContactTag
+    m.ContactTag.Contact = contactId
+    m.ContactTag.Tag = tagId
+    data, err := json.Marshal(m)
+    if err != nil {
+        return false, fmt.Errorf("Failed marshaling create contact tag request data: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestPost(requestUrl, data)
+    if r.Error != nil {
+        return false, fmt.Errorf("Failed adding tag %s to contact with ID %s: %w",
+            tagId, contactId, r.Error)
+    }
+    return true, nil
+}
+
+// Applies the named tag to the contact, creating the tag first if it doesn't
+// exist and createMissing is set. Returns true if the tag was added.
+func (c *Client) AddTagToContactByName(contactId string, tag string, createMissing bool) (bool, error) {
+    tagId, err := c.getTagIdByName(tag, createMissing)
+    if err != nil {
+        return false, fmt.Errorf("Failed finding tag '%s': %w", tag, err)
+    }
+    if tagId == "" {
+        return false, newNotFoundError("Could not find any tags with name: %s", tag)
+    }
+    return c.AddTagToContact(contactId, tagId)
+}
+
+// Removes the tag from the contact. Returns true if the tag was removed, or
+// false if the contact didn't have it.
+func (c *Client) RemoveTagFromContact(contactId string, tagId string) (bool, error) {
+    existing, err := c.getContactTag(contactId, tagId)
+    if err != nil {
+        return false, err
+    }
+    if existing == nil {
+        if DEBUG {
+            log.Printf("Contact %s does not have tag %s", contactId, tagId)
+        }
+        return false, nil
+    }
+
+    // Build request URL
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_CONTACT_TAGS, existing.Id)
+    if err != nil {
+        return false, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    // Send request
+    requestUrl := u.String()
+    r := c.DoApiRequestDelete(requestUrl)
+    if r.Error != nil {
+        return false, fmt.Errorf("Failed removing tag %s from contact with ID %s: %w",
+            tagId, contactId, r.Error)
+    }
+    return true, nil
+}
+
+// Removes the named tag from the contact. A tag that doesn't exist is
+// treated the same as one the contact doesn't have.
+func (c *Client) RemoveTagFromContactByName(contactId string, tag string) (bool, error) {
+    tagId, err := c.getTagIdByName(tag, false)
+    if err != nil {
+        return false, fmt.Errorf("Failed finding tag '%s': %w", tag, err)
+    }
+    if tagId == "" {
+        return false, nil
+    }
+    return c.RemoveTagFromContact(contactId, tagId)
+}
+
+// Applies each of the named tags to the contact. Returns the number of tags
+// that were actually added.
+func (c *Client) AddTagsToContact(contactId string, tags []string, createMissing bool) (int, error) {
+    count := 0
+    for _, t := range tags {
+        added, err := c.AddTagToContactByName(contactId, t, createMissing)
+        if err != nil {
+            return count, err
+        }
+        if added {
+            count++
+        }
+    }
+    return count, nil
+}
+
+// Removes each of the named tags from the contact. Returns the number of tags
+// that were actually removed.
+func (c *Client) RemoveTagsFromContact(contactId string, tags []string) (int, error) {
+    count := 0
+    for _, t := range tags {
+        removed, err := c.RemoveTagFromContactByName(contactId, t)
+        if err != nil {
+            return count, err
+        }
+        if removed {
+            count++
+        }
+    }
+    return count, nil
+}
+
+/*
+ * Messages and unmarshalers
+ */
+// Create a tag
+type CreateTag struct {
+    Tag         CreateTagTag    `json:"tag"`
+}
+
+type CreateTagTag struct {
+    Tag         string      `json:"tag"`
+    TagType     string      `json:"tagType"`
+    Description string      `json:"description"`
+}
+
+// Add a tag to a contact
+type CreateContactTag struct {
+    ContactTag  CreateContactTagContactTag  `json:"contactTag"`
+}
+
+type CreateContactTagContactTag struct {
+    Contact     string      `json:"contact"`
+    Tag         string      `json:"tag"`
+}
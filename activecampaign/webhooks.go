@@ -0,0 +1,326 @@
+package activecampaign
+
+import (
+    "bytes"
+    "crypto/subtle"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/url"
+    "regexp"
+    "sort"
+    "strings"
+
+    "github.com/xiam/to"
+)
+
+// AC posts webhooks as form-urlencoded bodies with nested keys, e.g.
+// contact[email] or contact[fields][rid]. The webhook server may also hand
+// them to us as a flat JSON object of those same keys, so ParseWebhook()
+// accepts either.
+const (
+    WEBHOOK_TYPE_SUBSCRIBE = "subscribe"
+    WEBHOOK_TYPE_UNSUBSCRIBE = "unsubscribe"
+    WEBHOOK_TYPE_CONTACT_UPDATE = "update"
+    WEBHOOK_TYPE_TAG_ADDED = "contact_tag_added"
+    WEBHOOK_TYPE_TAG_REMOVED = "contact_tag_removed"
+    WEBHOOK_TYPE_DEAL_UPDATE = "deal_update"
+    // Webhook actions in automations don't send a type, so we use this one
+    WEBHOOK_TYPE_AUTOMATION = "automation"
+)
+
+var ErrWebhookSecretMismatch = errors.New("Webhook secret does not match")
+
+var RegexWebhookContactField = regexp.MustCompile(`^contact\[fields\]\[(.+)\]$`)
+var RegexWebhookUpdatedField = regexp.MustCompile(`^updated_fields\[(\d*)\]$`)
+
+// Every parsed webhook implements this
+type WebhookPayload interface {
+    Common() *WebhookCommon
+}
+
+// Fields sent with every webhook
+type WebhookCommon struct {
+    Type            string
+    DateTime        string // date_time
+    InitiatedFrom   string // initiated_from, e.g. admin, api, public, system
+    InitiatedBy     string // initiated_by, e.g. admin, api, public, system
+    Secret          string // our own ?secret= added to the webhook URL, see ParseWebhookWithQuery()
+    Contact         WebhookContact
+    Values          url.Values // everything that was posted
+}
+
+func (w *WebhookCommon) Common() *WebhookCommon {
+    return w
+}
+
+// Checks the secret sent with the webhook against the expected one. An empty
+// expected secret means no check.
+func (w *WebhookCommon) CheckSecret(expected string) error {
+    if expected == "" {
+        return nil
+    }
+    if subtle.ConstantTimeCompare([]byte(w.Secret), []byte(expected)) != 1 {
+        return ErrWebhookSecretMismatch
+    }
+    return nil
+}
+
+type WebhookContact struct {
+    Id          string
+    Email       string
+    FirstName   string
+    LastName    string
+    Phone       string
+    Ip          string
+    OrgName     string
+    Tags        []string
+    Fields      map[string]string // custom field values by perstag (lowercase)
+}
+
+type SubscribeWebhook struct {
+    WebhookCommon
+    ListId      string
+    FormId      string
+}
+
+type UnsubscribeWebhook struct {
+    WebhookCommon
+    ListId      string
+    CampaignId  string
+    Reason      string
+}
+
+type ContactUpdateWebhook struct {
+    WebhookCommon
+    UpdatedFields   []string
+}
+
+// For both tag added and removed
+type ContactTagWebhook struct {
+    WebhookCommon
+    Tag         string
+    Added       bool
+}
+
+type DealUpdateWebhook struct {
+    WebhookCommon
+    Deal            WebhookDeal
+    UpdatedFields   []string
+}
+
+type WebhookDeal struct {
+    Id              string
+    Title           string
+    CreatedDate     string
+    Status          string
+    Value           string
+    ValueRaw        string
+    Currency        string
+    PipelineId      string
+    PipelineTitle   string
+    StageId         string
+    StageTitle      string
+    Owner           string
+    OwnerFirstName  string
+    OwnerLastName   string
+    ContactId       string
+    OrgId           string
+    OrgName         string
+}
+
+type AutomationWebhook struct {
+    WebhookCommon
+}
+
+// Parses a webhook post body (form-urlencoded or flat JSON) into one of the
+// *Webhook types above, based on its type field.
+func ParseWebhook(data []byte) (WebhookPayload, error) {
+    values, err := decodeWebhookValues(data)
+    if err != nil {
+        return nil, err
+    }
+    return ParseWebhookValues(values)
+}
+
+// Same as ParseWebhook(), also taking the secret from the hook URL's query
+// (a query string or flat JSON object, as passed by the webhook server). AC
+// doesn't copy the query into the post body.
+func ParseWebhookWithQuery(data []byte, query string) (WebhookPayload, error) {
+    p, err := ParseWebhook(data)
+    if err != nil {
+        return nil, err
+    }
+    query = strings.TrimPrefix(strings.TrimSpace(query), "?")
+    if query == "" {
+        return p, nil
+    }
+    q, err := decodeWebhookValues([]byte(query))
+    if err != nil {
+        return nil, fmt.Errorf("Failed parsing webhook query: %w", err)
+    }
+    p.Common().Secret = q.Get("secret")
+    return p, nil
+}
+
+func ParseWebhookValues(v url.Values) (WebhookPayload, error) {
+    common := WebhookCommon{
+        Type: v.Get("type"),
+        DateTime: v.Get("date_time"),
+        InitiatedFrom: v.Get("initiated_from"),
+        InitiatedBy: v.Get("initiated_by"),
+        Contact: parseWebhookContact(v),
+        Values: v,
+    }
+
+    switch common.Type {
+    case WEBHOOK_TYPE_SUBSCRIBE:
+        return &SubscribeWebhook{
+            WebhookCommon: common,
+            ListId: v.Get("list"),
+            FormId: v.Get("form[id]"),
+        }, nil
+    case WEBHOOK_TYPE_UNSUBSCRIBE:
+        return &UnsubscribeWebhook{
+            WebhookCommon: common,
+            ListId: v.Get("list"),
+            CampaignId: v.Get("campaign[id]"),
+            Reason: v.Get("unsubscribe[reason]"),
+        }, nil
+    case WEBHOOK_TYPE_CONTACT_UPDATE:
+        return &ContactUpdateWebhook{
+            WebhookCommon: common,
+            UpdatedFields: parseWebhookUpdatedFields(v),
+        }, nil
+    case WEBHOOK_TYPE_TAG_ADDED, WEBHOOK_TYPE_TAG_REMOVED:
+        return &ContactTagWebhook{
+            WebhookCommon: common,
+            Tag: v.Get("tag"),
+            Added: common.Type == WEBHOOK_TYPE_TAG_ADDED,
+        }, nil
+    case WEBHOOK_TYPE_DEAL_UPDATE:
+        return &DealUpdateWebhook{
+            WebhookCommon: common,
+            Deal: parseWebhookDeal(v),
+            UpdatedFields: parseWebhookUpdatedFields(v),
+        }, nil
+    case "", WEBHOOK_TYPE_AUTOMATION:
+        if common.Contact.Id == "" && common.Contact.Email == "" {
+            return nil, fmt.Errorf("Webhook has no type and no contact")
+        }
+        common.Type = WEBHOOK_TYPE_AUTOMATION
+        return &AutomationWebhook{WebhookCommon: common}, nil
+    }
+    return nil, fmt.Errorf("Unsupported webhook type: %s", common.Type)
+}
+
+func decodeWebhookValues(data []byte) (url.Values, error) {
+    data = bytes.TrimSpace(data)
+    if len(data) < 1 {
+        return nil, fmt.Errorf("Empty webhook payload")
+    }
+    if data[0] != '{' {
+        v, err := url.ParseQuery(string(data))
+        if err != nil {
+            return nil, fmt.Errorf("Failed parsing webhook form data: %w", err)
+        }
+        return v, nil
+    }
+
+    m := make(map[string]interface{})
+    err := json.Unmarshal(data, &m)
+    if err != nil {
+        return nil, fmt.Errorf("Failed parsing webhook JSON data: %w", err)
+    }
+    v := url.Values{}
+    for key, value := range m {
+        switch t := value.(type) {
+        case []interface{}:
+            for _, e := range t {
+                v.Add(key, to.String(e))
+            }
+        case nil:
+            v.Set(key, "")
+        default:
+            v.Set(key, to.String(t))
+        }
+    }
+    return v, nil
+}
+
+func parseWebhookContact(v url.Values) WebhookContact {
+    c := WebhookContact{
+        Id: v.Get("contact[id]"),
+        Email: v.Get("contact[email]"),
+        FirstName: v.Get("contact[first_name]"),
+        LastName: v.Get("contact[last_name]"),
+        Phone: v.Get("contact[phone]"),
+        Ip: v.Get("contact[ip]"),
+        OrgName: v.Get("contact[orgname]"),
+        Fields: make(map[string]string),
+    }
+    for _, t := range strings.Split(v.Get("contact[tags]"), ",") {
+        t = strings.TrimSpace(t)
+        if t != "" {
+            c.Tags = append(c.Tags, t)
+        }
+    }
+    for key := range v {
+        m := RegexWebhookContactField.FindStringSubmatch(key)
+        if m != nil {
+            c.Fields[strings.ToLower(m[1])] = v.Get(key)
+        }
+    }
+    return c
+}
+
+func parseWebhookDeal(v url.Values) WebhookDeal {
+    return WebhookDeal{
+        Id: v.Get("deal[id]"),
+        Title: v.Get("deal[title]"),
+        CreatedDate: v.Get("deal[create_date]"),
+        Status: v.Get("deal[status]"),
+        Value: v.Get("deal[value]"),
+        ValueRaw: v.Get("deal[value_raw]"),
+        Currency: v.Get("deal[currency]"),
+        PipelineId: v.Get("deal[pipelineid]"),
+        PipelineTitle: v.Get("deal[pipeline_title]"),
+        StageId: v.Get("deal[stageid]"),
+        StageTitle: v.Get("deal[stage_title]"),
+        Owner: v.Get("deal[owner]"),
+        OwnerFirstName: v.Get("deal[owner_firstname]"),
+        OwnerLastName: v.Get("deal[owner_lastname]"),
+        ContactId: v.Get("deal[contactid]"),
+        OrgId: v.Get("deal[orgid]"),
+        OrgName: v.Get("deal[orgname]"),
+    }
+}
+
+// Updated fields come as updated_fields[0], updated_fields[1], ... or
+// repeated updated_fields[] keys. They're returned in index order, followed
+// by any without an index.
+func parseWebhookUpdatedFields(v url.Values) []string {
+    type indexedField struct {
+        index   int
+        values  []string
+    }
+    var indexed []indexedField
+    var unindexed []string
+    for key, values := range v {
+        m := RegexWebhookUpdatedField.FindStringSubmatch(key)
+        if m == nil {
+            continue
+        }
+        if m[1] == "" {
+            unindexed = append(unindexed, values...)
+            continue
+        }
+        indexed = append(indexed, indexedField{to.Int(m[1]), values})
+    }
+    sort.Slice(indexed, func(i, j int) bool { return indexed[i].index < indexed[j].index })
+    var l []string
+    for _, f := range indexed {
+        l = append(l, f.values...)
+    }
+    return append(l, unindexed...)
+}
@@ -0,0 +1,168 @@
+package activecampaign_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+func TestParseWebhook(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		wantType string
+		check    func(t *testing.T, p ac.WebhookPayload)
+	}{
+		{
+			"unsubscribe form",
+			"type=unsubscribe&date_time=2020-05-01T10%3A00%3A00-05%3A00&initiated_by=public&initiated_from=public" +
+				"&list=3&campaign%5Bid%5D=42&unsubscribe%5Breason%5D=Too+many+emails" +
+				"&contact%5Bid%5D=123&contact%5Bemail%5D=jane%40example.com&contact%5Bfirst_name%5D=Jane" +
+				"&contact%5Btags%5D=SJ_Student%2C+Rainmaker&contact%5Bfields%5D%5BRID%5D=987",
+			ac.WEBHOOK_TYPE_UNSUBSCRIBE,
+			func(t *testing.T, p ac.WebhookPayload) {
+				w := p.(*ac.UnsubscribeWebhook)
+				if w.ListId != "3" || w.CampaignId != "42" || w.Reason != "Too many emails" {
+					t.Errorf("got list %q campaign %q reason %q", w.ListId, w.CampaignId, w.Reason)
+				}
+				if w.InitiatedBy != "public" || w.InitiatedFrom != "public" {
+					t.Errorf("got initiated by %q from %q", w.InitiatedBy, w.InitiatedFrom)
+				}
+				if w.Contact.Id != "123" || w.Contact.Email != "jane@example.com" || w.Contact.FirstName != "Jane" {
+					t.Errorf("got contact %+v", w.Contact)
+				}
+				if !reflect.DeepEqual(w.Contact.Tags, []string{"SJ_Student", "Rainmaker"}) {
+					t.Errorf("got tags %v", w.Contact.Tags)
+				}
+				if w.Contact.Fields["rid"] != "987" {
+					t.Errorf("got fields %v", w.Contact.Fields)
+				}
+			},
+		},
+		{
+			"subscribe flat json",
+			`{"type":"subscribe","list":"5","form[id]":"7","initiated_from":"api","contact[id]":"9","contact[email]":"a@b.com"}`,
+			ac.WEBHOOK_TYPE_SUBSCRIBE,
+			func(t *testing.T, p ac.WebhookPayload) {
+				w := p.(*ac.SubscribeWebhook)
+				if w.ListId != "5" || w.FormId != "7" || w.Contact.Id != "9" || w.InitiatedFrom != "api" {
+					t.Errorf("got %+v", w)
+				}
+			},
+		},
+		{
+			"contact update",
+			"type=update&contact%5Bid%5D=1&updated_fields%5B1%5D=first_name&updated_fields%5B0%5D=email",
+			ac.WEBHOOK_TYPE_CONTACT_UPDATE,
+			func(t *testing.T, p ac.WebhookPayload) {
+				w := p.(*ac.ContactUpdateWebhook)
+				if !reflect.DeepEqual(w.UpdatedFields, []string{"email", "first_name"}) {
+					t.Errorf("got updated fields %v", w.UpdatedFields)
+				}
+			},
+		},
+		{
+			"contact update with unindexed fields",
+			"type=update&contact%5Bid%5D=1&updated_fields%5B%5D=phone&updated_fields%5B10%5D=last_name" +
+				"&updated_fields%5B2%5D=first_name&updated_fields%5B0%5D=email",
+			ac.WEBHOOK_TYPE_CONTACT_UPDATE,
+			func(t *testing.T, p ac.WebhookPayload) {
+				w := p.(*ac.ContactUpdateWebhook)
+				want := []string{"email", "first_name", "last_name", "phone"}
+				if !reflect.DeepEqual(w.UpdatedFields, want) {
+					t.Errorf("got updated fields %v, want %v", w.UpdatedFields, want)
+				}
+			},
+		},
+		{
+			"tag removed",
+			"type=contact_tag_removed&tag=SJ_Student&contact%5Bid%5D=1",
+			ac.WEBHOOK_TYPE_TAG_REMOVED,
+			func(t *testing.T, p ac.WebhookPayload) {
+				w := p.(*ac.ContactTagWebhook)
+				if w.Tag != "SJ_Student" || w.Added {
+					t.Errorf("got tag %q added %v", w.Tag, w.Added)
+				}
+			},
+		},
+		{
+			"deal update",
+			"type=deal_update&deal%5Bid%5D=11&deal%5Btitle%5D=Course&deal%5Bstageid%5D=2&deal%5Bvalue%5D=997.00",
+			ac.WEBHOOK_TYPE_DEAL_UPDATE,
+			func(t *testing.T, p ac.WebhookPayload) {
+				w := p.(*ac.DealUpdateWebhook)
+				if w.Deal.Id != "11" || w.Deal.Title != "Course" || w.Deal.StageId != "2" || w.Deal.Value != "997.00" {
+					t.Errorf("got deal %+v", w.Deal)
+				}
+			},
+		},
+		{
+			"automation action",
+			"contact%5Bid%5D=1&contact%5Bemail%5D=a%40b.com&initiated_from=automation",
+			ac.WEBHOOK_TYPE_AUTOMATION,
+			func(t *testing.T, p ac.WebhookPayload) {
+				if _, ok := p.(*ac.AutomationWebhook); !ok {
+					t.Errorf("got %T", p)
+				}
+			},
+		},
+	}
+	for _, c := range cases {
+		p, err := ac.ParseWebhook([]byte(c.data))
+		if err != nil {
+			t.Errorf("%s: ParseWebhook() returned error: %v", c.name, err)
+			continue
+		}
+		if p.Common().Type != c.wantType {
+			t.Errorf("%s: got type %q, want %q", c.name, p.Common().Type, c.wantType)
+			continue
+		}
+		c.check(t, p)
+	}
+}
+
+func TestParseWebhookErrors(t *testing.T) {
+	for _, data := range []string{"", "type=account_add", "{not json", "date_time=today"} {
+		if _, err := ac.ParseWebhook([]byte(data)); err == nil {
+			t.Errorf("ParseWebhook(%q) returned no error", data)
+		}
+	}
+}
+
+func TestWebhookCheckSecret(t *testing.T) {
+	// AC doesn't post the hook URL's query, so a secret in the body is ignored
+	p, err := ac.ParseWebhookWithQuery([]byte("type=subscribe&secret=wrong&contact%5Bid%5D=1"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Common().CheckSecret("s3cret"); !errors.Is(err, ac.ErrWebhookSecretMismatch) {
+		t.Errorf("CheckSecret() with only a body secret == %v, want ErrWebhookSecretMismatch", err)
+	}
+
+	for _, query := range []string{"secret=s3cret", "?secret=s3cret", `{"secret":"s3cret"}`} {
+		p, err := ac.ParseWebhookWithQuery([]byte("type=subscribe&contact%5Bid%5D=1"), query)
+		if err != nil {
+			t.Fatalf("ParseWebhookWithQuery(%q) returned error: %v", query, err)
+		}
+		if err := p.Common().CheckSecret("s3cret"); err != nil {
+			t.Errorf("ParseWebhookWithQuery(%q): CheckSecret(right) == %v, want nil", query, err)
+		}
+	}
+
+	p, err = ac.ParseWebhookWithQuery([]byte("type=subscribe&contact%5Bid%5D=1"), "secret=s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := p.Common()
+	if err := w.CheckSecret(""); err != nil {
+		t.Errorf("CheckSecret(\"\") == %v, want nil", err)
+	}
+	if err := w.CheckSecret("s3cret"); err != nil {
+		t.Errorf("CheckSecret(right) == %v, want nil", err)
+	}
+	if err := w.CheckSecret("wrong"); !errors.Is(err, ac.ErrWebhookSecretMismatch) {
+		t.Errorf("CheckSecret(wrong) == %v, want ErrWebhookSecretMismatch", err)
+	}
+}
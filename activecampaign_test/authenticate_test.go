@@ -1,17 +1,32 @@
 package activecampaign_test
 
 import (
-	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
 	"testing"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign/actest"
 )
 
 func TestAuthenticateWithCredentials(t *testing.T) {
-	apiUrl, apiToken := activecampaign.GetApiCredentials()
+	s := actest.NewServer()
+	defer s.Close()
+	activecampaign.SetDefaultClient(s.Client())
+	defer activecampaign.SetDefaultClient(nil)
 
-	err := activecampaign.AuthenticateWithCredentials(apiUrl, apiToken)
-
-	if err != nil {
-		t.Errorf("AuthenticateWithCredentials() == (error), expected no error but got: %s",
-			err)
+	apiUrl, apiToken := activecampaign.GetApiCredentials()
+	cases := []struct {
+		tokenIn   string
+		wantError bool
+	}{
+		{apiToken, false},
+		{"wrong-token", true},
+	}
+	for _, c := range cases {
+		err := activecampaign.AuthenticateWithCredentials(apiUrl, c.tokenIn)
+		gotError := err != nil
+		if gotError != c.wantError {
+			t.Errorf("AuthenticateWithCredentials(%q) == (error=%t), want (error=%t), got err: %v",
+				c.tokenIn, gotError, c.wantError, err)
+		}
 	}
 }
@@ -1,47 +1,63 @@
 package activecampaign_test
 
 import (
-	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
-	//"fmt"
 	"testing"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign/actest"
 )
 
 func TestGetContactsByEmail(t *testing.T) {
+	s := newContactServer(t)
+	defer s.Close()
+	defer activecampaign.SetDefaultClient(nil)
+
 	cases := []struct {
 		in           string
 		wantContacts bool
 		wantError    bool
 	}{
-		{"davie.goodman.music@gmail.com", false, false},
-		{"davie.goodman.music+tester@gmail.com", true, false},
-		{"davie.goodman.music", false, true},
-		{"brelix@gmail.com", false, false},
-		{"brelix+tester@gmail.com", true, false},
-		{"dagoodma@gmail.com", false, false},
+		{"tester@example.com", true, false},
+		{"TESTER@example.com", true, false},
+		{"nobody@example.com", false, true},
+		{"tester", false, true},
 		{"1", false, true},
-		{"", false, true},
 	}
 	for _, c := range cases {
-		r, err := activecampaign.GetContactsByEmail(c.in)
-		var gotContactList = r != nil
-		var gotError = err != nil
-		var gotContacts = r != nil && r.Metadata.Total > 0
-		if gotError && !c.wantError || !gotError && c.wantError || !gotContactList && !gotError {
-			t.Errorf("GetContactsByEmail(%q) == (contactList=%t, error=%t), want (contactList=%t, error=%t)",
-				c.in, gotContactList, gotError, c.wantContacts, c.wantError)
-		} else if gotContacts && !c.wantContacts {
-			t.Errorf("GetContactsByEmail(%q) == (contacts count=%d, error=%t), expected no contacts, but got some",
-				c.in, r.Metadata.Total, gotError)
-		} else if !gotContactList && c.wantContacts {
-			t.Errorf("GetContactsByEmail(%q) == (contacts=%t, error=%t), expected contacts but got none",
-				c.in, gotContacts, gotError)
-		} else if gotError && c.wantError {
-			// Do nothing, but don't proceed into else
-		} else {
-			if r.Metadata.Total < 1 && c.wantContacts {
-				t.Errorf("GetContactsByEmail(%q) returned no contacts, expected at least 1 contact",
-					c.in, r.Metadata.Total)
-			}
+		r, err := activecampaign.GetContacts(activecampaign.QueryParameters{Email: c.in})
+		gotError := err != nil
+		if gotError != c.wantError {
+			t.Errorf("GetContacts(email=%q) == (error=%t), want (error=%t), got err: %v",
+				c.in, gotError, c.wantError, err)
+			continue
+		}
+		if gotError {
+			continue
 		}
+		gotContacts := r.Metadata.Total > 0 && len(r.Contacts) > 0
+		if gotContacts != c.wantContacts {
+			t.Errorf("GetContacts(email=%q) returned %d contacts, want contacts=%t",
+				c.in, r.Metadata.Total, c.wantContacts)
+		}
+	}
+}
+
+// Starts a fake AC server with two contacts and makes it the default client.
+// Close the server and reset the default client when done.
+func newContactServer(t *testing.T) *actest.Server {
+	s := actest.NewServer()
+	err := s.Seed(actest.Fixtures{
+		Contacts: []actest.ContactFixture{
+			{Id: "123", Email: "tester@example.com"},
+			{Id: "124", Email: "other@example.com"},
+		},
+	})
+	if err != nil {
+		s.Close()
+		t.Fatalf("Seed() returned error: %v", err)
 	}
+	c := s.Client()
+	c.AccountId = "nancyhillis"
+	activecampaign.SetDefaultClient(c)
+	return s
 }
@@ -1,14 +1,36 @@
 package activecampaign_test
 
 import (
-	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
 )
 
 var ApiUrlPrefix = "https://"
 
 func TestGetApiCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ac_secrets.yml")
+	err = ioutil.WriteFile(path, []byte("ACCOUNT_ID: nancyhillis\n"+
+		"API_URL: https://nancyhillis.api-us1.com\nAPI_TOKEN: test-token\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := activecampaign.NewClientFromSecrets(path)
+	if err != nil {
+		t.Fatalf("NewClientFromSecrets() returned error: %v", err)
+	}
+	activecampaign.SetDefaultClient(c)
+	defer activecampaign.SetDefaultClient(nil)
+
 	apiUrl, apiToken := activecampaign.GetApiCredentials()
 	if len(apiUrl) < 1 {
 		t.Errorf("GetApiCredentials() == (empty string, _), want non-empty string for api url")
@@ -17,7 +39,7 @@ func TestGetApiCredentials(t *testing.T) {
 		t.Errorf("GetApiCredentials() == (%s, _), want string starting with '%s' for api url",
 			apiUrl, ApiUrlPrefix)
 	}
-	if len(apiToken) < 1 {
-		t.Errorf("GetApiCredentials() == (_, empty string), want non-empty string for api token")
+	if apiToken != "test-token" {
+		t.Errorf("GetApiCredentials() == (_, %q), want %q for api token", apiToken, "test-token")
 	}
 }
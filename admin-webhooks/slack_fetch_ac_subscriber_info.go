@@ -20,6 +20,9 @@ var RespondToMessageInChannel = true
 
 var Debug = false // supress extra messages if false
 
+// How many of the most recent notes to show
+var MaxNotesShown = 5
+
 // Note that we will be using our own customer error handler: HandleError()
 func main() {
 	argsWithProg := os.Args
@@ -94,6 +97,18 @@ func CreateSubscriberInfoAttachment(contact *activecampaign.ListContactsContact)
 		"Profile URL: %s\n",
 		name, contact.Email, contact.Id, contact.CreationDate, contact.UpdatedUtcTimestamp,
 		url)
+
+	// Recent history from notes, newest first
+	notes, err := activecampaign.GetContactNotes(contact.Id)
+	if err != nil {
+		return "", fmt.Errorf("Failed fetching AC subscriber notes: %s", err)
+	}
+	if len(notes) > 0 {
+		r += fmt.Sprintf("Notes (%d):\n", len(notes))
+	}
+	for i := len(notes) - 1; i >= 0 && i >= len(notes)-MaxNotesShown; i-- {
+		r += fmt.Sprintf("- %s: %s\n", notes[i].CreationDate, notes[i].Note)
+	}
 	return r, nil
 }
 func HandleError(w *util.WebhookEvent, format string, args ...interface{}) {
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"bitbucket.org/dagoodma/dagoodma-go/stripewrap"
+	teachable "bitbucket.org/dagoodma/nancyhillis-go/teachable"
+	flag "github.com/spf13/pflag"
+)
+
+var Debug = false // supress extra messages if false
+
+// Format of the date arguments
+var DateLayout = "2006-01-02"
+
+func myUsage() {
+	fmt.Printf("Usage: %s [OPTIONS] AFTER_DATE [BEFORE_DATE]\n", os.Args[0])
+	fmt.Printf("Compare each student's Teachable revenue with their Stripe charges between the\n"+
+		"given dates (%s). Before defaults to now.\n\n", DateLayout)
+	flag.PrintDefaults()
+}
+
+// Net revenue for one student, in cents
+type StudentRevenue struct {
+	Email     string
+	Teachable int64
+	Stripe    int64
+	ChargeIds map[string]bool // Stripe charges of the student's transactions
+	Error     error
+}
+
+func main() {
+	var verbose int
+	var productId uint64
+	var showAll bool
+
+	flag.CountVarP(&verbose, "verbose", "v", "Print output with increasing verbosity")
+	flag.Uint64VarP(&productId, "product", "p", 0, "Only reconcile sales of this Teachable product ID")
+	flag.BoolVarP(&showAll, "all", "a", false, "Print every student, not just mismatches")
+
+	flag.Usage = myUsage
+	flag.Parse()
+	args := flag.Args()
+	Debug = verbose > 0
+
+	if len(args) < 1 {
+		log.Fatal("No date range provided")
+	}
+	q := teachable.SalesQuery{ProductId: productId, Before: time.Now()}
+	var err error
+	q.After, err = time.Parse(DateLayout, args[0])
+	if err != nil {
+		log.Fatalf("Invalid after date '%s': %v", args[0], err)
+	}
+	if len(args) > 1 {
+		q.Before, err = time.Parse(DateLayout, args[1])
+		if err != nil {
+			log.Fatalf("Invalid before date '%s': %v", args[1], err)
+		}
+	}
+
+	teachable.SecretsFilePath = "teachable_secrets.yml"
+	start := time.Now()
+
+	tc, err := teachable.DefaultClient()
+	if err != nil {
+		log.Fatalf("Failed loading Teachable client: %v", err)
+	}
+	// Payment plan sales made before the range can still have payments in
+	// it, so fetch everything sold before the end and check each payment's
+	// own date
+	salesQuery := q
+	salesQuery.After = time.Time{}
+	allSales, err := tc.GetSales(context.Background(), salesQuery)
+	if err != nil {
+		log.Fatalf("Failed fetching Teachable sales: %v", err)
+	}
+	sales, undated := FilterSalesByPaymentDate(allSales, q.After, q.Before)
+	if Debug {
+		log.Printf("Found %d of %d sales in Teachable with payments between %s and %s", len(sales),
+			len(allSales), q.After.Format(DateLayout), q.Before.Format(DateLayout))
+	}
+
+	// Total up Teachable revenue by student
+	revenueByEmail := make(map[string]*StudentRevenue)
+	getRevenue := func(email string) *StudentRevenue {
+		r, ok := revenueByEmail[email]
+		if !ok {
+			r = &StudentRevenue{Email: email, ChargeIds: make(map[string]bool)}
+			revenueByEmail[email] = r
+		}
+		return r
+	}
+	for _, s := range sales {
+		r := getRevenue(strings.ToLower(s.User.Email))
+		for _, t := range s.Transactions {
+			r.Teachable += t.NetAmount()
+			if t.ChargeId != "" {
+				r.ChargeIds[t.ChargeId] = true
+			}
+		}
+	}
+	for email, err := range undated {
+		getRevenue(email).Error = err
+	}
+
+	// Then the same for their Stripe charges
+	var emails []string
+	for email, r := range revenueByEmail {
+		emails = append(emails, email)
+		if r.Error != nil {
+			continue
+		}
+		r.Stripe, r.Error = GetStripeRevenue(email, r.ChargeIds)
+	}
+	sort.Strings(emails)
+
+	mismatches := 0
+	var teachableTotal, stripeTotal int64
+	for _, email := range emails {
+		r := revenueByEmail[email]
+		teachableTotal += r.Teachable
+		stripeTotal += r.Stripe
+		if r.Error != nil {
+			mismatches += 1
+			fmt.Printf("%s: teachable=%s, stripe=error (%v)\n", email, FormatCents(r.Teachable), r.Error)
+			continue
+		}
+		if r.Teachable != r.Stripe {
+			mismatches += 1
+		} else if !showAll {
+			continue
+		}
+		fmt.Printf("%s: teachable=%s, stripe=%s\n", email, FormatCents(r.Teachable),
+			FormatCents(r.Stripe))
+	}
+
+	for _, s := range teachable.SummarizeRevenue(sales) {
+		fmt.Printf("Teachable %s: %d sales, %d payments, %d refunds, gross=%s, refunded=%s, net=%s\n",
+			s.Currency, s.SaleCount, s.PaymentCount, s.RefundCount, FormatCents(s.Gross),
+			FormatCents(s.Refunded), FormatCents(s.Net))
+	}
+	fmt.Printf("Reconciled %d students: teachable=%s, stripe=%s, %d mismatched\n", len(emails),
+		FormatCents(teachableTotal), FormatCents(stripeTotal), mismatches)
+	log.Printf("Finished in: %v", time.Since(start))
+}
+
+// Returns when the transaction was paid, falling back to when it was created
+func TransactionTime(t teachable.Transaction) (time.Time, error) {
+	s := t.PurchasedAt
+	if s == "" {
+		s = t.CreatedAt
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// Returns the sales with only their transactions paid in [after, before),
+// dropping any sales left without one. Transactions without a valid date are
+// reported by student email instead.
+func FilterSalesByPaymentDate(sales []teachable.RetrieveSale, after time.Time,
+	before time.Time) ([]teachable.RetrieveSale, map[string]error) {
+	var l []teachable.RetrieveSale
+	undated := make(map[string]error)
+	for _, s := range sales {
+		var transactions []teachable.Transaction
+		for _, t := range s.Transactions {
+			paid, err := TransactionTime(t)
+			if err != nil {
+				undated[strings.ToLower(s.User.Email)] = fmt.Errorf("Transaction %d has no valid date: %v",
+					t.Id, err)
+				continue
+			}
+			if paid.Before(after) || !paid.Before(before) {
+				continue
+			}
+			transactions = append(transactions, t)
+		}
+		if len(transactions) < 1 {
+			continue
+		}
+		s.Transactions = transactions
+		l = append(l, s)
+	}
+	return l, undated
+}
+
+// Returns the student's paid Stripe charges less refunds, in cents. Only the
+// given charges are counted, so payments for other products don't show up as
+// mismatches.
+func GetStripeRevenue(email string, chargeIds map[string]bool) (int64, error) {
+	if len(chargeIds) < 1 {
+		return 0, nil
+	}
+	c, err := stripewrap.GetCustomerByEmail(email)
+	if err != nil || c == nil {
+		return 0, fmt.Errorf("Could not find student in Stripe: %v", err)
+	}
+	l := stripewrap.GetChargeList(c.ID)
+	if l == nil {
+		return 0, nil
+	}
+	var total int64
+	for l.Next() {
+		c2 := l.Charge()
+		if !c2.Paid || !chargeIds[c2.ID] {
+			continue
+		}
+		total += c2.Amount - c2.AmountRefunded
+	}
+	return total, nil
+}
+
+func FormatCents(cents int64) string {
+	return fmt.Sprintf("$%.2f", float64(cents)/100.00)
+}
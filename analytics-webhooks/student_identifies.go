@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
 	"bitbucket.org/dagoodma/nancyhillis-go/util"
 )
 
@@ -24,11 +26,6 @@ type InputData struct {
 	Device      string `json:"device"`
 }
 
-type OutputData struct {
-	Status       string `json:"status"`
-	GlobalUserId string `json:"uid"` // For GA user ID
-}
-
 // Valid website names
 var ValidSiteNames = []string{
 	"rainmaker",
@@ -41,13 +38,16 @@ var SiteIdFieldName = map[string]string{
 	"teachable": "tid",
 }
 
+// Custom field name in AC for GA client ID
+var ClientIdFieldName = "cid"
+
 // Note that we will be using our own customer error handler: HandleError()
 func main() {
 	argsWithProg := os.Args
 	if len(argsWithProg) < 3 {
 		//log.Fatalf("Not enough arguments, expected %d given: %d",
 		//	1, len(argsWithProg))
-		HandleError("No input data provided")
+		HandleError(nil, "No input data provided")
 		return
 	}
 
@@ -60,11 +60,17 @@ func main() {
 		util.RecordWebhookStarted(w)
 	}
 
+	// Local secrets?
+	if _, err := os.Stat("ac_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local AC secrets.")
+		ac.SecretsFilePath = "ac_secrets.yml"
+	}
+
 	// Unmarshal the input data
 	m := InputData{}
 	err := json.Unmarshal(data, &m)
 	if err != nil {
-		HandleError("Error while parsing input data for '%s'. %v", data, err)
+		HandleError(w, "Error while parsing input data for '%s'. %v", data, err)
 		return
 	}
 
@@ -97,52 +103,60 @@ func main() {
 		HandleError(w, "No site user ID provided")
 		return
 	}
-	// GA Client ID
-	id := m.Id
-	if len(id) < 1 {
-		HandleError(w, "No site user ID provided")
-		return
-	}
+	// GA Client ID (optional)
+	clientId := m.ClientId
 
-	// Find Stripe customer ID in spreadsheet
-	stripeId, err := nancyhillis.GetSjStripeIdByEmail(email)
+	// Create or update the contact in AC
+	fields := map[string]string{
+		SiteIdFieldName[siteName]: userId,
+	}
+	if len(clientId) > 0 {
+		fields[ClientIdFieldName] = clientId
+	}
+	d := ac.ContactDetails{
+		Email:     email,
+		FirstName: m.FirstName,
+		LastName:  m.LastName,
+		Fields:    fields,
+	}
+	c, created, err := ac.CreateOrUpdateContact(d)
 	if err != nil {
-		// Check if they're a founder who never migrated
-		if IsFounderNeverMigrated(email) {
-			HandleError("Your account is still in our old billing system and still needs to me moved over")
-		} else {
-			HandleError("%v", err)
-		}
+		HandleError(w, "Failed to create or update AC contact for '%s': %v", email, err)
 		return
 	}
+	if Debug {
+		action := "Updated"
+		if created {
+			action = "Created"
+		}
+		log.Printf("%s AC contact '%s' (%s) from %s site user %s", action, email,
+			c.Id, siteName, userId)
+	}
 
 	// Return result
 	r := make(map[string]interface{})
-	r["result"] = stripeId
+	r["status"] = "success"
+	r["uid"] = c.Id // for the GA user ID
 	util.PrintJsonObject(r)
 	return
 }
 
-// Check if this person is in membermouse and never migrated
-func IsFounderNeverMigrated(email string) bool {
-	// Find founder by email in membermouse
-	m, err := membermouse.GetMemberByEmail(email)
-	if err != nil {
-		return false
-	}
-
-	isMigrated := m.IsMigrated()
-	if isMigrated {
-		return false
+func StringSliceContains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
 	}
-
-	return true
+	return false
 }
 
-func HandleError(format string, args ...interface{}) {
+func HandleError(w *util.WebhookEvent, format string, args ...interface{}) {
 	message := fmt.Sprintf(format, args...)
 	util.PrintJsonError(message)
 	if Debug {
 		log.Printf(message)
 	}
+	if w != nil {
+		util.ReportWebhookFailure(w, message)
+	}
 }
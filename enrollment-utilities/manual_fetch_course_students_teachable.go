@@ -10,7 +10,6 @@ import (
     "strings"
 	flag "github.com/spf13/pflag"
 	teachable "bitbucket.org/dagoodma/nancyhillis-go/teachable"
-    "regexp"
 )
 
 
@@ -18,7 +17,11 @@ var Debug = false // supress extra messages if false
 
 func myUsage() {
      fmt.Printf("Usage: %s [OPTIONS] <COURSE_ACRONYM>\n", os.Args[0])
-     fmt.Printf("Lists all students enrolled in a given course in Teachable.\n\n")
+     fmt.Printf("Lists all students enrolled in a given course in Teachable.\n")
+     if catalog, err := teachable.GetCourseCatalog(); err == nil {
+         fmt.Printf("Possible course acronyms are:\n%s", catalog.Usage())
+     }
+     fmt.Printf("\n")
      flag.PrintDefaults()
 }
 
@@ -26,6 +29,9 @@ func main() {
 	var verbose int
 	var dryRun, exactMatch bool
 
+    teachable.SecretsFilePath = "teachable_secrets.yml"
+    teachable.CourseCatalogFilePath = "course_catalog.yml"
+
 	flag.CountVarP(&verbose, "verbose", "v", "Print output with increasing verbosity")
 	flag.BoolVarP(&dryRun, "dry-run", "d", false, "To Be Implemented")
 	flag.BoolVarP(&exactMatch, "exact-match", "e", false, "To Be Implemented") //"Tag name must match exactly, otherwise it can be a substring")
@@ -38,16 +44,13 @@ func main() {
         log.Fatal("No course acronym")
         return
     }
-    var courseAcronymRegex = regexp.MustCompile(`^(TAJC|TAJM|EWC|SJC|SJM|ATC|LYS|BUNDLE_TAJC-EWC|TAP_CHALLENGE|TAPCIP)$`)
     courseAcronym := strings.ToUpper(string(args[0]))
 
-    if courseAcronymRegex.FindString(courseAcronym) == "" {
+    if !teachable.IsValidCourseAcronym(courseAcronym) {
         log.Fatal("Expected valid course acronym but got: ", courseAcronym)
         return
     }
 
-    teachable.SecretsFilePath = "teachable_secrets.yml"
-
     //
     start := time.Now()
     log.Println("Fetching courses in Teachable...")
@@ -39,6 +39,7 @@ func main() {
     var regexId = regexp.MustCompile(`^\d+$`)
 
     teachable.SecretsFilePath = "teachable_secrets.yml"
+    teachable.CourseCatalogFilePath = "course_catalog.yml"
     start := time.Now()
 
     if regexId.FindString(id) != "" {
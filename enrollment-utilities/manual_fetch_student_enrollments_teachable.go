@@ -42,6 +42,7 @@ func main() {
     var regexId = regexp.MustCompile(`^\d+$`)
 
     teachable.SecretsFilePath = "teachable_secrets.yml"
+    teachable.CourseCatalogFilePath = "course_catalog.yml"
     start := time.Now()
 
     var err error
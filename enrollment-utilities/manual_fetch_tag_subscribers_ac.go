@@ -22,10 +22,13 @@ func myUsage() {
 func main() {
 	var verbose int
 	var dryRun, exactMatch bool
+	var updatedDays, createdDays int
 
 	flag.CountVarP(&verbose, "verbose", "v", "Print output with increasing verbosity")
 	flag.BoolVarP(&dryRun, "dry-run", "d", false, "To Be Implemented")
 	flag.BoolVarP(&exactMatch, "exact-match", "e", false, "To Be Implemented") //"Tag name must match exactly, otherwise it can be a substring")
+	flag.IntVarP(&updatedDays, "updated-days", "u", 0, "Only contacts updated in the last number of days")
+	flag.IntVarP(&createdDays, "created-days", "c", 0, "Only contacts created in the last number of days")
 
 	flag.Parse()
 	args := flag.Args()
@@ -39,7 +42,14 @@ func main() {
 
     ac.SecretsFilePath = "ac_secrets.yml"
     start := time.Now()
-    allContacts, err := ac.GetContactsByTag(tagName)
+    var p ac.QueryParameters
+    if updatedDays > 0 {
+        p.UpdatedAfter = start.AddDate(0, 0, -updatedDays)
+    }
+    if createdDays > 0 {
+        p.CreatedAfter = start.AddDate(0, 0, -createdDays)
+    }
+    allContacts, err := ac.SearchContactsByTag(tagName, p)
     if err != nil {
         log.Printf("Error retrieving contact. %v\n", err)
         return
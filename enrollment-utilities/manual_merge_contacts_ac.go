@@ -0,0 +1,73 @@
+package main
+
+import (
+    "os"
+	"log"
+	"fmt"
+	flag "github.com/spf13/pflag"
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+
+var Debug = false // supress extra messages if false
+
+func myUsage() {
+     fmt.Printf("Usage: %s [OPTIONS] [OLD_EMAIL] [NEW_EMAIL] \n", os.Args[0])
+     fmt.Printf("Merge the ActiveCampaign contact with OLD_EMAIL into the one with NEW_EMAIL," +
+        " copying tags, lists, custom fields, and notes.\n\n")
+     flag.PrintDefaults()
+}
+
+func main() {
+	var dryRun bool
+	var deleteOld bool
+	var flagOld bool
+
+	flag.BoolVarP(&dryRun, "dry-run", "d", false, "Only print the changes that would be made")
+	flag.BoolVar(&deleteOld, "delete-old", false, "Delete the old contact after merging")
+	flag.BoolVar(&flagOld, "flag-old", false, "Tag the old contact as a merged duplicate")
+
+    flag.Usage = myUsage
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) < 2 {
+        log.Fatal("Expected an old and new email")
+        return
+	}
+    oldEmail := string(args[0])
+    newEmail := string(args[1])
+
+    options := ac.MergeOptions{DryRun: dryRun}
+    if deleteOld {
+        options.OldContact = ac.MERGE_DELETE_OLD_CONTACT
+    } else if flagOld {
+        options.OldContact = ac.MERGE_FLAG_OLD_CONTACT
+    }
+
+    ac.SecretsFilePath = "ac_secrets.yml"
+    c1, err := ac.GetContactByEmail(oldEmail)
+    if err != nil {
+        log.Printf("Error retrieving old contact. %v\n", err)
+        return
+    }
+    c2, err := ac.GetContactByEmail(newEmail)
+    if err != nil {
+        log.Printf("Error retrieving new contact. %v\n", err)
+        return
+    }
+
+    plan, err := ac.MergeContacts(c1.Id, c2.Id, options)
+    if plan != nil {
+        fmt.Println(plan)
+    }
+    if err != nil {
+        log.Printf("Error merging contacts. %v\n", err)
+        return
+    }
+    if dryRun {
+        log.Printf("Dry run, not merging contacts.")
+        return
+    }
+    log.Printf("Merged contact '%s' (%s) into '%s' (%s).", oldEmail, c1.Id, newEmail, c2.Id)
+}
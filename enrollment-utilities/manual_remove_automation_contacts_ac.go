@@ -0,0 +1,78 @@
+package main
+
+import (
+    "os"
+	"log"
+	"fmt"
+    "time"
+	flag "github.com/spf13/pflag"
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+)
+
+
+var Debug = false // supress extra messages if false
+
+func myUsage() {
+     fmt.Printf("Usage: %s [OPTIONS] [NAME] \n", os.Args[0])
+     fmt.Printf("Remove all ActiveCampaign contacts currently in the automation with the given NAME" +
+        " (e.g. SJC_PaymentFailed).\n\n")
+     flag.PrintDefaults()
+}
+
+func main() {
+	var verbose int
+	var dryRun bool
+
+	flag.CountVarP(&verbose, "verbose", "v", "Print output with increasing verbosity")
+	flag.BoolVarP(&dryRun, "dry-run", "d", false, "Only print the contacts that would be removed")
+
+    flag.Usage = myUsage
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) < 1 {
+        log.Fatal("No automation name provided")
+        return
+	}
+    automationName := string(args[0])
+
+    ac.SecretsFilePath = "ac_secrets.yml"
+    start := time.Now()
+    a, err := ac.GetAutomationByName(automationName)
+    if err != nil {
+        log.Printf("Error retrieving automation. %v\n", err)
+        return
+    }
+    contacts, err := ac.GetAutomationContacts(a)
+    if err != nil {
+        log.Printf("Error retrieving automation contacts. %v\n", err)
+        return
+    }
+
+    // Only those still in the automation
+    var contactsScheduled []ac.ListContactAutomationsContact
+    for _, c := range contacts {
+        if !c.IsCompleted {
+            contactsScheduled = append(contactsScheduled, c)
+        }
+    }
+    log.Printf("Found %d contacts currently in automation '%s' (%s).",
+        len(contactsScheduled), a.Name, a.Id)
+
+    if dryRun || verbose > 0 {
+        l := ac.GetAutomationContactList(contactsScheduled)
+        fmt.Println(&l)
+    }
+    if dryRun {
+        log.Printf("Dry run, not removing any contacts.")
+        return
+    }
+
+    count, err := ac.RemoveAutomationContacts(contactsScheduled)
+    if err != nil {
+        log.Printf("Error removing automation contacts. %v\n", err)
+    }
+    duration := time.Since(start)
+    log.Printf("Removed %d of %d contacts from automation '%s' in: %v",
+        count, len(contactsScheduled), a.Name, duration)
+}
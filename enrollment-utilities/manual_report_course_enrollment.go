@@ -3,12 +3,12 @@ package main
 import (
     "os"
 	"bytes"
+    "context"
     "strings"
 	"log"
 	"fmt"
     "time"
 	flag "github.com/spf13/pflag"
-    "regexp"
     "github.com/xiam/to"
 	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
 	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
@@ -24,44 +24,32 @@ var GoogleSheetSleepTime, _ = time.ParseDuration("0.5s")
 
 var AddHyperlinksToReport = true
 
-// Automations they must be in (one of) if they're enrolled
-var AutomationSuffixes = []string{"Enrolled", "YearlyMember", "Renewal_Invitation",
-    "PaymentFailed", "1_Month_Trial", "CollectingTestimonials",
-    "Import_Rainmaker"}
-// Actually, 1_Month_Trial is not a valid automation for Enrolled tag,
-// but we'll simplify and not check for that here.
+// Automations and extra tags for each course are listed in the course
+// catalog (course_catalog.yml)
 
 var TagEnrolledSuffix = "Enrolled"
 var TagRainmakerSuffix = "Enrolled_Rainmaker"
 
-// 1_Month_Trial  for ATC: Students_Rainmaker_Importing
-var ExtraTagSuffixes = []string{"4ExtraMonths", "Cancelled", "FreeAccess",
-    "NeverRenewed_Rainmaker", "Revoked", "TechGlitch", "YearlyMember",
-    "Trial_Convert", "Trial_Dropout", "Enrolled_Trial"}
-
 var ReportFolderId = "1Sw8QyhMuGtHPOrCqun6tBDxY8QT5zjAf"
 
 func myUsage() {
      fmt.Printf("Usage: %s [OPTIONS] <COURSE_ACRONYM> \n", os.Args[0])
      fmt.Printf("Compare course enrollment between Teachable and ActiveCampaign for a given course.\n" +
-                "Possible course acronyms to report on are:\n" +
-                "\tTAJC: The Artist's Journey Course\n" +
-                "\tTAJM: The Artist's Journey Masterclass\n" +
-                "\tEWC: Experimenting With Color\n" +
-                "\tSJC: Studio Journey Course\n" +
-                "\tSJM: Studio Journey Masterclass\n" +
-                "\tATC: Activating The Canvas\n" +
-                "\tLYS: Light Your Creative Studio Like A Pro\n" +
-                "\tBUNDLE_TAJC-EWC: Bundle: The Artist's Journey + Experimenting With Color\n" +
-                "\tTAP_CHALLENGE: The Adjacent Possible: Creativity Challenge\n" +
-                "\tTAPCIP: The Adjacent Possible: Creativity Immersion Program\n" +
-                "\n")
+                "Possible course acronyms to report on are:\n")
+     if catalog, err := teachable.GetCourseCatalog(); err == nil {
+         fmt.Printf("%s", catalog.Usage())
+     }
+     fmt.Printf("\n")
      flag.PrintDefaults()
 }
 
 func main() {
 	var verbose int
-	var dryRun, quiet, skipAutomations, skipExtraTags, excludeValid, includeRainmaker bool
+	var dryRun, quiet, skipAutomations, skipExtraTags, excludeValid, includeRainmaker, fixMissing, enrollMissing bool
+	var refreshCache bool
+
+    teachable.SecretsFilePath = "teachable_secrets.yml"
+    teachable.CourseCatalogFilePath = "course_catalog.yml"
 
 	flag.CountVarP(&verbose, "verbose", "v", "Print output with increasing verbosity")
 	flag.BoolVarP(&dryRun, "dry-run", "d", false, "Print results without creating report spreadsheet")
@@ -70,6 +58,9 @@ func main() {
 	flag.BoolVarP(&skipExtraTags, "skip-extra-tags", "k", false, "Don't include extra AC tags info in the list")
 	flag.BoolVarP(&excludeValid, "exclude-valid", "x", false, "Don't include users who are enrolled in Teachable and AC")
 	flag.BoolVarP(&includeRainmaker, "include-rainmaker", "r", false, "Include Rainmaker students in the report")
+	flag.BoolVarP(&fixMissing, "fix-missing", "f", false, "Bulk import Teachable students missing in AC with the enrolled tag")
+	flag.BoolVarP(&enrollMissing, "enroll-missing", "e", false, "Enroll AC students missing in the Teachable course")
+	flag.BoolVarP(&refreshCache, "refresh-cache", "c", false, "Fetch AC tags and automations again instead of using the cache")
 	//flag.BoolVarP(&exactMatch, "exact-match", "e", false, "To Be Implemented")
 
     flag.Usage = myUsage
@@ -80,18 +71,30 @@ func main() {
         log.Fatal("No course acronym")
         return
     }
-    var courseAcronymRegex = regexp.MustCompile(`^(TAJC|TAJM|EWC|SJC|SJM|ATC|LYS|BUNDLE_TAJC-EWC|TAP_CHALLENGE|TAPCIP)$`)
-    courseAcronym := strings.ToUpper(string(args[0]))
-
-    if courseAcronymRegex.FindString(courseAcronym) == "" {
-        log.Fatal("Expected valid course acronym but got: ", courseAcronym)
+    catalog, err := teachable.GetCourseCatalog()
+    if err != nil {
+        log.Fatalf("Failed loading course catalog: %s", err)
         return
     }
+    catalogCourse, err := catalog.Get(string(args[0]))
+    if err != nil {
+        log.Fatal("Expected valid course acronym but got: ", args[0])
+        return
+    }
+    // AC tags and automations are prefixed with this
+    courseAcronym := catalogCourse.TagPrefix
+    automationNames := catalogCourse.AutomationNames()
+    extraTagNames := catalogCourse.ExtraTagNames()
 
-    teachable.SecretsFilePath = "teachable_secrets.yml"
     ac.SecretsFilePath = "ac_secrets.yml"
+    ac.CacheFilePath = "ac_cache.json"
     teachable.DEBUG = false
     ac.DEBUG = false
+    if refreshCache {
+        if err := ac.InvalidateCache(); err != nil {
+            log.Printf("Failed clearing AC cache: %s", err)
+        }
+    }
 
     // TODO change this
     gsheetwrap.SecretsFilePath = "gsheet_client_secrets.json"
@@ -103,7 +106,7 @@ func main() {
     start := time.Now()
     firstStart := time.Now()
     // By <COURSE>_Enrolled tag
-    tagName := courseAcronym + "_" + TagEnrolledSuffix
+    tagName := catalogCourse.TagName(TagEnrolledSuffix)
     if verbose > 0 {
         log.Println("Fetching contacts in ActiveCampaign with tag: ", tagName)
     }
@@ -114,7 +117,7 @@ func main() {
     }
     // By <COURSE>_Enrolled_Rainmaker tag
     var contactsWithRainmakerTag []ac.ListContactsContact
-    rainmakerTagName := courseAcronym + "_" + TagRainmakerSuffix
+    rainmakerTagName := catalogCourse.TagName(TagRainmakerSuffix)
     rainmakerTagStr := ""
     if includeRainmaker {
         rainmakerTagStr = fmt.Sprintf(" and tag '%s'", rainmakerTagName)
@@ -163,12 +166,11 @@ func main() {
     if !skipAutomations {
         if !quiet {
             log.Printf("Fetching all contacts from %d automations in AC...",
-                len(AutomationSuffixes))
+                len(automationNames))
         }
         start = time.Now()
-        for _, v := range AutomationSuffixes {
+        for _, automationName := range automationNames {
             // Get automation from AC
-            automationName := fmt.Sprintf("%s_%s", courseAcronym, v)
             if verbose > 1 {
                 log.Printf("Fetching automation '%s' from AC...", automationName)
             }
@@ -239,7 +241,7 @@ func main() {
         duration = time.Since(start)
         if !quiet {
             log.Printf("Got %d automations for a total %d contacts in AC in: %v",
-                len(AutomationSuffixes), len(contactAutomationsByEmail), duration)
+                len(automationNames), len(contactAutomationsByEmail), duration)
         }
     }
 
@@ -249,13 +251,12 @@ func main() {
     if !skipExtraTags {
         if !quiet {
             log.Printf("Fetching all contacts with %d extra tags in AC...",
-                len(ExtraTagSuffixes))
+                len(extraTagNames))
         }
         start = time.Now()
 
-        for _, v := range ExtraTagSuffixes  {
+        for _, extraTagName := range extraTagNames {
             // Get tag from AC
-            extraTagName := fmt.Sprintf("%s_%s", courseAcronym, v)
             if verbose > 1 {
                 log.Printf("Fetching tag '%s' from AC...", extraTagName)
             }
@@ -292,12 +293,12 @@ func main() {
                 log.Printf("Filtered to %d contacts for tag '%s' from tag list with" +
                 " %d contacts.", extrasCount, extraTagName, len(extraTagContacts))
             }
-        } // for _, v := range ExtraTagSuffixes 
+        } // for _, extraTagName := range extraTagNames 
 
         duration = time.Since(start)
         if !quiet {
             log.Printf("Found %d contacts with %d extra tags in AC in: %v",
-                totalExtrasCount, len(ExtraTagSuffixes), duration)
+                totalExtrasCount, len(extraTagNames), duration)
         }
     } // if !skipExtraTags
 
@@ -540,6 +541,81 @@ func main() {
         }
     } // if !quiet
 
+    // Fix students missing their enrolled tag in AC
+    if fixMissing && len(studentsMissingInAc) > 0 {
+        var contacts []ac.BulkImportContact
+        for _, v := range(studentsMissingInAc) {
+            if !v.IsInTeachable || v.TeachableUser == nil {
+                continue
+            }
+            firstName, lastName := splitName(v.TeachableUser.Name)
+            contacts = append(contacts, ac.BulkImportContact{
+                Email: v.Email,
+                FirstName: firstName,
+                LastName: lastName,
+                Tags: []string{tagName},
+            })
+        }
+        if dryRun {
+            log.Printf("Dry run, not importing %d students with tag '%s' to AC.",
+                len(contacts), tagName)
+        } else {
+            start = time.Now()
+            result, err := ac.BulkImportContacts(context.Background(), contacts,
+                ac.BulkImportOptions{})
+            if err != nil {
+                log.Printf("Failed bulk importing students to AC: %s", err)
+            }
+            if result != nil {
+                log.Printf("Imported %d of %d students with tag '%s' to AC in %d batches in: %v",
+                    len(result.Imported), len(contacts), tagName, len(result.BatchIds),
+                    time.Since(start))
+                for _, f := range result.Failures {
+                    if f.Email == "" {
+                        log.Printf("\tFailed importing contact %s: %s", f.ContactId, f.Reason)
+                    } else {
+                        log.Printf("\tFailed importing %s: %s", f.Email, f.Reason)
+                    }
+                }
+            }
+        }
+    }
+
+    // Enroll students missing in the Teachable course
+    if enrollMissing && len(studentsMissingInTeachable) > 0 {
+        start = time.Now()
+        enrolledCount := 0
+        for _, v := range(studentsMissingInTeachable) {
+            u, err := teachable.GetUserByEmail(v.Email)
+            if err != nil {
+                log.Printf("Skipping enrolling '%s' in %s, not found in Teachable: %s",
+                    v.Email, courseAcronym, err)
+                continue
+            }
+            if dryRun {
+                log.Printf("Dry run, not enrolling '%s' (id=%d) in %s.", v.Email, u.Id,
+                    courseAcronym)
+                continue
+            }
+            enrolled, err := teachable.EnrollUser(u.Id, course.Id)
+            if err != nil {
+                log.Printf("Failed enrolling '%s' (id=%d) in %s: %s", v.Email, u.Id,
+                    courseAcronym, err)
+                continue
+            }
+            if enrolled {
+                enrolledCount += 1
+                v.TeachableUser = u
+                v.IsInTeachable = true
+                v.TeachableProfileUrl = teachable.GetUserProfileUrlById(u.Id)
+            }
+        }
+        if !quiet {
+            log.Printf("Enrolled %d of %d students missing in %s in Teachable in: %v",
+                enrolledCount, len(studentsMissingInTeachable), courseAcronym, time.Since(start))
+        }
+    }
+
     // Create spreadsheet report
     start = time.Now()
 
@@ -762,6 +838,14 @@ func main() {
     }
 }
 
+func splitName(name string) (string, string) {
+    parts := strings.SplitN(strings.TrimSpace(name), " ", 2)
+    if len(parts) < 2 {
+        return parts[0], ""
+    }
+    return parts[0], parts[1]
+}
+
 type CourseStudent struct {
     Email               string
     TeachableUser       *teachable.ListUsersUser
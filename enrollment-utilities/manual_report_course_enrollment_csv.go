@@ -23,39 +23,23 @@ var GoogleSheetSleepTime, _ = time.ParseDuration("0.5s")
 
 var AddHyperlinksToReport = true
 
-// Automations they must be in (one of) if they're enrolled
-var AutomationSuffixes = []string{"Enrolled", "YearlyMember", "Renewal_Invitation",
-    "PaymentFailed", "1_Month_Trial", "CollectingTestimonials",
-    "Import_Rainmaker"}
-// Actually, 1_Month_Trial is not a valid automation for Enrolled tag,
-// but we'll simplify and not check for that here.
+// Automations and extra tags for each course are listed in the course
+// catalog (course_catalog.yml)
 
 var TagEnrolledSuffix = "Enrolled"
 var TagRainmakerSuffix = "Enrolled_Rainmaker"
 
-// 1_Month_Trial  for ATC: Students_Rainmaker_Importing
-var ExtraTagSuffixes = []string{"4ExtraMonths", "Cancelled", "FreeAccess",
-    "NeverRenewed_Rainmaker", "Revoked", "TechGlitch", "YearlyMember",
-    "Trial_Convert", "Trial_Dropout", "Enrolled_Trial"}
-
 var ReportFolderId = "1Sw8QyhMuGtHPOrCqun6tBDxY8QT5zjAf"
 
 func myUsage() {
      fmt.Printf("Usage: %s [OPTIONS] <COURSE_ACRONYM> <COURSE_CSV_FILE> \n", os.Args[0])
      fmt.Printf("Compare course enrollment between Teachable and ActiveCampaign for a" +
                 " given course using a Teachable student record CSV file.\n" +
-                "Possible course acronyms to report on are:\n" +
-                "\tTAJC: The Artist's Journey Course\n" +
-                "\tTAJM: The Artist's Journey Masterclass\n" +
-                "\tEWC: Experimenting With Color\n" +
-                "\tSJC: Studio Journey Course\n" +
-                "\tSJM: Studio Journey Masterclass\n" +
-                "\tATC: Activating The Canvas\n" +
-                "\tLYS: Light Your Creative Studio Like A Pro\n" +
-                "\tBUNDLE_TAJC-EWC: Bundle: The Artist's Journey + Experimenting With Color\n" +
-                "\tTAP_CHALLENGE: The Adjacent Possible: Creativity Challenge\n" +
-                "\tTAPCIP: The Adjacent Possible: Creativity Immersion Program\n" +
-                "\n")
+                "Possible course acronyms to report on are:\n")
+     if catalog, err := teachable.GetCourseCatalog(); err == nil {
+         fmt.Printf("%s", catalog.Usage())
+     }
+     fmt.Printf("\n")
      flag.PrintDefaults()
 }
 
@@ -63,6 +47,9 @@ func main() {
 	var verbose int
 	var dryRun, quiet, skipAutomations, skipExtraTags, excludeValid, includeRainmaker bool
 
+    teachable.SecretsFilePath = "teachable_secrets.yml"
+    teachable.CourseCatalogFilePath = "course_catalog.yml"
+
 	flag.CountVarP(&verbose, "verbose", "v", "Print output with increasing verbosity")
 	flag.BoolVarP(&dryRun, "dry-run", "d", false, "Print results without creating report spreadsheet")
 	flag.BoolVarP(&quiet, "quiet", "q", false, "Don't print any output if possible")
@@ -85,17 +72,24 @@ func main() {
         return
     }
 
-    courseAcronym := strings.ToUpper(string(args[0]))
-    csvFile := string(args[1])
-
-    if !teachable.IsValidCourseAcronym(courseAcronym) {
-        log.Fatal("Expected valid course acronym but got: ", courseAcronym)
+    catalog, err := teachable.GetCourseCatalog()
+    if err != nil {
+        log.Fatalf("Failed loading course catalog: %s", err)
         return
     }
+    catalogCourse, err := catalog.Get(string(args[0]))
+    if err != nil {
+        log.Fatal("Expected valid course acronym but got: ", args[0])
+        return
+    }
+    // AC tags and automations are prefixed with this
+    courseAcronym := catalogCourse.TagPrefix
+    automationNames := catalogCourse.AutomationNames()
+    extraTagNames := catalogCourse.ExtraTagNames()
+    csvFile := string(args[1])
 
 
     // TODO remove these overrides
-    teachable.SecretsFilePath = "teachable_secrets.yml"
     ac.SecretsFilePath = "ac_secrets.yml"
     gsheetwrap.SecretsFilePath = "gsheet_client_secrets.json"
 
@@ -109,7 +103,7 @@ func main() {
     start := time.Now()
     firstStart := time.Now()
     // By <COURSE>_Enrolled tag
-    tagName := courseAcronym + "_" + TagEnrolledSuffix
+    tagName := catalogCourse.TagName(TagEnrolledSuffix)
     if verbose > 0 {
         log.Println("Fetching contacts in ActiveCampaign with tag: ", tagName)
     }
@@ -120,7 +114,7 @@ func main() {
     }
     // By <COURSE>_Enrolled_Rainmaker tag
     var contactsWithRainmakerTag []ac.ListContactsContact
-    rainmakerTagName := courseAcronym + "_" + TagRainmakerSuffix
+    rainmakerTagName := catalogCourse.TagName(TagRainmakerSuffix)
     rainmakerTagStr := ""
     if includeRainmaker {
         rainmakerTagStr = fmt.Sprintf(" and tag '%s'", rainmakerTagName)
@@ -169,12 +163,11 @@ func main() {
     if !skipAutomations {
         if !quiet {
             log.Printf("Fetching all contacts from %d automations in AC...",
-                len(AutomationSuffixes))
+                len(automationNames))
         }
         start = time.Now()
-        for _, v := range AutomationSuffixes {
+        for _, automationName := range automationNames {
             // Get automation from AC
-            automationName := fmt.Sprintf("%s_%s", courseAcronym, v)
             if verbose > 1 {
                 log.Printf("Fetching automation '%s' from AC...", automationName)
             }
@@ -245,7 +238,7 @@ func main() {
         duration = time.Since(start)
         if !quiet {
             log.Printf("Got %d automations for a total %d contacts in AC in: %v",
-                len(AutomationSuffixes), len(contactAutomationsByEmail), duration)
+                len(automationNames), len(contactAutomationsByEmail), duration)
         }
     }
 
@@ -255,13 +248,12 @@ func main() {
     if !skipExtraTags {
         if !quiet {
             log.Printf("Fetching all contacts with %d extra tags in AC...",
-                len(ExtraTagSuffixes))
+                len(extraTagNames))
         }
         start = time.Now()
 
-        for _, v := range ExtraTagSuffixes  {
+        for _, extraTagName := range extraTagNames {
             // Get tag from AC
-            extraTagName := fmt.Sprintf("%s_%s", courseAcronym, v)
             if verbose > 1 {
                 log.Printf("Fetching tag '%s' from AC...", extraTagName)
             }
@@ -298,12 +290,12 @@ func main() {
                 log.Printf("Filtered to %d contacts for tag '%s' from tag list with" +
                 " %d contacts.", extrasCount, extraTagName, len(extraTagContacts))
             }
-        } // for _, v := range ExtraTagSuffixes 
+        } // for _, extraTagName := range extraTagNames 
 
         duration = time.Since(start)
         if !quiet {
             log.Printf("Found %d contacts with %d extra tags in AC in: %v",
-                totalExtrasCount, len(ExtraTagSuffixes), duration)
+                totalExtrasCount, len(extraTagNames), duration)
         }
     } // if !skipExtraTags
 
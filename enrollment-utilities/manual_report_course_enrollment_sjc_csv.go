@@ -24,29 +24,12 @@ var GoogleSheetSleepTime, _ = time.ParseDuration("0.5s")
 
 var AddHyperlinksToReport = true
 
-// Automations they must be in (one of) if they're enrolled
-var AutomationNames = []string{"SJ_Overdue_Billing", "SJC_Renewal_Invitation",
-    "SJC_Enrolled", "SJC_YearlyMember", "SJC_YearlyMember_Legacy",
-    "SJC_Renewal_Invitation", "SJC_PaymentFailed", "SJC_1_Month_Trial",
-    "SJC_CollectingTestimonials", "SJC_Import_Rainmaker"}
-
-// Actually, 1_Month_Trial is not a valid automation for Enrolled tag,
-// but we'll simplify and not check for that here.
+// Automations and extra tags are listed under SJC in the course catalog
+// (course_catalog.yml)
 
 var TagEnrolledSuffix = "Enrolled"
 var TagRainmakerSuffix = "Enrolled_Rainmaker"
 
-// 1_Month_Trial  for ATC: Students_Rainmaker_Importing
-var ExtraTags = []string{"SJC_4ExtraMonths", "SJC_Cancelled", "SJC_FreeAccess",
-    "SJC_FoundingMembers", "SJC_NonFoundingMembers", "SJC_PaymentFailed",
-    "SJC_PaypalPayment", "SJC_Renewal_2019", "SJC_Renewal_April",
-    "SJC_Renewal_G1", "SJC_Revoked", "SJC_TechGlitch", "SJC_YearlyMember",
-    "SJ_Founder", "SJ_Founder_FreeYear", "SJ_Founder_NeedMigrate",
-    "SJ_Overdue", "SJC_Overdue", "SJ_Renewed", "SJ_Revoked",
-    "SJ_Completed", "SJ_Cohort1_March2018", "SJ_Cohort2_April2018",
-    "SJ_Cohort3_June2018", "SJ_Cohort4_July2018", "SJ_Cohort5_August2018",
-    "SJ_Cohort6_December2018"}
-
 var ReportFolderId = "1Sw8QyhMuGtHPOrCqun6tBDxY8QT5zjAf"
 
 func myUsage() {
@@ -63,6 +46,9 @@ func main() {
 	var verbose int
 	var dryRun, quiet, skipAutomations, skipExtraTags, excludeValid, includeRainmaker bool
 
+    teachable.SecretsFilePath = "teachable_secrets.yml"
+    teachable.CourseCatalogFilePath = "course_catalog.yml"
+
 	flag.CountVarP(&verbose, "verbose", "v", "Print output with increasing verbosity")
 	flag.BoolVarP(&dryRun, "dry-run", "d", false, "Print results without creating report spreadsheet")
 	flag.BoolVarP(&quiet, "quiet", "q", false, "Don't print any output if possible")
@@ -84,7 +70,6 @@ func main() {
     csvFile := string(args[0])
 
     // TODO remove these overrides
-    teachable.SecretsFilePath = "teachable_secrets.yml"
     ac.SecretsFilePath = "ac_secrets.yml"
     gsheetwrap.SecretsFilePath = "gsheet_client_secrets.json"
 
@@ -92,6 +77,18 @@ func main() {
     ac.DEBUG = false
 
     courseAcronym := "SJC" // for Teachable
+    catalog, err := teachable.GetCourseCatalog()
+    if err != nil {
+        log.Fatalf("Failed loading course catalog: %s", err)
+        return
+    }
+    catalogCourse, err := catalog.Get(courseAcronym)
+    if err != nil {
+        log.Fatalf("Failed finding %s in course catalog: %s", courseAcronym, err)
+        return
+    }
+    automationNames := catalogCourse.AutomationNames()
+    extraTagNames := catalogCourse.ExtraTagNames()
 
     /******************************************************************
      * Fetching Data
@@ -110,7 +107,7 @@ func main() {
         return
     }
     // By SJC_Enrolled tag
-    tagName := "SJC" + "_" + TagEnrolledSuffix
+    tagName := catalogCourse.TagName(TagEnrolledSuffix)
     if verbose > 0 {
         log.Println("Fetching contacts in ActiveCampaign with tag: ", tagName)
     }
@@ -121,7 +118,7 @@ func main() {
     }
     // By SJC_Enrolled_Rainmaker tag
     var contactsWithRainmakerTag []ac.ListContactsContact
-    rainmakerTagName := "SJC" + "_" + TagRainmakerSuffix
+    rainmakerTagName := catalogCourse.TagName(TagRainmakerSuffix)
     rainmakerTagStr := ""
     if includeRainmaker {
         rainmakerTagStr = fmt.Sprintf(" and tag '%s'", rainmakerTagName)
@@ -176,10 +173,10 @@ func main() {
     if !skipAutomations {
         if !quiet {
             log.Printf("Fetching all contacts from %d automations in AC...",
-                len(AutomationNames))
+                len(automationNames))
         }
         start = time.Now()
-        for _, v := range AutomationNames {
+        for _, v := range automationNames {
             // Get automation from AC
             automationName := v
             if verbose > 1 {
@@ -251,7 +248,7 @@ func main() {
         duration = time.Since(start)
         if !quiet {
             log.Printf("Got %d automations for a total %d contacts in AC in: %v",
-                len(AutomationNames), len(contactAutomationsByEmail), duration)
+                len(automationNames), len(contactAutomationsByEmail), duration)
         }
     }
 
@@ -261,11 +258,11 @@ func main() {
     if !skipExtraTags {
         if !quiet {
             log.Printf("Fetching all contacts with %d extra tags in AC...",
-                len(ExtraTags))
+                len(extraTagNames))
         }
         start = time.Now()
 
-        for _, v := range ExtraTags  {
+        for _, v := range extraTagNames {
             // Get tag from AC
             extraTagName := v
             if verbose > 1 {
@@ -304,12 +301,12 @@ func main() {
                 log.Printf("Filtered to %d contacts for tag '%s' from tag list with" +
                 " %d contacts.", extrasCount, extraTagName, len(extraTagContacts))
             }
-        } // for _, v := range ExtraTags 
+        } // for _, v := range extraTagNames
 
         duration = time.Since(start)
         if !quiet {
             log.Printf("Found %d contacts with %d extra tags in AC in: %v",
-                totalExtrasCount, len(ExtraTags), duration)
+                totalExtrasCount, len(extraTagNames), duration)
         }
     } // if !skipExtraTags
 
@@ -45,6 +45,7 @@ func main() {
     }
 
     teachable.SecretsFilePath = "teachable_secrets.yml"
+    teachable.CourseCatalogFilePath = "course_catalog.yml"
     ac.SecretsFilePath = "ac_secrets.yml"
 
     start := time.Now()
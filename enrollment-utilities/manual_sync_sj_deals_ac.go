@@ -0,0 +1,107 @@
+package main
+
+import (
+    "os"
+	"log"
+	"fmt"
+	flag "github.com/spf13/pflag"
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
+	"bitbucket.org/dagoodma/nancyhillis-go/studiojourney"
+)
+
+
+var Debug = false // supress extra messages if false
+
+// Pipeline (by name) that Studio Journey renewals go in
+var DealPipelineName = "Studio Journey"
+var DealTitle = "Studio Journey renewal"
+
+// Stage (by name) and deal status for each SJ account status
+var DealStages = map[string]string{
+    "active": "Renewing",
+    "past_due": "Overdue",
+    "pending_cancel": "Canceling",
+    "canceled": "Canceled",
+    "complete": "Complete",
+}
+var DealStatuses = map[string]int{
+    "canceled": ac.DEAL_STATUS_LOST,
+    "complete": ac.DEAL_STATUS_WON,
+}
+
+func myUsage() {
+     fmt.Printf("Usage: %s [OPTIONS] [EMAIL]... \n", os.Args[0])
+     fmt.Printf("Create or update a Studio Journey renewal deal in ActiveCampaign for each" +
+        " student EMAIL from their billing status.\n\n")
+     flag.PrintDefaults()
+}
+
+func main() {
+	var dryRun bool
+
+	flag.BoolVarP(&dryRun, "dry-run", "d", false, "Only print the deals that would be made")
+
+    flag.Usage = myUsage
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) < 1 {
+        log.Fatal("No student emails provided")
+        return
+	}
+
+    ac.SecretsFilePath = "ac_secrets.yml"
+    synced := 0
+    for _, email := range args {
+        err := syncDeal(email, dryRun)
+        if err != nil {
+            log.Printf("Error syncing deal for '%s'. %v\n", email, err)
+            continue
+        }
+        synced++
+    }
+    log.Printf("Synced %d of %d deals.", synced, len(args))
+}
+
+func syncDeal(email string, dryRun bool) error {
+    stripeId, err := studiojourney.GetStripeIdByEmail(email)
+    if err != nil {
+        return err
+    }
+    status, err := studiojourney.GetAccountStatus(stripeId)
+    if err != nil {
+        return err
+    }
+    if !status.IsRecurring {
+        log.Printf("Skipping '%s', they don't have a recurring plan.", email)
+        return nil
+    }
+    stage, ok := DealStages[status.Status]
+    if !ok {
+        return fmt.Errorf("No deal stage for status: %s", status.Status)
+    }
+    contact, err := ac.GetContactByEmail(email)
+    if err != nil {
+        return err
+    }
+
+    d := ac.DealDetails{
+        ContactId: contact.Id,
+        Title: DealTitle,
+        Description: fmt.Sprintf("Plan: %s, next bill: %s", status.PlanHuman, status.NextBillHuman),
+        Pipeline: DealPipelineName,
+        Stage: stage,
+        Value: int64(status.RecurringPrice),
+        Status: DealStatuses[status.Status],
+    }
+    if dryRun {
+        fmt.Printf("%s: %+v\n", email, d)
+        return nil
+    }
+    deal, created, err := ac.CreateOrUpdateContactDeal(d)
+    if err != nil {
+        return err
+    }
+    log.Printf("Deal %s for '%s' in stage '%s' (created: %t).", deal.Id, email, stage, created)
+    return nil
+}
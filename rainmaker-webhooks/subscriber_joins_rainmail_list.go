@@ -1,25 +1,18 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
 	"bitbucket.org/dagoodma/nancyhillis-go/util"
-
-	"github.com/gosexy/to"
-	"github.com/gosexy/yaml"
 )
 
 var Debug = true // Show/hide debug output
 //var WebhookIsSilent = false // don't print anything since we return JSON
-var UserAgent = "Nancy Hillis Studio Webhook Backend (wbhk97.nancyhillis.com)"
 
 // Subscriber joins rain mail list input data
 type InputData struct {
@@ -28,44 +21,13 @@ type InputData struct {
 	Email string `json:"email"`
 }
 
-// Zapier webhook output data
-type ZapOutputData struct {
-	Status    string `json:"status"`
-	Attempt   string `json:"attempt"`
-	Id        string `json:"id"`
-	RequestId string `json:"request_id"`
-}
-
-/* Rainmail -> Zapier settings */
-var SecretsFilePath = "/var/webhook/secrets/rainmaker_secrets.yml"
-var ZapierUrlParentKey = "RAIN_MAIL_LIST_ZAPIER_URL"
+// Custom field name in AC for Rainmaker ID
+var RainmakerIdFieldName = "rid"
 
 var ListsWithNoSlackAlert = []string{
 	"The Artists Journey Resource Library",
 }
 
-func GetUrlSecret(listName string) (string, error) {
-	secrets := yaml.New()
-	secrets, err := yaml.Open(SecretsFilePath)
-	if err != nil {
-		return "", err
-	}
-
-	url := to.String(secrets.Get(ZapierUrlParentKey, listName))
-	if len(url) < 1 {
-		// Remove slashes and try again
-		listName = strings.Replace(listName, "\\", "", -1)
-		url = to.String(secrets.Get(ZapierUrlParentKey, listName))
-
-		if len(url) < 1 {
-			msg := fmt.Sprintf("No url found for list: %s", listName)
-			return "", errors.New(msg)
-		}
-	}
-
-	return url, nil
-}
-
 func main() {
 	argsWithProg := os.Args
 	if len(argsWithProg) < 3 {
@@ -83,6 +45,10 @@ func main() {
 	if Debug {
 		util.RecordWebhookStarted(w)
 	}
+	if _, err := os.Stat("ac_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local AC secrets.")
+		ac.SecretsFilePath = "ac_secrets.yml"
+	}
 	log.Println("Entire headers: " + string(header))
 	log.Println("Entire payload: " + string(data))
 
@@ -114,37 +80,26 @@ func main() {
 		return
 	}
 
-	// Get Zapier webhook url secrets
-	url, err := GetUrlSecret(listName)
-	if err != nil {
-		HandleError(w, "Could not handle new subscriber \"%s\": %s", email, err.Error())
-		return
-	}
-
-	if Debug {
-		log.Printf("Forwarding to Zapier webhook: %s\n\t%v\n", url, m)
+	// Make sure they're in AC with their Rainmaker ID
+	d := ac.ContactDetails{
+		Email:  email,
+		Fields: map[string]string{RainmakerIdFieldName: id},
 	}
-	rawData, err := json.Marshal(m)
+	c, created, err := ac.CreateOrUpdateContact(d)
 	if err != nil {
-		HandleError(w, "Failed marshalling JSON data for webhook: %s", err.Error())
+		HandleError(w, "Could not create or update subscriber \"%s\" in AC: %s", email, err.Error())
 		return
 	}
-	r, err := ForwardZapierWebhook(url, rawData)
-	if err != nil {
-		HandleError(w, "Failed forwarding JSON data webhook url: %s. %v", url, err.Error())
-		return
+	contactStatus := "existing"
+	if created {
+		contactStatus = "new"
 	}
-	log.Printf("Here with resp: %s\n", r)
 
-	// Unmarshal the output data (response)
-	m2 := ZapOutputData{}
-	err = json.Unmarshal(r, &m2)
+	// Subscribe them to the AC list with the same name
+	acListName := strings.Replace(listName, "\\", "", -1)
+	err = ac.SubscribeContactToListByName(c.Id, acListName)
 	if err != nil {
-		HandleError(w, "Error while parsing output data for (%s) '%s'. %v", url, r, err)
-		return
-	}
-	if m2.Status != "success" {
-		HandleError(w, "Expected success forwarding data to webhook url (%s), got: %s. %s", url, m2.Status, r)
+		HandleError(w, "Could not subscribe \"%s\" to AC list \"%s\": %s", email, acListName, err.Error())
 		return
 	}
 
@@ -155,43 +110,12 @@ func main() {
 			w.Options.WantSlackSuccessAlert = false
 		}
 	}
-	message := fmt.Sprintf("Subscriber \"%s\" (%s) joined a list \"%s\" and was forwarded to a Zapier webhook: %s",
-		email, id, listName, url)
+	message := fmt.Sprintf("Subscriber \"%s\" (%s) joined a list \"%s\" and was subscribed as %s AC contact (%s)",
+		email, id, listName, contactStatus, c.Id)
 	util.ReportWebhookSuccess(w, message)
 	return
 }
 
-func ForwardZapierWebhook(url string, jsonData []byte) ([]byte, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		//log.Fatalln(err)
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", UserAgent)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		//log.Fatalln(err)
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-
-	//log.Println("response Status:", resp.Status)
-	//log.Println("response Headers:", resp.Header)
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		//log.Fatalln(err)
-		return nil, err
-	}
-	//log.Println("response Body:", string(body))
-
-	return body, nil
-}
-
 func HandleError(w *util.WebhookEvent, format string, args ...interface{}) {
 	message := fmt.Sprintf(format, args...)
 	util.PrintJsonError(message)
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -9,15 +8,38 @@ import (
 	//"github.com/gosexy/to"
 
 	"bitbucket.org/dagoodma/dagoodma-go/util"
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
 	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
 )
 
+// Course (acronym in the catalog) this hook handles, other cancellations are ignored
+var SjCourseAcronym = "SJC"
+
+// Tags to apply and remove in AC when a student cancels
+var AddTags = []string{"SJC_Cancelled"}
+var RemoveTags = []string{"SJC_Enrolled"}
+
 func main() {
 	argsWithProg := os.Args
 	if len(argsWithProg) < 3 {
 		log.Fatalf("Not enough arguments, expected %d given: %d",
 			3, len(argsWithProg))
 	}
+
+	// Local secrets?
+	if _, err := os.Stat("ac_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local AC secrets.")
+		ac.SecretsFilePath = "ac_secrets.yml"
+	}
+	if _, err := os.Stat("teachable_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local Teachable secrets.")
+		teachable.SecretsFilePath = "teachable_secrets.yml"
+	}
+	if _, err := os.Stat("course_catalog.yml"); !os.IsNotExist(err) {
+		log.Println("Got local course catalog.")
+		teachable.CourseCatalogFilePath = "course_catalog.yml"
+	}
+
 	// Create the webhook event
 	programName := string(argsWithProg[0])
 	header := []byte(argsWithProg[1])
@@ -25,40 +47,75 @@ func main() {
 	w := util.NewWebhookEvent(programName, header, data)
 	util.RecordWebhookStarted(w)
 
-	// Unmarshall the header and ensure its correct
-	h := &teachable.WebhookHeader{}
-	err := json.Unmarshal(header, &h)
+	// Parse the request and ensure it's really from Teachable
+	r, err := teachable.ParseWebhookRequest(argsWithProg[1:])
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
-	err = teachable.EnsureValidWebhook(h, data)
+	err = teachable.VerifyWebhook(r)
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
 
 	// Unmarshall the message
-	m := &teachable.StudentCancelled{}
-	err = json.Unmarshal(data, &m)
+	e, err := teachable.ParseWebhookEvent(data)
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
+	m, ok := e.(*teachable.StudentCancelled)
+	if !ok {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Unexpected webhook event: %s",
+			e.EventType()))
+		return
+	}
 
 	// Grab the data
 	email := m.Object.User.Email
 
-	/*
-		err = teachable.StudentCancelledCourse(email, EventAction, AddTags, RemoveTags)
-		if err != nil {
-			util.ReportWebhookFailure(progName, string(err), data)
-			return 0
-	*/
+	// Only handle cancellations of SJC
+	_, err = teachable.GetCourseCatalog()
+	if err != nil {
+		util.ReportWebhookFailure(w, err.Error())
+		return
+	}
+	course, err := teachable.GetCourse(m.Object.CourseId)
+	if err != nil {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Failed to fetch course %s for '%s': %s",
+			m.Object.CourseId, email, err))
+		return
+	}
+	if !course.IsAcronym(SjCourseAcronym) {
+		log.Printf("Ignoring cancellation of '%s' from non-SJ course: %s (%s)", email,
+			course.Name, m.Object.CourseId)
+		return
+	}
+
+	// Update their tags in AC
+	c, err := ac.GetContactByEmail(email)
+	if err != nil {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Failed to find cancelled student '%s' in AC: %s",
+			email, err))
+		return
+	}
+	added, err := ac.AddTagsToContact(c.Id, AddTags, false)
+	if err != nil {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Failed to add tags to contact '%s' (%s): %s",
+			email, c.Id, err))
+		return
+	}
+	removed, err := ac.RemoveTagsFromContact(c.Id, RemoveTags)
+	if err != nil {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Failed to remove tags from contact '%s' (%s): %s",
+			email, c.Id, err))
+		return
+	}
 
-	// Notify slack they joined
-	message := fmt.Sprintf("Student \"%s\" cancelled a course.\n",
-		email)
+	// Notify slack they cancelled
+	message := fmt.Sprintf("Student \"%s\" cancelled a course. Added %d and removed %d tags in AC.\n",
+		email, added, removed)
 	log.Println(message)
 	util.ReportWebhookSuccess(w, message)
 }
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -12,8 +12,19 @@ import (
 	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
 )
 
-// TODO support fetching this and remove hard coded id
-var ChangedEmailCustomFieldId = "71"
+// Custom field (by perstag) holding the new email of a contact that needs a merge
+var ChangedEmailCustomField = "CHANGED_EMAIL"
+
+// Field ID to fall back on if the perstag isn't found. Set with
+// CHANGED_EMAIL_FIELD_ID in the AC secrets, e.g. "71".
+var ChangedEmailCustomFieldId = ""
+
+// Automation that walks a contact through merging with their new email. Set
+// with CHANGED_EMAIL_AUTOMATION in the AC secrets, or none is started.
+var ChangedEmailAutomationName = ""
+
+// What happens to the old contact after it's merged into the one with the new email
+var MergeOldContact = ac.MERGE_FLAG_OLD_CONTACT
 
 func main() {
 	// Get the args
@@ -33,6 +44,15 @@ func main() {
         log.Println("Got local AC secrets.")
         ac.SecretsFilePath = "ac_secrets.yml"
     }
+    if _, err := os.Stat("teachable_secrets.yml"); !os.IsNotExist(err) {
+        log.Println("Got local Teachable secrets.")
+        teachable.SecretsFilePath = "teachable_secrets.yml"
+    }
+    var secrets ac.SecretsConfig
+    if err := secrets.GetSecrets(ac.SecretsFilePath); err == nil {
+        ChangedEmailAutomationName = secrets.ChangedEmailAutomation
+        ChangedEmailCustomFieldId = secrets.ChangedEmailFieldId
+    }
 
 	// Create the webhook event
 	programName := string(argsWithProg[0])
@@ -41,27 +61,31 @@ func main() {
 	w := util.NewWebhookEvent(programName, header, data)
 	util.RecordWebhookStarted(w)
 
-	// Unmarshall the header and ensure its correct
-	h := &teachable.WebhookHeader{}
-	err := json.Unmarshal(header, &h)
+	// Parse the request and ensure it's really from Teachable
+	r, err := teachable.ParseWebhookRequest(argsWithProg[1:])
 	if err != nil {
         util.ReportWebhookFailure(w, fmt.Sprintf("Failed unmarshaling header: %s", err))
 		return
 	}
-	err = teachable.EnsureValidWebhook(h, data)
+	err = teachable.VerifyWebhook(r)
 	if err != nil {
         util.ReportWebhookFailure(w, fmt.Sprintf("Failed validating webhook: %s", err))
 		return
 	}
 
 	// Unmarshall the message
-	m := &teachable.StudentUpdated{}
-	err = json.Unmarshal(data, &m)
+	e, err := teachable.ParseWebhookEvent(data)
 	if err != nil {
         util.ReportWebhookFailure(w, fmt.Sprintf("Failed unmarshaling update profile: %s",
             err))
 		return
 	}
+	m, ok := e.(*teachable.StudentUpdated)
+	if !ok {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Unexpected webhook event: %s",
+			e.EventType()))
+		return
+	}
 
 	// Grab the data
 	//_ := m.Object.Email
@@ -77,50 +101,102 @@ func main() {
         // Propagate changes (email) through to system
         // - Active Campaign
         c1, err := ac.GetContactByEmail(oldEmail)
-        if err != nil {
+        if errors.Is(err, ac.ErrNotFound) {
             util.ReportWebhookFailure(w, fmt.Sprintf("Failed to update '%s' email address to '%s': Could not find old email in AC",
                     oldEmail, newEmail))
             return
+        } else if err != nil {
+            util.ReportWebhookFailure(w, fmt.Sprintf("Failed to update '%s' email address to '%s': %s",
+                    oldEmail, newEmail, err))
+            return
         }
-        _, err = ac.GetContactByEmail(newEmail)
+        c2, err := ac.GetContactByEmail(newEmail)
         needMerge := false
         if err == nil {
-            needMerge = true
             // Found them in AC, can't update their email automatically
-            // TODO add them to the automation and set the field
-            err = ac.UpdateContactCustomField(c1, ChangedEmailCustomFieldId , newEmail)
-            if err != nil {
-                util.ReportWebhookFailure(w, fmt.Sprintf("Failed to set custom field to update contact '%s' who needs merge with '%s': %s",
-                    oldEmail, newEmail, err))
-                return
-            }
+            needMerge = true
+        } else if !errors.Is(err, ac.ErrNotFound) {
+            util.ReportWebhookFailure(w, fmt.Sprintf("Failed to check for new email '%s' in AC: %s",
+                    newEmail, err))
+            return
         }
 
         var message string
+        noteContactId := c1.Id
         if !needMerge {
             err = ac.UpdateContactEmail(c1.Id, newEmail)
-            if err != nil {
+            if errors.Is(err, ac.ErrConflict) {
+                // Someone took the email since we checked
+                needMerge = true
+                c2, err = ac.GetContactByEmail(newEmail)
+                if err != nil {
+                    util.ReportWebhookFailure(w, fmt.Sprintf("Failed to find conflicting contact '%s' in AC: %s",
+                        newEmail, err))
+                    return
+                }
+            } else if err != nil {
                 util.ReportWebhookFailure(w, fmt.Sprintf("Failed to update '%s' (%s) email to '%s': %s",
                     oldEmail, c1.Id, newEmail, err))
                 return
             }
             message = fmt.Sprintf("Webhook updated contact '%s' (%s) email from '%s' to: %s",
                 name, c1.Id, oldEmail, newEmail)
-        } else {
-            err = ac.UpdateContactCustomField(c1, ChangedEmailCustomFieldId, newEmail)
+        }
+        if needMerge {
+            // Merge the old contact into the one that has their new email
+            plan, err := ac.MergeContacts(c1.Id, c2.Id, ac.MergeOptions{OldContact: MergeOldContact})
+            if err == nil {
+                message = fmt.Sprintf("Webhook merged contact '%s' (%s) into '%s' (%s) after email change:" +
+                    " copied %d tags, %d list statuses, %d custom fields, and %d notes.",
+                    oldEmail, c1.Id, newEmail, c2.Id, len(plan.Tags), len(plan.Lists),
+                    len(plan.FieldValues), len(plan.Notes))
+                noteContactId = c2.Id // the old one may be gone
+                needMerge = false
+            } else if errors.Is(err, ac.ErrAlreadyMerged) {
+                // Delivered again after we merged them
+                message = fmt.Sprintf("Webhook skipped merging contact '%s' (%s) into '%s' (%s)" +
+                    " after email change: already merged.", oldEmail, c1.Id, newEmail, c2.Id)
+                noteContactId = c2.Id
+                needMerge = false
+            } else {
+                log.Printf("Failed merging contact (%s) into (%s), falling back to manual merge: %s",
+                    c1.Id, c2.Id, err)
+            }
+        }
+        if needMerge {
+            err = ac.UpdateContactCustomField(c1, ChangedEmailCustomField, newEmail)
+            if errors.Is(err, ac.ErrNotFound) && ChangedEmailCustomFieldId != "" {
+                log.Printf("Using custom field %s for the changed email: %s", ChangedEmailCustomFieldId, err)
+                err = ac.UpdateContactCustomField(c1, ChangedEmailCustomFieldId, newEmail)
+            }
             if err != nil {
                 util.ReportWebhookFailure(w, fmt.Sprintf("Failed to update email changed field for '%s' (%s) to '%s' for manual merge: %s",
                     oldEmail, c1.Id, newEmail, err))
                 return
             }
+            // The automation sends the merge instructions using the field set above
+            if ChangedEmailAutomationName == "" {
+                log.Printf("No automation configured for manual merge of '%s' (%s)", oldEmail, c1.Id)
+            } else {
+                err = ac.AddContactToAutomationByName(c1.Id, ChangedEmailAutomationName)
+                if errors.Is(err, ac.ErrNotFound) {
+                    log.Printf("Warning: automation '%s' for manual merge of '%s' (%s) not found in AC: %s",
+                        ChangedEmailAutomationName, oldEmail, c1.Id, err)
+                } else if err != nil {
+                    util.ReportWebhookFailure(w, fmt.Sprintf("Failed to add '%s' (%s) to automation '%s' for manual merge: %s",
+                        oldEmail, c1.Id, ChangedEmailAutomationName, err))
+                    return
+                }
+            }
             message = fmt.Sprintf("Webhook found conflict for contact (%s) email" +
                 " who changed from '%s' to '%s'. See email notification for instructions.",
                 c1.Id, oldEmail, newEmail)
         }
 
-        err = ac.AddNoteToContact(c1.Id, message)
+        // Retried webhooks shouldn't leave the same note twice
+        _, err = ac.AddNoteToContactOnce(noteContactId, message)
         if err != nil {
-            log.Printf("Failed to add note to contact (%s): %s", c1.Id, err)
+            log.Printf("Failed to add note to contact (%s): %s", noteContactId, err)
         }
         log.Println(message)
         util.ReportWebhookSuccess(w, message)
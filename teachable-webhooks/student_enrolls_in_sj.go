@@ -1,21 +1,51 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 
 	"bitbucket.org/dagoodma/dagoodma-go/util"
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
 	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
 )
 
+// Course (acronym in the catalog) this hook handles, other enrollments are ignored
+var SjCourseAcronym = "SJC"
+
+// Tags to apply and remove in AC when a student enrolls
+var AddTags = []string{"SJC_Enrolled"}
+var RemoveTags = []string{"SJC_Cancelled"}
+
+// Event recorded in AC when a student enrolls (event data is the course ID)
+var EventAction = "teachable_enrolled"
+
+// Pipeline and stage (by name) for the deal recorded from their sale
+var DealPipelineName = "Studio Journey"
+var DealStageName = "Enrolled"
+
 func main() {
 	argsWithProg := os.Args
 	if len(argsWithProg) < 3 {
 		log.Fatalf("Not enough arguments, expected %d given: %d",
 			3, len(argsWithProg))
 	}
+
+	// Local secrets?
+	if _, err := os.Stat("ac_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local AC secrets.")
+		ac.SecretsFilePath = "ac_secrets.yml"
+	}
+	if _, err := os.Stat("teachable_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local Teachable secrets.")
+		teachable.SecretsFilePath = "teachable_secrets.yml"
+	}
+	if _, err := os.Stat("course_catalog.yml"); !os.IsNotExist(err) {
+		log.Println("Got local course catalog.")
+		teachable.CourseCatalogFilePath = "course_catalog.yml"
+	}
+
 	// Create the webhook event
 	programName := string(argsWithProg[0])
 	header := []byte(argsWithProg[1])
@@ -23,26 +53,30 @@ func main() {
 	w := util.NewWebhookEvent(programName, header, data)
 	util.RecordWebhookStarted(w)
 
-	// Unmarshall the header and ensure its correct
-	h := &teachable.WebhookHeader{}
-	err := json.Unmarshal(header, &h)
+	// Parse the request and ensure it's really from Teachable
+	r, err := teachable.ParseWebhookRequest(argsWithProg[1:])
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
-	err = teachable.EnsureValidWebhook(h, data)
+	err = teachable.VerifyWebhook(r)
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
 
 	// Unmarshall the message
-	m := &teachable.StudentEnrolled{}
-	err = json.Unmarshal(data, &m)
+	e, err := teachable.ParseWebhookEvent(data)
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
+	m, ok := e.(*teachable.StudentEnrolled)
+	if !ok {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Unexpected webhook event: %s",
+			e.EventType()))
+		return
+	}
 
 	// Grab the data
 	email := m.Object.User.Email
@@ -50,13 +84,84 @@ func main() {
 	//name := m.Object.User.Name
 	ip := m.Object.User.CurrentSignInIp
 
-	// TODO check course id
-	// if courseId != SjCourseId {
-	//teachable.StudentEnrolledInCourse(email, id, name, ip, EventAction, AddTags, RemoveTags)
+	// Only handle enrollments in SJC
+	_, err = teachable.GetCourseCatalog()
+	if err != nil {
+		util.ReportWebhookFailure(w, err.Error())
+		return
+	}
+	course, err := teachable.GetCourse(m.Object.CourseId)
+	if err != nil {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Failed to fetch course %s for '%s': %s",
+			m.Object.CourseId, email, err))
+		return
+	}
+	if !course.IsAcronym(SjCourseAcronym) {
+		log.Printf("Ignoring enrollment of '%s' in non-SJ course: %s (%s)", email,
+			course.Name, m.Object.CourseId)
+		return
+	}
+
+	// Update their tags in AC
+	c, err := ac.GetContactByEmail(email)
+	if err != nil {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Failed to find enrolled student '%s' in AC: %s",
+			email, err))
+		return
+	}
+	added, err := ac.AddTagsToContact(c.Id, AddTags, false)
+	if err != nil {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Failed to add tags to contact '%s' (%s): %s",
+			email, c.Id, err))
+		return
+	}
+	removed, err := ac.RemoveTagsFromContact(c.Id, RemoveTags)
+	if err != nil {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Failed to remove tags from contact '%s' (%s): %s",
+			email, c.Id, err))
+		return
+	}
+
+	// Record the event in AC
+	err = ac.TrackEvent(email, EventAction, m.Object.CourseId)
+	if err != nil && !errors.Is(err, ac.ErrEventTrackingDisabled) {
+		log.Printf("Failed to track '%s' event for '%s' in AC: %s", EventAction, email, err)
+	}
+
+	// Record their sale as a won deal in AC
+	dealMessage := ""
+	if m.Object.SaleId != "" && m.Object.SaleId != "0" {
+		sale, err := teachable.GetSaleById(m.Object.SaleId)
+		if err != nil {
+			util.ReportWebhookFailure(w, fmt.Sprintf("Failed to fetch sale %s for '%s': %s",
+				m.Object.SaleId, email, err))
+			return
+		}
+		d := ac.DealDetails{
+			ContactId: c.Id,
+			Title:     fmt.Sprintf("Teachable sale %s", m.Object.SaleId),
+			Pipeline:  DealPipelineName,
+			Stage:     DealStageName,
+			Value:     int64(sale.Price),
+			Currency:  sale.Currency,
+			Status:    ac.DEAL_STATUS_WON,
+		}
+		if sale.Coupon.Id != 0 {
+			d.Value = int64(sale.Coupon.NewPurchasePrice)
+			d.Description = fmt.Sprintf("Coupon: %s", sale.Coupon.Code)
+		}
+		deal, _, err := ac.CreateOrUpdateContactDeal(d)
+		if err != nil {
+			util.ReportWebhookFailure(w, fmt.Sprintf("Failed to record deal for sale %s of '%s' in AC: %s",
+				m.Object.SaleId, email, err))
+			return
+		}
+		dealMessage = fmt.Sprintf(" Recorded deal %s.", deal.Id)
+	}
 
 	// Notify slack they joined
-	message := fmt.Sprintf("Student \"%s\" (%s) (ip: %s) enrolled in a course.\n",
-		email, id, ip)
+	message := fmt.Sprintf("Student \"%s\" (%s) (ip: %s) enrolled in a course."+
+		" Added %d and removed %d tags in AC.%s\n", email, id, ip, added, removed, dealMessage)
 	log.Println(message)
 	util.ReportWebhookSuccess(w, message)
 }
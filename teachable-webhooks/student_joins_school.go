@@ -1,21 +1,36 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 
 	"bitbucket.org/dagoodma/dagoodma-go/util"
+	ac "bitbucket.org/dagoodma/nancyhillis-go/activecampaign"
 	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
 )
 
+// Event recorded in AC when a student joins the school
+var EventAction = "teachable_joined_school"
+
 func main() {
 	argsWithProg := os.Args
 	if len(argsWithProg) < 3 {
 		log.Fatalf("Not enough arguments, expected %d given: %d",
 			3, len(argsWithProg))
 	}
+
+	// Local secrets?
+	if _, err := os.Stat("ac_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local AC secrets.")
+		ac.SecretsFilePath = "ac_secrets.yml"
+	}
+	if _, err := os.Stat("teachable_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local Teachable secrets.")
+		teachable.SecretsFilePath = "teachable_secrets.yml"
+	}
+
 	// Create the webhook event
 	programName := string(argsWithProg[0])
 	header := []byte(argsWithProg[1])
@@ -23,39 +38,41 @@ func main() {
 	w := util.NewWebhookEvent(programName, header, data)
 	util.RecordWebhookStarted(w)
 
-	// Unmarshall the header and ensure its correct
-	h := &teachable.WebhookHeader{}
-	err := json.Unmarshal(header, &h)
+	// Parse the request and ensure it's really from Teachable
+	r, err := teachable.ParseWebhookRequest(argsWithProg[1:])
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
-	err = teachable.EnsureValidWebhook(h, data)
+	err = teachable.VerifyWebhook(r)
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
 
 	// Unmarshall the message
-	m := &teachable.NewStudent{}
-	err = json.Unmarshal(data, &m)
+	e, err := teachable.ParseWebhookEvent(data)
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
+	m, ok := e.(*teachable.NewStudent)
+	if !ok {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Unexpected webhook event: %s",
+			e.EventType()))
+		return
+	}
 
 	// Grab the data
 	email := m.Object.Email
 	id := m.Object.Id
 	name := m.Object.Name
 
-	/*
-		err = teachable.StudentJoinedSchool(id, email, name, AddTags)
-		if err != nil {
-			util.ReportWebhookFailure(progName, err.Error(), data)
-			return
-		}
-	*/
+	// Record the event in AC (event data is their Teachable ID)
+	err = ac.TrackEvent(email, EventAction, id)
+	if err != nil && !errors.Is(err, ac.ErrEventTrackingDisabled) {
+		log.Printf("Failed to track '%s' event for '%s' in AC: %s", EventAction, email, err)
+	}
 
 	// Notify slack they joined
 	message := fmt.Sprintf("Student \"%s\" (%s) with name \"%s\" joined your school.\n",
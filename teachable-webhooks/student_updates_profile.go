@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -27,6 +26,10 @@ func main() {
         slackwrap.SecretsFilePath = "slack_secrets.yml"
     }
     //slackwrap.DEBUG = false
+    if _, err := os.Stat("teachable_secrets.yml"); !os.IsNotExist(err) {
+        log.Println("Got local Teachable secrets.")
+        teachable.SecretsFilePath = "teachable_secrets.yml"
+    }
 
 	// Create the webhook event
 	programName := string(argsWithProg[0])
@@ -35,26 +38,30 @@ func main() {
 	w := util.NewWebhookEvent(programName, header, data)
 	util.RecordWebhookStarted(w)
 
-	// Unmarshall the header and ensure its correct
-	h := &teachable.WebhookHeader{}
-	err := json.Unmarshal(header, &h)
+	// Parse the request and ensure it's really from Teachable
+	r, err := teachable.ParseWebhookRequest(argsWithProg[1:])
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
-	err = teachable.EnsureValidWebhook(h, data)
+	err = teachable.VerifyWebhook(r)
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
 
 	// Unmarshall the message
-	m := &teachable.StudentUpdated{}
-	err = json.Unmarshal(data, &m)
+	e, err := teachable.ParseWebhookEvent(data)
 	if err != nil {
 		util.ReportWebhookFailure(w, err.Error())
 		return
 	}
+	m, ok := e.(*teachable.StudentUpdated)
+	if !ok {
+		util.ReportWebhookFailure(w, fmt.Sprintf("Unexpected webhook event: %s",
+			e.EventType()))
+		return
+	}
 
 	// Grab the data
 	email := m.Object.Email
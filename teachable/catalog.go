@@ -0,0 +1,193 @@
+package teachable
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "os"
+    "strings"
+
+    "gopkg.in/yaml.v2"
+)
+
+// Courses we know about and how each maps to tags and automations in
+// ActiveCampaign. See course_catalog.yml for an example.
+var CourseCatalogFilePath = "/var/webhook/config/course_catalog.yml"
+
+type CourseCatalog struct {
+    // Defaults for courses that don't list their own
+    AutomationSuffixes  []string        `yaml:"automation_suffixes"`
+    ExtraTagSuffixes    []string        `yaml:"extra_tag_suffixes"`
+    Courses             []CatalogCourse `yaml:"courses"`
+}
+
+type CatalogCourse struct {
+    Acronym             CourseAcronym   `yaml:"acronym"`
+    Name                string          `yaml:"name"`
+    CourseId            uint64          `yaml:"course_id"` // optional, saves a lookup by friendly URL
+    FriendlyUrl         string          `yaml:"friendly_url"`
+    TagPrefix           string          `yaml:"tag_prefix"` // AC tag and automation prefix, defaults to the acronym
+    AutomationSuffixes  []string        `yaml:"automation_suffixes"` // AC automations enrolled students may be in
+    ExtraTagSuffixes    []string        `yaml:"extra_tag_suffixes"` // other AC tags reported for the course
+    Automations         []string        `yaml:"automations"` // full names of any other automations
+    ExtraTags           []string        `yaml:"extra_tags"` // full names of any other tags
+}
+
+var savedCourseCatalog *CourseCatalog
+var savedCourseCatalogErr error // so a missing catalog is only read and logged once
+
+// Loads and checks a catalog, filling in each course's defaults
+func LoadCourseCatalog(filePath string) (*CourseCatalog, error) {
+    yamlFile, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        return nil, fmt.Errorf("Failed reading course catalog: %w", err)
+    }
+    c := &CourseCatalog{}
+    err = yaml.Unmarshal(yamlFile, c)
+    if err != nil {
+        return nil, fmt.Errorf("Failed unmarshaling course catalog '%s': %w", filePath, err)
+    }
+
+    seen := make(map[CourseAcronym]bool)
+    for i := range c.Courses {
+        course := &c.Courses[i]
+        course.Acronym = normalizeCourseAcronym(string(course.Acronym))
+        if course.Acronym == "" || course.FriendlyUrl == "" && course.CourseId == 0 {
+            return nil, fmt.Errorf("Course %d in catalog '%s' needs an acronym and a course ID or friendly URL",
+                i + 1, filePath)
+        }
+        if seen[course.Acronym] {
+            return nil, fmt.Errorf("Duplicate course '%s' in catalog: %s", course.Acronym, filePath)
+        }
+        seen[course.Acronym] = true
+        if course.TagPrefix == "" {
+            course.TagPrefix = string(course.Acronym)
+        }
+        if course.AutomationSuffixes == nil {
+            course.AutomationSuffixes = c.AutomationSuffixes
+        }
+        if course.ExtraTagSuffixes == nil {
+            course.ExtraTagSuffixes = c.ExtraTagSuffixes
+        }
+    }
+    return c, nil
+}
+
+// Returns the catalog from CourseCatalogFilePath, loading it the first time.
+// A failed load is remembered, so later calls return the same error.
+func GetCourseCatalog() (*CourseCatalog, error) {
+    if savedCourseCatalog != nil {
+        return savedCourseCatalog, nil
+    }
+    if savedCourseCatalogErr != nil {
+        return nil, savedCourseCatalogErr
+    }
+    c, err := LoadCourseCatalog(CourseCatalogFilePath)
+    if err != nil {
+        if errors.Is(err, os.ErrNotExist) {
+            log.Printf("Course catalog not found at %s", CourseCatalogFilePath)
+        } else {
+            log.Printf("Invalid course catalog at %s: %s", CourseCatalogFilePath, err)
+        }
+        savedCourseCatalogErr = err
+        return nil, err
+    }
+    savedCourseCatalog = c
+    return c, nil
+}
+
+// Replaces the catalog used by the package. Passing nil will cause it to be
+// reloaded from CourseCatalogFilePath.
+func SetCourseCatalog(c *CourseCatalog) {
+    savedCourseCatalog = c
+    savedCourseCatalogErr = nil
+}
+
+func normalizeCourseAcronym(a string) CourseAcronym {
+    return CourseAcronym(strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(a), "-", "_")))
+}
+
+// Finds a course by its acronym (case and "-" or "_" don't matter), AC tag
+// prefix or friendly URL
+func (c *CourseCatalog) Get(a string) (*CatalogCourse, error) {
+    a2 := normalizeCourseAcronym(a)
+    for i, course := range c.Courses {
+        if course.Acronym == a2 || normalizeCourseAcronym(course.TagPrefix) == a2 ||
+            course.FriendlyUrl == a {
+            return &c.Courses[i], nil
+        }
+    }
+    return nil, fmt.Errorf("Unknown course acronym: %s", a)
+}
+
+// Finds a Teachable course in the catalog by ID, then friendly URL. Returns
+// nil if it's not there.
+func (c *CourseCatalog) Find(id uint64, friendlyUrl string) *CatalogCourse {
+    for i, course := range c.Courses {
+        if id > 0 && course.CourseId == id {
+            return &c.Courses[i]
+        }
+    }
+    for i, course := range c.Courses {
+        if friendlyUrl != "" && course.FriendlyUrl == friendlyUrl {
+            return &c.Courses[i]
+        }
+    }
+    return nil
+}
+
+func (c *CourseCatalog) Acronyms() []CourseAcronym {
+    var l []CourseAcronym
+    for _, course := range c.Courses {
+        l = append(l, course.Acronym)
+    }
+    return l
+}
+
+// Lists each course for a program's usage text
+func (c *CourseCatalog) Usage() string {
+    var strBuffer bytes.Buffer
+    for _, course := range c.Courses {
+        strBuffer.WriteString(fmt.Sprintf("\t%s: %s\n", course.TagPrefix, course.Name))
+    }
+    return strBuffer.String()
+}
+
+// Returns the AC tag or automation name with the course's prefix, e.g.
+// "SJC_Enrolled"
+func (c *CatalogCourse) TagName(suffix string) string {
+    return c.TagPrefix + "_" + suffix
+}
+
+// Returns the prefixed automation suffixes followed by the other automations
+func (c *CatalogCourse) AutomationNames() []string {
+    var l []string
+    for _, s := range c.AutomationSuffixes {
+        l = append(l, c.TagName(s))
+    }
+    return append(l, c.Automations...)
+}
+
+// Returns the prefixed extra tag suffixes followed by the other tags
+func (c *CatalogCourse) ExtraTagNames() []string {
+    var l []string
+    for _, s := range c.ExtraTagSuffixes {
+        l = append(l, c.TagName(s))
+    }
+    return append(l, c.ExtraTags...)
+}
+
+// Returns the acronym of a Teachable course, or "" if it's not in the catalog
+func findCourseAcronym(id uint64, friendlyUrl string) CourseAcronym {
+    c, err := GetCourseCatalog()
+    if err != nil {
+        return ""
+    }
+    course := c.Find(id, friendlyUrl)
+    if course == nil {
+        return ""
+    }
+    return course.Acronym
+}
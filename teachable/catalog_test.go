@@ -0,0 +1,127 @@
+package teachable_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
+)
+
+func TestCourseCatalog(t *testing.T) {
+	c, err := teachable.LoadCourseCatalog("course_catalog.yml")
+	if err != nil {
+		t.Fatalf("LoadCourseCatalog() returned error: %v", err)
+	}
+	teachable.SetCourseCatalog(c)
+	defer teachable.SetCourseCatalog(nil)
+
+	cases := []struct {
+		acronymIn    string
+		wantAcronym  teachable.CourseAcronym
+		wantEnrolled string
+		wantError    bool
+	}{
+		{"SJC", "SJC", "SJC_Enrolled", false},
+		{"sjc", "SJC", "SJC_Enrolled", false},
+		{"sjc-course", "SJC", "SJC_Enrolled", false},
+		{"BUNDLE_TAJC-EWC", "BUNDLE_TAJC_EWC", "BUNDLE_TAJC-EWC_Enrolled", false},
+		{"bundle_tajc_ewc", "BUNDLE_TAJC_EWC", "BUNDLE_TAJC-EWC_Enrolled", false},
+		{"NOPE", "", "", true},
+	}
+	for _, tc := range cases {
+		course, err := c.Get(tc.acronymIn)
+		gotError := err != nil
+		if gotError != tc.wantError {
+			t.Errorf("Get(%q) == (error=%t), want (error=%t), got err: %v",
+				tc.acronymIn, gotError, tc.wantError, err)
+			continue
+		}
+		if gotError {
+			if teachable.IsValidCourseAcronym(tc.acronymIn) {
+				t.Errorf("IsValidCourseAcronym(%q) == true, want false", tc.acronymIn)
+			}
+			continue
+		}
+		if course.Acronym != tc.wantAcronym || course.TagName("Enrolled") != tc.wantEnrolled {
+			t.Errorf("Get(%q) == (acronym=%s, tag=%s), want (acronym=%s, tag=%s)", tc.acronymIn,
+				course.Acronym, course.TagName("Enrolled"), tc.wantAcronym, tc.wantEnrolled)
+		}
+		if a, err := teachable.GetCourseAcronym(tc.acronymIn); err != nil || a != tc.wantAcronym {
+			t.Errorf("GetCourseAcronym(%q) == (%s, %v), want %s", tc.acronymIn, a, err, tc.wantAcronym)
+		}
+	}
+
+	// Courses without their own suffixes get the catalog's
+	course, _ := c.Get("TAJC")
+	if len(course.AutomationNames()) != len(c.AutomationSuffixes) ||
+		course.AutomationNames()[0] != "TAJC_Enrolled" {
+		t.Errorf("AutomationNames() == %v, want the catalog's %v with the TAJC prefix",
+			course.AutomationNames(), c.AutomationSuffixes)
+	}
+	if !strings.Contains(c.Usage(), "\tTAPCIP: The Adjacent Possible: Creativity Immersion Program\n") {
+		t.Errorf("Usage() is missing TAPCIP:\n%s", c.Usage())
+	}
+}
+
+func TestLoadCourseCatalogInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		yamlIn    string
+		wantError bool
+	}{
+		{"courses:\n  - acronym: ABC\n    course_id: 5\n", false},
+		{"courses:\n  - acronym: ABC\n", true},
+		{"courses:\n  - friendly_url: abc\n", true},
+		{"courses:\n  - acronym: ABC\n    course_id: 5\n  - acronym: abc\n    course_id: 6\n", true},
+		{"courses: [", true},
+	}
+	for i, c := range cases {
+		path := filepath.Join(dir, "course_catalog.yml")
+		if err := ioutil.WriteFile(path, []byte(c.yamlIn), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := teachable.LoadCourseCatalog(path)
+		if gotError := err != nil; gotError != c.wantError {
+			t.Errorf("%d: LoadCourseCatalog(%q) == (error=%t), want (error=%t), got err: %v",
+				i, c.yamlIn, gotError, c.wantError, err)
+		}
+	}
+}
+
+func TestGetCourseCatalogMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldPath := teachable.CourseCatalogFilePath
+	defer func() { teachable.CourseCatalogFilePath = oldPath }()
+	defer teachable.SetCourseCatalog(nil)
+	teachable.SetCourseCatalog(nil)
+	teachable.CourseCatalogFilePath = filepath.Join(dir, "course_catalog.yml")
+
+	if _, err := teachable.GetCourseCatalog(); err == nil {
+		t.Fatalf("GetCourseCatalog() with no catalog returned no error")
+	}
+	// The failure is remembered until the catalog is reset
+	if err := ioutil.WriteFile(teachable.CourseCatalogFilePath,
+		[]byte("courses:\n  - acronym: ABC\n    course_id: 5\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := teachable.GetCourseCatalog(); err == nil {
+		t.Errorf("GetCourseCatalog() after a failed load returned no error")
+	}
+	teachable.SetCourseCatalog(nil)
+	if c, err := teachable.GetCourseCatalog(); err != nil || len(c.Courses) != 1 {
+		t.Errorf("GetCourseCatalog() after reset == (%v, %v), want 1 course", c, err)
+	}
+}
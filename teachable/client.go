@@ -0,0 +1,248 @@
+package teachable
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "log"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/xiam/to"
+    "gopkg.in/yaml.v2"
+)
+
+const (
+    DEFAULT_REQUEST_TIMEOUT = 30 * time.Second
+)
+
+// Kinds of API failures, check for them with errors.Is()
+var (
+    ErrNotFound = errors.New("Not found")
+    ErrConflict = errors.New("Conflict") // e.g. email already taken
+)
+
+// Client holds everything needed to talk to one Teachable school. Point
+// SchoolUrl at an httptest server or another school to redirect all
+// requests made through it.
+type Client struct {
+    SchoolUrl           string // e.g. https://<school>.teachable.com, used for admin links
+    ApiUrl              string // includes API_URL_SUFFIX
+    User                string
+    Password            string
+    RelicId             string // expected relic ID on incoming webhooks
+    UserAgent           string
+    HttpClient          *http.Client
+    ConcurrencyLimit    int
+    WebhookVerifier     WebhookVerifier // extra checks for VerifyWebhook(), optional
+}
+
+// Creates a client for the given school URL (e.g. https://<school>.teachable.com)
+// and API login. The v1 API suffix is added automatically.
+func NewClient(schoolUrl string, user string, password string) *Client {
+    schoolUrl = strings.TrimSuffix(schoolUrl, "/")
+    return &Client{
+        SchoolUrl: schoolUrl,
+        ApiUrl: fmt.Sprintf("%s%s", schoolUrl, API_URL_SUFFIX),
+        User: user,
+        Password: password,
+        UserAgent: USER_AGENT,
+        HttpClient: &http.Client{Timeout: DEFAULT_REQUEST_TIMEOUT},
+        ConcurrencyLimit: REQUEST_CONCURRENCY_LIMIT,
+    }
+}
+
+// Creates a client from a YAML secrets file (see SecretsConfig).
+func NewClientFromSecrets(filePath string) (*Client, error) {
+    yamlFile, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        return nil, fmt.Errorf("Failed reading secrets file '%s': %w", filePath, err)
+    }
+    var s SecretsConfig
+    err = yaml.Unmarshal(yamlFile, &s)
+    if err != nil {
+        return nil, fmt.Errorf("Failed unmarshaling secrets file '%s': %w", filePath, err)
+    }
+    if s.ApiUrl == "" || s.ApiUser == "" {
+        return nil, fmt.Errorf("Secrets file '%s' is missing API_URL or API_USER", filePath)
+    }
+
+    c := NewClient(s.ApiUrl, s.ApiUser, s.ApiPassword)
+    c.RelicId = s.RelicId
+    c.WebhookVerifier, err = NewWebhookVerifierFromSecrets(&s)
+    if err != nil {
+        return nil, fmt.Errorf("Failed reading webhook settings from '%s': %w", filePath, err)
+    }
+    return c, nil
+}
+
+func (c *Client) concurrencyLimit() int {
+    if c.ConcurrencyLimit < 1 {
+        return 1
+    }
+    return c.ConcurrencyLimit
+}
+
+func (c *Client) httpClient() *http.Client {
+    if c.HttpClient == nil {
+        return http.DefaultClient
+    }
+    return c.HttpClient
+}
+
+// Sends a request with basic auth and reads the response body. Any other
+// status than the expected ones is returned as an error.
+func (c *Client) doApiRequestContext(ctx context.Context, method string, requestUrl string,
+    data []byte, expectedStatusCodes ...int) (*ApiRequestResult) {
+    r := &ApiRequestResult{Data: nil, Error: nil, Url: requestUrl}
+
+    if DEBUG {
+        log.Printf("Requesting %s url: %s", method, requestUrl)
+    }
+
+    var reqBody io.Reader
+    if data != nil {
+        reqBody = bytes.NewReader(data)
+    }
+    req, err := http.NewRequest(method, requestUrl, reqBody)
+    if err != nil {
+        r.Error = err
+        return r
+    }
+    req = req.WithContext(ctx)
+    if data != nil {
+        req.Header.Set("Content-Type", "application/json; charset=utf-8")
+    }
+    userAgent := c.UserAgent
+    if userAgent == "" {
+        userAgent = USER_AGENT
+    }
+    req.Header.Set("User-Agent", userAgent)
+    req.SetBasicAuth(c.User, c.Password)
+
+    resp, err := c.httpClient().Do(req)
+    if err != nil {
+        r.Error = err
+        return r
+    }
+
+    defer resp.Body.Close()
+
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        r.Error = err
+        return r
+    }
+    r.StatusCode = resp.StatusCode
+
+    if len(expectedStatusCodes) < 1 {
+        expectedStatusCodes = []int{http.StatusOK}
+    }
+    for _, code := range expectedStatusCodes {
+        if resp.StatusCode == code {
+            r.Data = body
+            return r
+        }
+    }
+    r.Error = fmt.Errorf("Got '%s' response with status code: %d, expected: %d", resp.Status,
+        resp.StatusCode, expectedStatusCodes[0])
+    switch resp.StatusCode {
+    case http.StatusNotFound:
+        r.Error = fmt.Errorf("%w: %s", ErrNotFound, r.Error)
+    case http.StatusConflict, http.StatusUnprocessableEntity:
+        r.Error = fmt.Errorf("%w: %s: %s", ErrConflict, r.Error, body)
+    }
+    return r
+}
+
+func (c *Client) doApiRequestGet(ctx context.Context, requestUrl string) (*ApiRequestResult) {
+    return c.doApiRequestContext(ctx, http.MethodGet, requestUrl, nil, http.StatusOK)
+}
+
+/* This function will asynchronously fetch all pages of data from the
+ * endpoint. The list response interface, r, given as a parameter will
+ * have it's metadata populated, but the list member within will not be
+ * poulated and must be filled using the API result array returned.
+ */
+// Refs:
+// - https://guzalexander.com/2013/12/06/golang-channels-tutorial.html
+// - https://gist.github.com/montanaflynn/ea4b92ed640f790c4b9cee36046a5383
+func (c *Client) fetchAllEndpointDataAsync(ctx context.Context, u *url.URL, q *url.Values,
+    r ListResponse) ([]ApiRequestResult, error) {
+    // Set initial page for discovery request
+    q.Set("page", "1")
+    u.RawQuery = q.Encode()
+    resp := c.doApiRequestGet(ctx, u.String())
+    if resp.Error != nil {
+        return nil, resp.Error
+    }
+
+    // Unmarshal the message metedata
+    err := json.Unmarshal(resp.Data, r)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    total := r.TotalResults()
+    if total < 1 {
+        return nil, fmt.Errorf("%w: could not find any endpoint data with query: %#v",
+            ErrNotFound, q)
+    }
+    totalPages := r.TotalPages()
+
+    if DEBUG {
+        log.Printf("Fetching %d pages from endpoint '%s' with %d total results.",
+            totalPages, u.Path, total)
+    }
+    q.Set("page", "2")
+    u.RawQuery = q.Encode()
+
+    // this buffered channel will block at the concurrency limit
+    semaphoreChan := make(chan struct{}, c.concurrencyLimit())
+    // this channel will not block and collect the http request results
+    resultsChan := make(chan *ApiRequestResult)
+
+    // make sure we close these channels when we're done with them
+    defer func() {
+        close(semaphoreChan)
+        close(resultsChan)
+    }()
+
+    for i := 2; i <= totalPages; i++ {
+        go func(page int) {
+            semaphoreChan <- struct{}{}
+
+            // Build the request URL string
+            requestUrl := RegexPageParameter.ReplaceAllString(u.String(),
+                "$1=" + to.String(page))
+            if DEBUG && DEBUG_VERBOSE {
+                log.Printf("Here doing request for page=%d, url=%s", page, requestUrl)
+            }
+
+            // Requests fail fast once ctx is done, so every page still
+            // sends a result
+            resultsChan <- c.doApiRequestGet(ctx, requestUrl)
+
+            <-semaphoreChan
+        }(i)
+    }
+
+    if DEBUG {
+        log.Printf("Listening for %d channel results...", totalPages)
+    }
+    var results []ApiRequestResult
+    results = append(results, *resp) // first add the initial result
+    for len(results) < totalPages {
+        result := <-resultsChan
+        results = append(results, *result)
+    }
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
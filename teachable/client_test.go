@@ -0,0 +1,85 @@
+package teachable_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
+)
+
+func TestNewClientFromSecretsMissingFile(t *testing.T) {
+	c, err := teachable.NewClientFromSecrets("does_not_exist_secrets.yml")
+	if err == nil || c != nil {
+		t.Errorf("NewClientFromSecrets(%q) == (client=%t, error=%t), want (client=false, error=true)",
+			"does_not_exist_secrets.yml", c != nil, err != nil)
+	}
+}
+
+func TestClientGetCourseStudents(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "api-user" || password != "api-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v1/users" || r.URL.Query().Get("enrolled_in_specific[]") != "7" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		fmt.Fprintf(w, `{"users":[{"id":%s,"email":"student%s@example.com"}],`+
+			`"meta":{"page":%s,"total":3,"number_of_pages":3}}`, page, page, page)
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		user      string
+		wantCount int
+		wantError bool
+	}{
+		{"api-user", 3, false},
+		{"bad-user", 0, true},
+	}
+	for _, c := range cases {
+		client := teachable.NewClient(ts.URL, c.user, "api-password")
+		l, err := client.GetCourseStudents(context.Background(), 7)
+		gotError := err != nil
+		if gotError != c.wantError {
+			t.Errorf("GetCourseStudents(7) as %q == (error=%t), want (error=%t), got err: %v",
+				c.user, gotError, c.wantError, err)
+			continue
+		}
+		if len(l) != c.wantCount {
+			t.Errorf("GetCourseStudents(7) as %q returned %d students, want %d", c.user, len(l), c.wantCount)
+		}
+	}
+}
+
+func TestClientContextDeadline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprint(w, `{"id":1,"email":"tester@example.com"}`)
+	}))
+	defer ts.Close()
+
+	client := teachable.NewClient(ts.URL, "api-user", "api-password")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.GetUserById(ctx, 1)
+	if err == nil {
+		t.Errorf("GetUserById() past its deadline returned no error")
+	}
+}
+
+func TestClientGetUserProfileUrlById(t *testing.T) {
+	client := teachable.NewClient("https://school.teachable.com/", "api-user", "api-password")
+	got := client.GetUserProfileUrlById(42)
+	want := "https://school.teachable.com/admin/users/42/information"
+	if got != want {
+		t.Errorf("GetUserProfileUrlById(42) == %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+package teachable
+
+import (
+    "context"
+)
+
+// Package-level functions that use a shared default client loaded from
+// SecretsFilePath. These keep the older call style working, but new code
+// should create its own Client and pass a context.
+
+var defaultClient *Client
+
+// Returns the default client, loading it from SecretsFilePath the first
+// time it's needed.
+func DefaultClient() (*Client, error) {
+    if defaultClient != nil {
+        return defaultClient, nil
+    }
+    c, err := NewClientFromSecrets(SecretsFilePath)
+    if err != nil {
+        return nil, err
+    }
+    if SAVE_API_KEY {
+        defaultClient = c
+    }
+    return c, nil
+}
+
+// Replaces the client used by the package-level functions. Passing nil
+// will cause it to be reloaded from SecretsFilePath.
+func SetDefaultClient(c *Client) {
+    defaultClient = c
+}
+
+func GetAllUsers() ([]ListUsersUser, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetAllUsers(context.Background())
+}
+
+func GetCourseStudents(courseId uint64) ([]ListUsersUser, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetCourseStudents(context.Background(), courseId)
+}
+
+func GetUsersAsync(params QueryParameters) (*ListUsers, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetUsersAsync(context.Background(), params)
+}
+
+func GetUser(params QueryParameters) (*ListUsersUser, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetUser(context.Background(), params)
+}
+
+func GetUserByEmail(email string) (*ListUsersUser, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetUserByEmail(context.Background(), email)
+}
+
+func GetUserById(id uint64) (*ListUsersUser, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetUserById(context.Background(), id)
+}
+
+func GetUserProfileUrlById(id uint64) string {
+    c, err := DefaultClient()
+    if err != nil {
+        return ""
+    }
+    return c.GetUserProfileUrlById(id)
+}
+
+func GetUserProfileUrlByEmail(email string) (string, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return "", err
+    }
+    return c.GetUserProfileUrlByEmail(context.Background(), email)
+}
+
+func GetUserEnrollments(id uint64) ([]ListEnrollmentsEnrollment, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetUserEnrollments(context.Background(), id)
+}
+
+func GetCourse(id string) (*RetrieveCourse, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetCourse(context.Background(), id)
+}
+
+func GetAllCourses() ([]ListCoursesCourse, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetAllCourses(context.Background())
+}
+
+func GetSaleById(id string) (*RetrieveSale, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetSaleById(context.Background(), id)
+}
+
+func EnrollUser(userId uint64, courseId uint64) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.EnrollUser(context.Background(), userId, courseId)
+}
+
+func UnenrollUser(userId uint64, courseId uint64) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.UnenrollUser(context.Background(), userId, courseId)
+}
+
+func GetCourseByAcronym(a CourseAcronym) (*RetrieveCourse, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetCourseByAcronym(context.Background(), a)
+}
+
+func EnrollUserByAcronym(userId uint64, a CourseAcronym) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.EnrollUserByAcronym(context.Background(), userId, a)
+}
+
+func UnenrollUserByAcronym(userId uint64, a CourseAcronym) (bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return false, err
+    }
+    return c.UnenrollUserByAcronym(context.Background(), userId, a)
+}
+
+func CreateUser(name string, email string, courseId uint64) (*ListUsersUser, bool, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, false, err
+    }
+    return c.CreateUser(context.Background(), name, email, courseId)
+}
+
+func SendPasswordReset(userId uint64) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return err
+    }
+    return c.SendPasswordReset(context.Background(), userId)
+}
+
+func GetSales(q SalesQuery) ([]RetrieveSale, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetSales(context.Background(), q)
+}
+
+func GetTransactions(q SalesQuery) ([]Transaction, error) {
+    c, err := DefaultClient()
+    if err != nil {
+        return nil, err
+    }
+    return c.GetTransactions(context.Background(), q)
+}
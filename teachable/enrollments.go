@@ -0,0 +1,135 @@
+package teachable
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+
+    "github.com/xiam/to"
+)
+
+// Enrolls a user in a course: POST /users/:id/enrollments {"course_id": ...}
+type CreateEnrollment struct {
+    CourseId    uint64  `json:"course_id"`
+}
+
+// Returns the user's active enrollment in the course, or nil if they're not
+// enrolled (or were unenrolled)
+func (c *Client) GetActiveEnrollment(ctx context.Context, userId uint64,
+    courseId uint64) (*ListEnrollmentsEnrollment, error) {
+    enrollments, err := c.GetUserEnrollments(ctx, userId)
+    if err != nil {
+        return nil, fmt.Errorf("Failed fetching enrollments for user %d: %w", userId, err)
+    }
+    for i, e := range enrollments {
+        if e.CourseId == courseId && e.IsActive {
+            return &enrollments[i], nil
+        }
+    }
+    return nil, nil
+}
+
+// Enrolls the user in the course. Returns false if they were already
+// actively enrolled, so it's safe to call again.
+func (c *Client) EnrollUser(ctx context.Context, userId uint64, courseId uint64) (bool, error) {
+    e, err := c.GetActiveEnrollment(ctx, userId, courseId)
+    if err != nil {
+        return false, err
+    }
+    if e != nil {
+        return false, nil
+    }
+
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_USERS, to.String(userId), API_URL_ENROLLMENTS)
+    if err != nil {
+        return false, fmt.Errorf("Failed building request url: %w", err)
+    }
+    data, err := json.Marshal(CreateEnrollment{CourseId: courseId})
+    if err != nil {
+        return false, fmt.Errorf("Failed to marshal enrollment: %w", err)
+    }
+    r := c.doApiRequestContext(ctx, http.MethodPost, u.String(), data, http.StatusCreated,
+        http.StatusOK)
+    if r.Error != nil {
+        return false, fmt.Errorf("Failed enrolling user %d in course %d: %w", userId, courseId,
+            r.Error)
+    }
+    return true, nil
+}
+
+// Unenrolls the user from the course. Returns false if they weren't
+// actively enrolled, so it's safe to call again.
+func (c *Client) UnenrollUser(ctx context.Context, userId uint64, courseId uint64) (bool, error) {
+    e, err := c.GetActiveEnrollment(ctx, userId, courseId)
+    if err != nil {
+        return false, err
+    }
+    if e == nil {
+        return false, nil
+    }
+
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_USERS, to.String(userId), API_URL_ENROLLMENTS,
+        to.String(e.Id), API_URL_UNENROLL)
+    if err != nil {
+        return false, fmt.Errorf("Failed building request url: %w", err)
+    }
+    r := c.doApiRequestContext(ctx, http.MethodPut, u.String(), nil, http.StatusOK,
+        http.StatusNoContent)
+    if r.Error != nil {
+        return false, fmt.Errorf("Failed unenrolling user %d from course %d: %w", userId, courseId,
+            r.Error)
+    }
+    return true, nil
+}
+
+// Finds the course with the given acronym. If the catalog doesn't give its
+// ID, each course is fetched until one matches, since the course list doesn't
+// include friendly URLs.
+func (c *Client) GetCourseByAcronym(ctx context.Context, a CourseAcronym) (*RetrieveCourse, error) {
+    catalog, err := GetCourseCatalog()
+    if err != nil {
+        return nil, err
+    }
+    entry, err := catalog.Get(string(a))
+    if err != nil {
+        return nil, err
+    }
+    if entry.CourseId > 0 {
+        course, err := c.GetCourse(ctx, to.String(entry.CourseId))
+        if err != nil {
+            return nil, fmt.Errorf("Failed fetching course %d: %w", entry.CourseId, err)
+        }
+        return course, nil
+    }
+    courses, err := c.GetAllCourses(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("Failed fetching all courses: %w", err)
+    }
+    for _, l := range courses {
+        course, err := c.GetCourse(ctx, to.String(l.Id))
+        if err != nil {
+            return nil, fmt.Errorf("Failed fetching course %d: %w", l.Id, err)
+        }
+        if course.Acronym == entry.Acronym {
+            return course, nil
+        }
+    }
+    return nil, fmt.Errorf("No course found with acronym: %s", a)
+}
+
+func (c *Client) EnrollUserByAcronym(ctx context.Context, userId uint64, a CourseAcronym) (bool, error) {
+    course, err := c.GetCourseByAcronym(ctx, a)
+    if err != nil {
+        return false, err
+    }
+    return c.EnrollUser(ctx, userId, course.Id)
+}
+
+func (c *Client) UnenrollUserByAcronym(ctx context.Context, userId uint64, a CourseAcronym) (bool, error) {
+    course, err := c.GetCourseByAcronym(ctx, a)
+    if err != nil {
+        return false, err
+    }
+    return c.UnenrollUser(ctx, userId, course.Id)
+}
@@ -0,0 +1,88 @@
+package teachable_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
+)
+
+// Fake school with one user (id=5) who is enrolled in course 7. Enrollment IDs
+// are the course ID times 10.
+func newEnrollmentServer() (*httptest.Server, *map[uint64]bool, *int) {
+	var mutex sync.Mutex
+	active := map[uint64]bool{7: true}
+	changes := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var enrollmentId uint64
+		fmt.Sscanf(r.URL.Path, "/api/v1/users/5/enrollments/%d/unenroll", &enrollmentId)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/users/5/enrollments":
+			var l []map[string]interface{}
+			for id, isActive := range active {
+				l = append(l, map[string]interface{}{"id": id * 10, "user_id": 5, "course_id": id,
+					"primary_course_id": id, "is_active": isActive})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"enrollments": l})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/users/5/enrollments":
+			var e teachable.CreateEnrollment
+			json.NewDecoder(r.Body).Decode(&e)
+			active[e.CourseId] = true
+			changes++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPut && active[enrollmentId/10]:
+			active[enrollmentId/10] = false
+			changes++
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return ts, &active, &changes
+}
+
+func TestClientEnrollAndUnenrollUser(t *testing.T) {
+	ts, active, changes := newEnrollmentServer()
+	defer ts.Close()
+	client := teachable.NewClient(ts.URL, "api-user", "api-password")
+	ctx := context.Background()
+
+	cases := []struct {
+		name        string
+		do          func(uint64, uint64) (bool, error)
+		courseId    uint64
+		wantChanged bool
+		wantActive  bool
+	}{
+		{"EnrollUser", func(u, c uint64) (bool, error) { return client.EnrollUser(ctx, u, c) }, 7, false, true},
+		{"EnrollUser", func(u, c uint64) (bool, error) { return client.EnrollUser(ctx, u, c) }, 8, true, true},
+		{"UnenrollUser", func(u, c uint64) (bool, error) { return client.UnenrollUser(ctx, u, c) }, 7, true, false},
+		{"UnenrollUser", func(u, c uint64) (bool, error) { return client.UnenrollUser(ctx, u, c) }, 7, false, false},
+		{"UnenrollUser", func(u, c uint64) (bool, error) { return client.UnenrollUser(ctx, u, c) }, 9, false, false},
+	}
+	for _, c := range cases {
+		changed, err := c.do(5, c.courseId)
+		if err != nil {
+			t.Errorf("%s(5, %d) returned error: %v", c.name, c.courseId, err)
+			continue
+		}
+		if changed != c.wantChanged {
+			t.Errorf("%s(5, %d) == %t, want %t", c.name, c.courseId, changed, c.wantChanged)
+		}
+		if (*active)[c.courseId] != c.wantActive {
+			t.Errorf("After %s(5, %d) enrollment is active=%t, want %t", c.name, c.courseId,
+				(*active)[c.courseId], c.wantActive)
+		}
+	}
+	if *changes != 2 {
+		t.Errorf("Server saw %d enrollment changes, want 2", *changes)
+	}
+}
@@ -0,0 +1,177 @@
+package teachable
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Webhook event types, as sent in the "type" field
+const (
+	EVENT_USER_CREATED             = "User.created"
+	EVENT_USER_UPDATED             = "User.updated"
+	EVENT_ENROLLMENT_CREATED       = "Enrollment.created"
+	EVENT_ENROLLMENT_COMPLETED     = "Enrollment.completed"
+	EVENT_ENROLLMENT_DISABLED      = "Enrollment.disabled"
+	EVENT_SALE_CREATED             = "Sale.created"
+	EVENT_TRANSACTION_CREATED      = "Transaction.created"
+	EVENT_TRANSACTION_REFUNDED     = "Transaction.refunded"
+	EVENT_COMMENT_CREATED          = "Comment.created"
+	EVENT_LECTURE_PROGRESS_CREATED = "LectureProgress.created"
+	EVENT_ABANDONED_CART           = "AbandonedOrder.created"
+)
+
+// Every parsed webhook event implements this
+type WebhookEvent interface {
+	EventType() string
+}
+
+// Reads the event type and returns the matching struct:
+//
+//	User.created               *NewStudent
+//	User.updated               *StudentUpdated
+//	Enrollment.created         *StudentEnrolled
+//	Enrollment.completed       *EnrollmentCompleted
+//	Enrollment.disabled        *StudentCancelled
+//	Sale.created               *SaleCreated
+//	Transaction.created        *TransactionCreated
+//	Transaction.refunded       *TransactionRefunded
+//	Comment.created            *CommentCreated
+//	LectureProgress.created    *LectureProgressCreated
+//	AbandonedOrder.created     *AbandonedCart
+//
+// Any other type comes back as *RawEvent.
+func ParseWebhookEvent(data []byte) (WebhookEvent, error) {
+	var e RawEvent
+	err := json.Unmarshal(data, &e)
+	if err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling webhook event: %w", err)
+	}
+	if e.Type == "" {
+		return nil, fmt.Errorf("Webhook event has no type")
+	}
+
+	var m WebhookEvent
+	switch e.Type {
+	case EVENT_USER_CREATED:
+		m = &NewStudent{}
+	case EVENT_USER_UPDATED:
+		m = &StudentUpdated{}
+	case EVENT_ENROLLMENT_CREATED:
+		m = &StudentEnrolled{}
+	case EVENT_ENROLLMENT_COMPLETED:
+		m = &EnrollmentCompleted{}
+	case EVENT_ENROLLMENT_DISABLED:
+		m = &StudentCancelled{}
+	case EVENT_SALE_CREATED:
+		m = &SaleCreated{}
+	case EVENT_TRANSACTION_CREATED:
+		m = &TransactionCreated{}
+	case EVENT_TRANSACTION_REFUNDED:
+		m = &TransactionRefunded{}
+	case EVENT_COMMENT_CREATED:
+		m = &CommentCreated{}
+	case EVENT_LECTURE_PROGRESS_CREATED:
+		m = &LectureProgressCreated{}
+	case EVENT_ABANDONED_CART:
+		m = &AbandonedCart{}
+	default:
+		return &e, nil
+	}
+	err = json.Unmarshal(data, m)
+	if err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling '%s' webhook event: %w", e.Type, err)
+	}
+	return m, nil
+}
+
+// Event with a type we don't model. Object is left as raw JSON.
+type RawEvent struct {
+	Type        string          `json:"type"`
+	Id          float64         `json:"id"`
+	Created     string          `json:"created"`
+	HookEventId float64         `json:"hook_event_id"`
+	Object      json.RawMessage `json:"object"`
+}
+
+func (e *RawEvent) EventType() string         { return e.Type }
+func (s *NewStudent) EventType() string       { return s.Type }
+func (s *StudentUpdated) EventType() string   { return s.Type }
+func (s *StudentEnrolled) EventType() string  { return s.Type }
+func (s *StudentCancelled) EventType() string { return s.Type }
+func (s *CommentCreated) EventType() string   { return s.Type }
+
+// Student completed a course. Same fields as StudentEnrolled.
+type EnrollmentCompleted struct {
+	StudentEnrolled
+}
+
+// Student bought a product
+type SaleCreated struct {
+	Type        string       `json:"type"`
+	Id          float64      `json:"id"`
+	Created     string       `json:"created"`
+	HookEventId float64      `json:"hook_event_id"`
+	Object      RetrieveSale `json:"object"`
+}
+
+func (s *SaleCreated) EventType() string { return s.Type }
+
+// Student was charged (including each payment of a plan)
+type TransactionCreated struct {
+	Type        string      `json:"type"`
+	Id          float64     `json:"id"`
+	Created     string      `json:"created"`
+	HookEventId float64     `json:"hook_event_id"`
+	Object      Transaction `json:"object"`
+}
+
+func (s *TransactionCreated) EventType() string { return s.Type }
+
+// A charge was refunded. Same fields as TransactionCreated.
+type TransactionRefunded TransactionCreated
+
+func (s *TransactionRefunded) EventType() string { return s.Type }
+
+// Student completed a lecture
+type LectureProgressCreated struct {
+	Type        string                `json:"type"`
+	Id          float64               `json:"id"`
+	Created     string                `json:"created"`
+	HookEventId float64               `json:"hook_event_id"`
+	Object      LectureProgressObject `json:"object"`
+}
+
+func (s *LectureProgressCreated) EventType() string { return s.Type }
+
+type LectureProgressObject struct {
+	Id        uint64        `json:"id"`
+	CreatedAt string        `json:"created_at"`
+	CourseId  uint64        `json:"course_id"`
+	LectureId uint64        `json:"lecture_id"`
+	UserId    uint64        `json:"user_id"`
+	User      ListUsersUser `json:"user"`
+}
+
+// Student started checking out but didn't pay
+type AbandonedCart struct {
+	Type        string              `json:"type"`
+	Id          float64             `json:"id"`
+	Created     string              `json:"created"`
+	HookEventId float64             `json:"hook_event_id"`
+	Object      AbandonedCartObject `json:"object"`
+}
+
+func (s *AbandonedCart) EventType() string { return s.Type }
+
+type AbandonedCartObject struct {
+	Id          uint64        `json:"id"`
+	CreatedAt   string        `json:"created_at"`
+	Email       string        `json:"email"`
+	Name        string        `json:"name"`
+	ProductId   uint64        `json:"product_id"`
+	ProductName string        `json:"product_name"`
+	Price       int64         `json:"price"` // in cents
+	Currency    string        `json:"currency"`
+	CouponCode  string        `json:"coupon_code"`
+	User        ListUsersUser `json:"user"`
+}
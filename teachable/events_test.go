@@ -0,0 +1,68 @@
+package teachable_test
+
+import (
+	"fmt"
+	"testing"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
+)
+
+func TestParseWebhookEvent(t *testing.T) {
+	event := func(typ string, object string) []byte {
+		return []byte(fmt.Sprintf(`{"type":%q,"id":7,"created":"2020-03-01T12:00:00Z","hook_event_id":3,"object":%s}`,
+			typ, object))
+	}
+	cases := []struct {
+		dataIn    []byte
+		wantType  string
+		wantError bool
+	}{
+		{event("User.created", `{"id":12,"email":"a@example.com","school_id":1}`), "*teachable.NewStudent", false},
+		{event("User.updated", `{"id":12,"email":"a@example.com"}`), "*teachable.StudentUpdated", false},
+		{event("Enrollment.created", `{"id":4,"course_id":9,"user":{"id":12}}`), "*teachable.StudentEnrolled", false},
+		{event("Enrollment.completed", `{"id":4,"course_id":9,"user":{"id":12}}`), "*teachable.EnrollmentCompleted", false},
+		{event("Enrollment.disabled", `{"id":4,"course_id":9,"user":{"id":12}}`), "*teachable.StudentCancelled", false},
+		{event("Sale.created", `{"id":5,"price":19700}`), "*teachable.SaleCreated", false},
+		{event("Transaction.created", `{"id":6,"charge":19700,"user":{"id":12}}`), "*teachable.TransactionCreated", false},
+		{event("Transaction.refunded", `{"id":6,"amount_refunded":19700}`), "*teachable.TransactionRefunded", false},
+		{event("Comment.created", `{"id":8,"user":{"id":12}}`), "*teachable.CommentCreated", false},
+		{event("LectureProgress.created", `{"id":9,"lecture_id":3}`), "*teachable.LectureProgressCreated", false},
+		{event("AbandonedOrder.created", `{"id":10,"email":"a@example.com"}`), "*teachable.AbandonedCart", false},
+		{event("Quiz.completed", `{"id":11}`), "*teachable.RawEvent", false},
+		{[]byte(`{"id":7,"object":{}}`), "", true},
+		{[]byte(`not json`), "", true},
+	}
+	for _, c := range cases {
+		e, err := teachable.ParseWebhookEvent(c.dataIn)
+		gotError := err != nil
+		if gotError != c.wantError {
+			t.Errorf("ParseWebhookEvent(%s) == (error=%t), want (error=%t), got err: %v",
+				c.dataIn, gotError, c.wantError, err)
+			continue
+		}
+		if gotError {
+			continue
+		}
+		if gotType := fmt.Sprintf("%T", e); gotType != c.wantType {
+			t.Errorf("ParseWebhookEvent(%s) == %s, want %s", c.dataIn, gotType, c.wantType)
+		}
+	}
+
+	e, err := teachable.ParseWebhookEvent(event("Enrollment.completed", `{"id":4,"course_id":9}`))
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() returned error: %v", err)
+	}
+	if m := e.(*teachable.EnrollmentCompleted); m.EventType() != teachable.EVENT_ENROLLMENT_COMPLETED ||
+		m.Object.CourseId != "9" {
+		t.Errorf("EnrollmentCompleted == (type=%q, course=%q), want (type=%q, course=%q)",
+			m.EventType(), m.Object.CourseId, teachable.EVENT_ENROLLMENT_COMPLETED, "9")
+	}
+
+	e, err = teachable.ParseWebhookEvent(event("Enrollment.disabled", `{"id":4,"course_id":9}`))
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() returned error: %v", err)
+	}
+	if m := e.(*teachable.StudentCancelled); m.Object.CourseId != "9" {
+		t.Errorf("StudentCancelled course == %q, want %q", m.Object.CourseId, "9")
+	}
+}
@@ -0,0 +1,215 @@
+package teachable
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/url"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/xiam/to"
+)
+
+// Product a sale was for
+type RetrieveSaleProduct struct {
+    Id          uint64      `json:"id"`
+    Name        string      `json:"name"`
+    CourseId    uint64      `json:"course_id"`
+    Price       uint64      `json:"price"` // in cents
+    Currency    string      `json:"currency"`
+    IsPublished bool        `json:"is_published"`
+}
+
+// Pricing plan a sale was made with
+type RetrieveSalePricingPlan struct {
+    Id                      uint64      `json:"id"`
+    Name                    string      `json:"name"`
+    ProductId               uint64      `json:"product_id"`
+    Price                   uint64      `json:"price"` // in cents
+    Currency                string      `json:"currency"`
+    Frequency               string      `json:"frequency"` // e.g. "one_time" or "monthly"
+    NumberOfPayments        uint32      `json:"num_payments"`
+    FreeTrialLength         uint32      `json:"free_trial_length"`
+}
+
+// A charge on a sale. Payment plans get one for each payment.
+type Transaction struct {
+    Id              uint64          `json:"id"`
+    SaleId          uint64          `json:"sale_id"`
+    UserId          uint64          `json:"user_id"`
+    ProductId       uint64          `json:"product_id"`
+    PricingPlanId   uint64          `json:"pricing_plan_id"`
+    ChargeId        string          `json:"charge_id"` // payment processor's ID, e.g. Stripe's ch_...
+    Amount          int64           `json:"charge"` // in cents
+    FinalPrice      int64           `json:"final_price"` // in cents, after coupons
+    AmountRefunded  int64           `json:"amount_refunded"` // in cents
+    Revenue         int64           `json:"revenue"` // in cents, after fees
+    Currency        string          `json:"currency"`
+    Status          string          `json:"status"`
+    CreatedAt       string          `json:"created_at"`
+    PurchasedAt     string          `json:"purchased_at"`
+    RefundedAt      string          `json:"refunded_at"`
+    User            ListUsersUser   `json:"user"`
+}
+
+// Returns what was kept from the charge, in cents
+func (t *Transaction) NetAmount() int64 {
+    return t.Amount - t.AmountRefunded
+}
+
+func (t *Transaction) IsRefunded() bool {
+    return t.AmountRefunded > 0
+}
+
+// A refund of (part of) a transaction
+type Refund struct {
+    Id              uint64      `json:"id"`
+    SaleId          uint64      `json:"sale_id"`
+    TransactionId   uint64      `json:"transaction_id"`
+    Amount          int64       `json:"amount"` // in cents
+    Currency        string      `json:"currency"`
+    Reason          string      `json:"reason"`
+    CreatedAt       string      `json:"created_at"`
+}
+
+// Filters for listing sales. Zero values are ignored.
+type SalesQuery struct {
+    After       time.Time // created at or after
+    Before      time.Time // created before
+    ProductId   uint64
+    UserId      uint64
+}
+
+func (q SalesQuery) values() *url.Values {
+    v := url.Values{}
+    if !q.After.IsZero() {
+        v.Set(API_PARAM_CREATED_AFTER, q.After.UTC().Format(time.RFC3339))
+    }
+    if !q.Before.IsZero() {
+        v.Set(API_PARAM_CREATED_BEFORE, q.Before.UTC().Format(time.RFC3339))
+    }
+    if q.ProductId > 0 {
+        v.Set(API_PARAM_PRODUCT_ID, to.String(q.ProductId))
+    }
+    if q.UserId > 0 {
+        v.Set(API_PARAM_USER_ID, to.String(q.UserId))
+    }
+    return &v
+}
+
+// ListSales response from endpoint: https://<account_id>.teachable.com/api/v1/sales
+type ListSales struct {
+    Sales       []RetrieveSale      `json:"sales"`
+    Metadata    ListUsersMetadata   `json:"meta"`
+}
+
+func (l *ListSales) TotalResults() uint64 {
+    return l.Metadata.Total
+}
+
+func (l *ListSales) TotalPages() int {
+    return to.Int(l.Metadata.NumberOfPages)
+}
+
+// Returns every sale matching the query, oldest first. Any page that fails
+// is an error, since a partial list would throw off revenue totals.
+func (c *Client) GetSales(ctx context.Context, q SalesQuery) ([]RetrieveSale, error) {
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_SALES)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+
+    results, err := c.fetchAllEndpointDataAsync(ctx, u, q.values(), &ListSales{})
+    if errors.Is(err, ErrNotFound) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("Failed fetching sales: %w", err)
+    }
+
+    var sales []RetrieveSale
+    var errorStrings []string
+    for _, r := range results {
+        if r.Error != nil {
+            errorStrings = append(errorStrings, r.Error.Error())
+            continue
+        }
+        l := &ListSales{}
+        err = json.Unmarshal(r.Data, l)
+        if err != nil {
+            errorStrings = append(errorStrings, fmt.Sprintf("Failed to unmarshal response data from '%s': %s",
+                r.Url, err))
+            continue
+        }
+        sales = append(sales, l.Sales...)
+    }
+    if len(errorStrings) > 0 {
+        return nil, fmt.Errorf("Failed fetching %d of %d pages of sales: %s", len(errorStrings),
+            len(results), strings.Join(errorStrings, "; "))
+    }
+    sort.Slice(sales, func(i, j int) bool { return sales[i].Id < sales[j].Id })
+    return sales, nil
+}
+
+// Returns the transactions of every sale matching the query
+func (c *Client) GetTransactions(ctx context.Context, q SalesQuery) ([]Transaction, error) {
+    sales, err := c.GetSales(ctx, q)
+    if err != nil {
+        return nil, err
+    }
+    var l []Transaction
+    for _, s := range sales {
+        for _, t := range s.Transactions {
+            if t.SaleId == 0 {
+                t.SaleId = s.Id
+            }
+            l = append(l, t)
+        }
+    }
+    return l, nil
+}
+
+// Revenue totals for one currency, in cents
+type RevenueSummary struct {
+    Currency        string
+    SaleCount       int
+    PaymentCount    int
+    RefundCount     int
+    Gross           int64
+    Refunded        int64
+    Net             int64
+}
+
+// Totals the sales' transactions by currency. Refunds are taken from each
+// transaction's refunded amount, so sale refund lists aren't counted twice.
+func SummarizeRevenue(sales []RetrieveSale) map[string]*RevenueSummary {
+    m := make(map[string]*RevenueSummary)
+    get := func(currency string) *RevenueSummary {
+        currency = strings.ToUpper(currency)
+        if _, ok := m[currency]; !ok {
+            m[currency] = &RevenueSummary{Currency: currency}
+        }
+        return m[currency]
+    }
+    for _, s := range sales {
+        get(s.Currency).SaleCount += 1
+        for _, t := range s.Transactions {
+            currency := t.Currency
+            if currency == "" {
+                currency = s.Currency
+            }
+            r := get(currency)
+            r.PaymentCount += 1
+            r.Gross += t.Amount
+            if t.IsRefunded() {
+                r.RefundCount += 1
+                r.Refunded += t.AmountRefunded
+            }
+            r.Net += t.NetAmount()
+        }
+    }
+    return m
+}
@@ -0,0 +1,80 @@
+package teachable_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
+)
+
+func TestClientGetSales(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v1/sales" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if q.Get("product_id") == "99" {
+			fmt.Fprint(w, `{"sales":[],"meta":{"page":1,"total":0,"number_of_pages":0}}`)
+			return
+		}
+		if q.Get("created_after") != "2020-01-01T00:00:00Z" || q.Get("product_id") != "3" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page := q.Get("page")
+		fmt.Fprintf(w, `{"sales":[{"id":%s,"price":10000,"currency":"usd","transactions":[`+
+			`{"id":%s1,"charge":5000,"currency":"usd"},`+
+			`{"id":%s2,"charge":5000,"amount_refunded":2000,"currency":"usd"}]}],`+
+			`"meta":{"page":%s,"total":2,"number_of_pages":2}}`, page, page, page, page)
+	}))
+	defer ts.Close()
+	client := teachable.NewClient(ts.URL, "api-user", "api-password")
+	ctx := context.Background()
+	after := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		queryIn   teachable.SalesQuery
+		wantCount int
+		wantError bool
+	}{
+		{teachable.SalesQuery{After: after, ProductId: 3}, 2, false},
+		{teachable.SalesQuery{ProductId: 99}, 0, false},
+		{teachable.SalesQuery{ProductId: 3}, 0, true},
+	}
+	for _, c := range cases {
+		l, err := client.GetSales(ctx, c.queryIn)
+		gotError := err != nil
+		if gotError != c.wantError {
+			t.Errorf("GetSales(%+v) == (error=%t), want (error=%t), got err: %v",
+				c.queryIn, gotError, c.wantError, err)
+			continue
+		}
+		if len(l) != c.wantCount {
+			t.Errorf("GetSales(%+v) returned %d sales, want %d", c.queryIn, len(l), c.wantCount)
+		}
+	}
+
+	sales, err := client.GetSales(ctx, teachable.SalesQuery{After: after, ProductId: 3})
+	if err != nil {
+		t.Fatalf("GetSales() returned error: %v", err)
+	}
+	if sales[0].Id != 1 || sales[1].Id != 2 {
+		t.Errorf("GetSales() returned sales %d, %d, want 1, 2", sales[0].Id, sales[1].Id)
+	}
+	s := teachable.SummarizeRevenue(sales)["USD"]
+	if s == nil || s.SaleCount != 2 || s.PaymentCount != 4 || s.RefundCount != 2 ||
+		s.Gross != 20000 || s.Refunded != 4000 || s.Net != 16000 {
+		t.Errorf("SummarizeRevenue() == %+v, want 2 sales, 4 payments, 2 refunds, gross 20000,"+
+			" refunded 4000, net 16000", s)
+	}
+
+	l, err := client.GetTransactions(ctx, teachable.SalesQuery{After: after, ProductId: 3})
+	if err != nil || len(l) != 4 || l[0].SaleId != 1 {
+		t.Errorf("GetTransactions() == (%d transactions, error=%v), want 4 with sale IDs set", len(l), err)
+	}
+}
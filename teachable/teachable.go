@@ -4,13 +4,13 @@ package teachable
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "errors"
     "fmt"
     "io/ioutil"
     "log"
     "os"
-    "net/http"
     "net/url"
     //"math"
     "strings"
@@ -42,8 +42,14 @@ const (
     API_URL_HOOKS = "/hooks"
     API_URL_ADMIN = "/admin"
     API_URL_INFORMATION = "/information"
+    API_URL_UNENROLL = "/unenroll"
+    API_URL_RESET_PASSWORD = "/reset_password"
     //API_PARAM_ENROLLED_IN = "enrolled_in_specific%5B%5D"
     API_PARAM_ENROLLED_IN = "enrolled_in_specific[]"
+    API_PARAM_CREATED_AFTER = "created_after"
+    API_PARAM_CREATED_BEFORE = "created_before"
+    API_PARAM_PRODUCT_ID = "product_id"
+    API_PARAM_USER_ID = "user_id"
 
 )
 
@@ -63,6 +69,11 @@ type SecretsConfig struct {
     ApiUrl      string `yaml:"API_URL"`
     ApiUser     string `yaml:"API_USER"`
     ApiPassword string `yaml:"API_PASSWORD"`
+    // Optional webhook checks, see NewWebhookVerifierFromSecrets()
+    WebhookSecret           string      `yaml:"WEBHOOK_SECRET"`
+    WebhookSigningSecret    string      `yaml:"WEBHOOK_SIGNING_SECRET"`
+    WebhookAllowedIps       []string    `yaml:"WEBHOOK_ALLOWED_IPS"`
+    WebhookMaxAge           string      `yaml:"WEBHOOK_MAX_AGE"` // e.g. "15m"
 }
 var SavedSecretsConfig *SecretsConfig
 
@@ -72,6 +83,10 @@ func (c *SecretsConfig) GetSecrets(filePath string) (error) {
         c.ApiUrl = SavedSecretsConfig.ApiUrl
         c.ApiUser = SavedSecretsConfig.ApiUser
         c.ApiPassword = SavedSecretsConfig.ApiPassword
+        c.WebhookSecret = SavedSecretsConfig.WebhookSecret
+        c.WebhookSigningSecret = SavedSecretsConfig.WebhookSigningSecret
+        c.WebhookAllowedIps = SavedSecretsConfig.WebhookAllowedIps
+        c.WebhookMaxAge = SavedSecretsConfig.WebhookMaxAge
         return nil
     }
 
@@ -98,38 +113,25 @@ type ApiLoginCredentials struct {
     Password    string
 }
 
-// Returns API URL, user login, and password
+// Returns API URL, user login, and password from the default client.
+// Prefer NewClientFromSecrets() or DefaultClient() for new code.
 func GetApiCredentials() (string, *ApiLoginCredentials) {
-    var c SecretsConfig
-    err := c.GetSecrets(SecretsFilePath)
+    c, err := DefaultClient()
     if err != nil {
-        log.Fatalf("Could not open YAML secrets file: %s", err.Error())
+        log.Printf("Could not load API credentials: %s", err)
+        return "", &ApiLoginCredentials{}
     }
-
-    apiUrl := c.ApiUrl
-    apiUser := c.ApiUser
-    apiPassword := c.ApiPassword
-
-    credentials := ApiLoginCredentials{User: apiUser, Password: apiPassword}
-    //log.Printf("Here with apiCred: %#v", credentials)
-
-    // Add suffix to url
-    url := fmt.Sprintf("%s%s", apiUrl, API_URL_SUFFIX)
-
-    return url, &credentials
+    return c.ApiUrl, &ApiLoginCredentials{User: c.User, Password: c.Password}
 }
 
-// Returns raw API URL only (with no suffix)
+// Returns raw API URL only (with no suffix) from the default client
 func GetRawApiUrl() string {
-    var c SecretsConfig
-    err := c.GetSecrets(SecretsFilePath)
+    c, err := DefaultClient()
     if err != nil {
-        log.Fatalf("Could not open YAML secrets file: %s", err.Error())
+        log.Printf("Could not load API url: %s", err)
+        return ""
     }
-
-    apiUrl := c.ApiUrl
-
-    return apiUrl
+    return c.SchoolUrl
 }
 
 func BuildRequestUrl(apiUrl string, requestSuffix string, pathParts ...string) (*url.URL, error) {
@@ -197,204 +199,66 @@ type ApiRequestResult struct {
     Data []byte
     Error error
     Url string
+    StatusCode int
 }
 
+// Does a GET request with the given login. Prefer a Client for new code,
+// which reuses its HTTP client and has a request timeout.
 func DoApiRequest(requestUrl string, apiCredentials *ApiLoginCredentials) (*ApiRequestResult) {
-    r := &ApiRequestResult{Data: nil, Error: nil, Url: requestUrl}
-
-    if DEBUG {
-        log.Println(fmt.Sprintf("Querying url: %s", requestUrl))
-    }
-
-    client := &http.Client{}
-    req, err := http.NewRequest("GET", requestUrl, nil)
-    if err != nil {
-        r.Error = err
-        return r
-    }
-    //req.Header.Set("Content-Type", "application/json")
-    req.Header.Set("User-Agent", USER_AGENT)
-    //req.Header.Set("Api-Token", apiToken)
-
-    req.SetBasicAuth(apiCredentials.User, apiCredentials.Password)
-
-    resp, err := client.Do(req)
-    if err != nil {
-        r.Error = err
-        return r
-    }
-
-    defer resp.Body.Close()
-
-    //log.Println("response Status:", resp.Status)
-    //log.Println("response Headers:", resp.Header)
-
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        r.Error = err
-        return r
-    }
-    //log.Println("Got body: ", to.String(body))
-
-    if resp.StatusCode != 200 {
-        log.Printf("%#v", resp)
-        r.Error = fmt.Errorf("Got '%s' response with status code: %d, expected: %d", resp.Status, resp.StatusCode, 200)
-        return r
-    }
-    r.Data = []byte(body)
-    return r
+    c := NewClient("", apiCredentials.User, apiCredentials.Password)
+    return c.doApiRequestGet(context.Background(), requestUrl)
 }
 
-/* This function will asynchronously fetch all pages of data from the
- * endpoint. The list response interface, r, given as a parameter will
- * have it's metadata populated, but the list member within will not be
- * poulated and must be filled using the API result array returned.
- */
-// Refs:
-// - https://guzalexander.com/2013/12/06/golang-channels-tutorial.html
-// - https://gist.github.com/montanaflynn/ea4b92ed640f790c4b9cee36046a5383
+// Fetches all pages of data from the endpoint with the given login. See
+// Client.fetchAllEndpointDataAsync().
 func FetchAllEndpointDataAsync(u *url.URL, q *url.Values, r ListResponse,
     apiCredentials *ApiLoginCredentials) ([]ApiRequestResult, error) {
-    // Set initial offset and limit for discovery request
-    q.Set("page", "1")
-    u.RawQuery = q.Encode()
-    resp := DoApiRequest(u.String(), apiCredentials)
-    if resp.Error != nil {
-        return nil, resp.Error
-    }
-
-    // Unmarshal the message metedata
-    err := json.Unmarshal(resp.Data, r)
-    if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
-    }
-    total := r.TotalResults()
-    if total < 1 {
-        msg := fmt.Sprintf("Could not find any endpoint data with query: %#v", q)
-        return nil, errors.New(msg)
-    }
-    totalPages := r.TotalPages()
-
-    // make a slice to hold the results we're expecting
-    var results []ApiRequestResult
-    //results = append(results, *resp) // add initial page 1 result first
-
-    if DEBUG {
-        log.Printf("Fetching %d pages from endpoint '%s' with %d total results.",
-            totalPages, u.Path, total)
-    }
-    q.Set("page", "2")
-    u.RawQuery = q.Encode()
-
-    // Use goroutine to request results concurrently
-    // this buffered channel will block at the concurrency limit
-    semaphoreChan := make(chan struct{}, REQUEST_CONCURRENCY_LIMIT )
-    // this channel will not block and collect the http request results
-    resultsChan := make(chan *ApiRequestResult)
-
-    // make sure we close these channels when we're done with them
-    defer func() {
-        close(semaphoreChan)
-        close(resultsChan)
-    }()
-
-    for i := 2; i <= totalPages; i++ {
-        //log.Printf("Do request for page %d", i)
-        go func(page int) {
-            // this sends an empty struct into the semaphoreChan which
-            // is basically saying add one to the limit, but when the
-            // limit has been reached block until there is room
-            semaphoreChan <- struct{}{}
-
-            // Build the request URL string
-            requestUrl := RegexPageParameter.ReplaceAllString(u.String(),
-                "$1=" + to.String(page))
-            if DEBUG && DEBUG_VERBOSE {
-                log.Printf("Here doing request for page=%d, url=%s", page, requestUrl)
-            }
-
-            // send the request and put the response in a result struct
-            // along with the index so we can sort them later along with
-            // any error that might have occoured
-            result := DoApiRequest(requestUrl, apiCredentials)
-            // now we can send the result struct through the resultsChan
-            resultsChan <- result
-
-            // once we're done it's we read from the semaphoreChan which
-            // has the effect of removing one from the limit and allowing
-            // another goroutine to start
-            <-semaphoreChan
-            //log.Printf("Finished request for page=%d, offset=%d", page, offset)
-        }(i)
-    }
-
-    if DEBUG {
-        log.Printf("Listening for %d channel results...", totalPages)
-    }
-    // start listening for any results over the resultsChan
-    // once we get a result append it to the result slice
-    results = append(results, *resp) // first add the initial result
-    for {
-        // if we've reached the expected amount of urls then stop
-        if len(results) >= totalPages {
-            break
-        }
-        result := <-resultsChan
-        results = append(results, *result)
-    }
-    return results, nil
+    c := NewClient("", apiCredentials.User, apiCredentials.Password)
+    return c.fetchAllEndpointDataAsync(context.Background(), u, q, r)
 }
 
-func GetAllUsers() ([]ListUsersUser, error) {
+func (c *Client) GetAllUsers(ctx context.Context) ([]ListUsersUser, error) {
     var p QueryParameters
-    l, err := GetUsersAsync(p)
+    l, err := c.GetUsersAsync(ctx, p)
     if err != nil {
-        return nil, fmt.Errorf("Failed fetching all users: %#v", err)
+        return nil, fmt.Errorf("Failed fetching all users: %w", err)
     }
     return l.Users, nil
 }
 
-func GetCourseStudents(courseId uint64) ([]ListUsersUser, error) {
+func (c *Client) GetCourseStudents(ctx context.Context, courseId uint64) ([]ListUsersUser, error) {
     var p QueryParameters
     p.CourseId = courseId
-    l, err := GetUsersAsync(p)
+    l, err := c.GetUsersAsync(ctx, p)
     if err != nil {
-        return nil, fmt.Errorf("Failed fetching students in course %d: %#v", courseId, err)
+        return nil, fmt.Errorf("Failed fetching students in course %d: %w", courseId, err)
     }
     return l.Users, nil
 }
 
-func GetUsersAsync(params QueryParameters) (*ListUsers, error) {
-    /*
-    if (!params.FetchAll) {
-        return GetUsers(params)
-    }
-    */
-
-    apiUrl, apiCredentials := GetApiCredentials()
+func (c *Client) GetUsersAsync(ctx context.Context, params QueryParameters) (*ListUsers, error) {
     result := &ListUsers{}
     var resultList []ListUsersUser
 
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_USERS)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_USERS)
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
     // Build request query variables 
     q, err := BuildQueryWithParams(params)
     if err != nil {
-        return nil, fmt.Errorf("Failed parsing query parameters '%#v': %s", params, err)
+        return nil, fmt.Errorf("Failed parsing query parameters '%#v': %w", params, err)
     }
 
     // Fetch all endpoint data asynchronously
-    results, err := FetchAllEndpointDataAsync(u, q, result, apiCredentials)
+    results, err := c.fetchAllEndpointDataAsync(ctx, u, q, result)
     if err != nil {
-        return nil, fmt.Errorf("Failed fetching all endpoint data asynchronously: %s", err)
+        return nil, fmt.Errorf("Failed fetching all endpoint data asynchronously: %w", err)
     }
 
     // Receive results from the channel and unmarshal them
-    //log.Printf("Unpacking all %d responses...", len(results))
     errorCount := 0
     studentUrlByEmail := make(map[string]string) // checking for errors
     var errorStrings []string
@@ -438,21 +302,18 @@ func GetUsersAsync(params QueryParameters) (*ListUsers, error) {
     return result, nil
 }
 
-func GetUser(params QueryParameters) (*ListUsersUser, error) {
-    apiUrl, apiCredentials := GetApiCredentials()
-
+func (c *Client) GetUser(ctx context.Context, params QueryParameters) (*ListUsersUser, error) {
     if params.Id == 0 {
         return nil, fmt.Errorf("No user ID specified")
     }
 
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_USERS, to.String(params.Id))
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_USERS, to.String(params.Id))
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
-    requestUrl := u.String()
-    result := DoApiRequest(requestUrl, apiCredentials)
+    result := c.doApiRequestGet(ctx, u.String())
     if result.Error != nil {
         return nil, result.Error
     }
@@ -461,24 +322,22 @@ func GetUser(params QueryParameters) (*ListUsersUser, error) {
     r := &ListUsersUser{}
     err = json.Unmarshal(result.Data, &r)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
 
     return r, nil
 }
 
-func GetUserByEmail(email string) (*ListUsersUser, error) {
+func (c *Client) GetUserByEmail(ctx context.Context, email string) (*ListUsersUser, error) {
     var p QueryParameters
     p.Email = strings.ToLower(email)
 
-    r, err := GetUsersAsync(p)
+    r, err := c.GetUsersAsync(ctx, p)
     if err != nil {
-        msg := fmt.Sprintf("Failed to get user '%s': %s", email, err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed to get user '%s': %w", email, err)
     }
     if r.Metadata.Total < 1 || len(r.Users) < 1 {
-        msg := fmt.Sprintf("No users found for: %s", email)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("%w: no users found for: %s", ErrNotFound, email)
     }
     if r.Metadata.Total > 1 || len(r.Users) > 1 {
         msg := fmt.Sprintf("Found multiple users for: %s", email)
@@ -489,47 +348,41 @@ func GetUserByEmail(email string) (*ListUsersUser, error) {
     return &s, nil
 }
 
-func GetUserById(id uint64) (*ListUsersUser, error) {
+func (c *Client) GetUserById(ctx context.Context, id uint64) (*ListUsersUser, error) {
     var p QueryParameters
-    p.Id = to.Uint64(id)
+    p.Id = id
 
-    r, err := GetUser(p)
+    r, err := c.GetUser(ctx, p)
     if err != nil {
-        msg := fmt.Sprintf("Failed to get user %s: %s", id, err)
-        return nil, errors.New(msg)
+        return nil, fmt.Errorf("Failed to get user %d: %w", id, err)
     }
 
     return r, nil
 }
 
-func GetUserProfileUrlById(id uint64) string {
-    apiUrl := GetRawApiUrl()
-    url := fmt.Sprintf("%s%s%s/%s%s", apiUrl, API_URL_ADMIN, API_URL_USERS,
+func (c *Client) GetUserProfileUrlById(id uint64) string {
+    url := fmt.Sprintf("%s%s%s/%s%s", c.SchoolUrl, API_URL_ADMIN, API_URL_USERS,
         to.String(id), API_URL_INFORMATION)
     return url
 }
 
-func GetUserProfileUrlByEmail(email string) (string, error) {
-    c, err := GetUserByEmail(email)
+func (c *Client) GetUserProfileUrlByEmail(ctx context.Context, email string) (string, error) {
+    u, err := c.GetUserByEmail(ctx, email)
     if err != nil {
-        msg := fmt.Sprintf("Failed to get profile url: %s", err)
-        return "", errors.New(msg)
+        return "", fmt.Errorf("Failed to get profile url: %w", err)
     }
-    return GetUserProfileUrlById(c.Id), nil
+    return c.GetUserProfileUrlById(u.Id), nil
 }
 
-func GetUserEnrollments(id uint64) ([]ListEnrollmentsEnrollment, error) {
-    apiUrl, apiCredentials := GetApiCredentials()
-
+func (c *Client) GetUserEnrollments(ctx context.Context, id uint64) ([]ListEnrollmentsEnrollment, error) {
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_USERS, to.String(id),
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_USERS, to.String(id),
         API_URL_ENROLLMENTS)
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
-    requestUrl := u.String()
-    result := DoApiRequest(requestUrl, apiCredentials)
+    result := c.doApiRequestGet(ctx, u.String())
     if result.Error != nil {
         return nil, result.Error
     }
@@ -538,23 +391,20 @@ func GetUserEnrollments(id uint64) ([]ListEnrollmentsEnrollment, error) {
     r := &ListEnrollments{}
     err = json.Unmarshal(result.Data, &r)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
 
     return r.Enrollments, nil
 }
 
-func GetCourse(id string) (*RetrieveCourse, error) {
-    apiUrl, apiCredentials := GetApiCredentials()
-
+func (c *Client) GetCourse(ctx context.Context, id string) (*RetrieveCourse, error) {
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_COURSES, to.String(id))
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_COURSES, id)
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
-    requestUrl := u.String()
-    result := DoApiRequest(requestUrl, apiCredentials)
+    result := c.doApiRequestGet(ctx, u.String())
     if result.Error != nil {
         return nil, result.Error
     }
@@ -563,23 +413,20 @@ func GetCourse(id string) (*RetrieveCourse, error) {
     r := &RetrieveCourse{}
     err = json.Unmarshal(result.Data, &r)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
 
     return r, nil
 }
 
-func GetAllCourses() ([]ListCoursesCourse, error) {
-    apiUrl, apiCredentials := GetApiCredentials()
-
+func (c *Client) GetAllCourses(ctx context.Context) ([]ListCoursesCourse, error) {
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_COURSES)
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_COURSES)
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
-    requestUrl := u.String()
-    result := DoApiRequest(requestUrl, apiCredentials)
+    result := c.doApiRequestGet(ctx, u.String())
     if result.Error != nil {
         return nil, result.Error
     }
@@ -588,20 +435,15 @@ func GetAllCourses() ([]ListCoursesCourse, error) {
     r := &ListCourses{}
     err = json.Unmarshal(result.Data, &r)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
 
     return r.Courses, nil
 }
 
 func IsValidCourseAcronym(acronym string) bool {
-    var courseAcronymRegex = regexp.MustCompile(`^(TAJC|TAJM|EWC|SJC|SJM|ATC|LYS|BUNDLE_TAJC-EWC|TAP_CHALLENGE|TAPCIP)$`)
-    //courseAcronym := strings.ToUpper(acronym)
-
-    if courseAcronymRegex.FindString(acronym) == "" {
-        return false
-    }
-    return true
+    _, err := GetCourseAcronym(acronym)
+    return err == nil
 }
 
 
@@ -623,19 +465,14 @@ func GetCourseStudentsCsv(csvFile string) ([]ListUsersUser, error) {
 
 }
 
-func GetSaleById(id string) (*RetrieveSale, error) {
-    apiUrl, apiCredentials := GetApiCredentials()
-    var p QueryParameters
-    p.Id = to.Uint64(id)
-
+func (c *Client) GetSaleById(ctx context.Context, id string) (*RetrieveSale, error) {
     // Build request URL
-    u, err := BuildRequestUrl(apiUrl, API_URL_SALES, to.String(p.Id))
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_SALES, to.String(to.Uint64(id)))
     if err != nil {
-        return nil, fmt.Errorf("Failed building request url: %s", err)
+        return nil, fmt.Errorf("Failed building request url: %w", err)
     }
 
-    requestUrl := u.String()
-    result := DoApiRequest(requestUrl, apiCredentials)
+    result := c.doApiRequestGet(ctx, u.String())
     if result.Error != nil {
         return nil, result.Error
     }
@@ -644,7 +481,7 @@ func GetSaleById(id string) (*RetrieveSale, error) {
     r := &RetrieveSale{}
     err = json.Unmarshal(result.Data, &r)
     if err != nil {
-        return nil, fmt.Errorf("Failed to unmarshal response data: %s", err)
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
     }
 
     return r, nil
@@ -673,77 +510,32 @@ type TeachableCourse struct {
 }
 */
 
-type CourseAcronym int
-
-const (
-    TAJC CourseAcronym = iota
-    TAJM
-    SJC
-    SJM
-    LYS
-    ATC
-    EWC
-    BUNDLE_TAJC_EWC
-    TAP_CHALLENGE
-    TAPCIP
-)
+// Short course name, e.g. "SJC". The courses are listed in the catalog at
+// CourseCatalogFilePath.
+type CourseAcronym string
 
 func (ct CourseAcronym) String() string {
-    if ct < 0 {
-        return "unknown"
-    }
-    acronymFriendlyUrls := [...]string{"the-artists-journey",
-    "the-artists-journey-masterclass",
-    "sjc-course",
-    "studio-journey-masterclass",
-    "light-your-creative-studio-like-a-pro",
-    "activating-the-canvas",
-    "experimenting-with-color",
-    "bundle-the-artists-journey-experimenting-with-color",
-    "creativity-challenge",
-    "creativity-immersion"}
-    if int(ct) > len(acronymFriendlyUrls) {
-        return "unknown"
-    }
-    return acronymFriendlyUrls[ct]
+    return string(ct)
 }
 
-// Returns error or nil if the ct is valid
+// Returns error or nil if the ct is in the course catalog
 func (ct CourseAcronym) EnsureValid() error {
-    if ct.String() != "unknown" {
-        return nil
-    }
-    return fmt.Errorf("Invalid course acronym: %s", ct)
+    _, err := GetCourseAcronym(string(ct))
+    return err
 }
 
+// Returns the acronym for an acronym, AC tag prefix or friendly URL in the
+// course catalog
 func GetCourseAcronym(a string) (CourseAcronym, error) {
-    a2 := strings.ToUpper(strings.ReplaceAll(a, "-", "_"))
-    switch a2 {
-    case "TAJC": return TAJC, nil
-    case "TAJM": return TAJM, nil
-    case "SJC": return SJC, nil
-    case "SJM": return SJM, nil
-    case "LYS": return LYS, nil
-    case "ATC": return ATC, nil
-    case "EWC": return EWC, nil
-    case "BUNDLE_TAJC_EWC": return BUNDLE_TAJC_EWC, nil
-    case "TAP_CHALLENGE": return TAP_CHALLENGE, nil
-    case "TAPCIP": return TAPCIP, nil
+    c, err := GetCourseCatalog()
+    if err != nil {
+        return "", err
     }
-
-    switch a {
-    case "the-artists-journey": return TAJC, nil
-    case "the-artists-journey-masterclass": return TAJM, nil
-    case "sjc-course": return SJC, nil
-    case "studio-journey-masterclass": return SJM, nil
-    case "light-your-creative-studio-like-a-pro": return LYS, nil
-    case "activating-the-canvas": return ATC, nil
-    case "experimenting-with-color": return EWC, nil
-    case "bundle-the-artists-journey-experimenting-with-color": return BUNDLE_TAJC_EWC, nil
-    case "creativity-challenge": return TAP_CHALLENGE, nil
-    case "creativity-immersion": return TAPCIP, nil
+    course, err := c.Get(a)
+    if err != nil {
+        return "", fmt.Errorf("Cannot build course acronym from unknown acronym string: %s", a)
     }
-    return -1, fmt.Errorf("Cannot build course acronym from unknown acronym string: %s", a)
+    return course.Acronym, nil
 }
 
 /*
@@ -1087,12 +879,10 @@ func (c *ListEnrollmentsEnrollmentCourse) UnmarshalJSON(jsonStr []byte) error {
     }
 
     // Course acronym
-    a, err := GetCourseAcronym(c2.FriendlyUrl)
-    if err != nil {
-        log.Printf("No acronym for course '%s' (id=%d, friendly_url=%s): %s",
-            c2.Name, c2.Id, c2.FriendlyUrl, err)
-    } else {
-        c2.Acronym = a
+    c2.Acronym = findCourseAcronym(c2.Id, c2.FriendlyUrl)
+    if c2.Acronym == "" {
+        log.Printf("No acronym for course '%s' (id=%d, friendly_url=%s)",
+            c2.Name, c2.Id, c2.FriendlyUrl)
     }
 
     *c = ListEnrollmentsEnrollmentCourse(c2)
@@ -1221,15 +1011,10 @@ func (c *RetrieveCourse) UnmarshalJSON(jsonStr []byte) error {
     }
 
     // Course acronym
-    a, err := GetCourseAcronym(c2.FriendlyUrl)
-    if err != nil {
-        if DEBUG && DEBUG_VERBOSE {
-            log.Printf("No acronym for course '%s' (id=%d, friendly_url=%s): %s",
-                c2.Name, c2.Id, c2.FriendlyUrl, err)
-        }
-        c2.Acronym = CourseAcronym(-1)
-    } else {
-        c2.Acronym = a
+    c2.Acronym = findCourseAcronym(c2.Id, c2.FriendlyUrl)
+    if c2.Acronym == "" && DEBUG && DEBUG_VERBOSE {
+        log.Printf("No acronym for course '%s' (id=%d, friendly_url=%s)",
+            c2.Name, c2.Id, c2.FriendlyUrl)
     }
 
     *c = RetrieveCourse(c2)
@@ -1238,9 +1023,7 @@ func (c *RetrieveCourse) UnmarshalJSON(jsonStr []byte) error {
 }
 
 func (c *RetrieveCourse) IsAcronym(a string) bool {
-    err := c.Acronym.EnsureValid()
-    if err != nil {
-        //log.Printf("Comparing course with invalid acronym '%s': %s", c.Acronym.String(), err)
+    if c.Acronym == "" {
         return false
     }
     a2, err := GetCourseAcronym(a)
@@ -1289,6 +1072,7 @@ type RetrieveSale struct {
     //CurrentPeriodStart      string      `json:"current_period_start"`
     //CurrentPeriodEnd        string      `json:"current_period_end"`
     IsRecurring             bool        `json:"is_recurring"`
+    Price                   uint64      `json:"price"` // in cents
     Currency                string      `json:"currency"`
     PaymentMethod           string      `json:"payment_method"`
     PurchasedAt             string      `json:"purchased_at"`
@@ -1299,9 +1083,11 @@ type RetrieveSale struct {
     //VatTaxId                vat_tax_id
     Id                      uint64      `json:"id"`
     //Metadata              RetrieveSaleMetadata   `json:"meta"`
-    //Product               RetrieveSaleProduct   `json:"product"`
+    Product                 RetrieveSaleProduct   `json:"product"`
+    PricingPlan             RetrieveSalePricingPlan   `json:"pricing_plan"`
     Coupon                  RetrieveSaleCoupon   `json:"coupon"`
-    //Transactions          RetrieveSaleTransactions   `json:"transactions"`
+    Transactions            []Transaction   `json:"transactions"`
+    Refunds                 []Refund    `json:"refunds"`
     User                    ListUsersUser   `json:"user"`
     //Enrollments             []ListEnrollmentsEnrollment   `json:"enrollments"`
 }
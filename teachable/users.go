@@ -0,0 +1,93 @@
+package teachable
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+
+    "github.com/xiam/to"
+
+    "bitbucket.org/dagoodma/dagoodma-go/util"
+)
+
+// Creates a user: POST /users. Without a password, the user sets one from
+// the reset password email (see SendPasswordReset()).
+type NewUser struct {
+    Name        string  `json:"name"`
+    Email       string  `json:"email"`
+    Password    string  `json:"password,omitempty"`
+}
+
+// Creates a user with the given name and email, and enrolls them in the
+// course if courseId isn't 0. When the email is already taken, the existing
+// user is returned (and still enrolled). The bool is true if the user was
+// created.
+func (c *Client) CreateUser(ctx context.Context, name string, email string,
+    courseId uint64) (*ListUsersUser, bool, error) {
+    email = strings.ToLower(strings.TrimSpace(email))
+    if !util.EmailLooksValid(email) {
+        return nil, false, fmt.Errorf("Invalid email given: %s", email)
+    }
+
+    u, err := c.GetUserByEmail(ctx, email)
+    created := false
+    if errors.Is(err, ErrNotFound) {
+        u, err = c.createUser(ctx, NewUser{Name: name, Email: email})
+        created = err == nil
+        if errors.Is(err, ErrConflict) {
+            // Created by someone else since we looked
+            u, err = c.GetUserByEmail(ctx, email)
+        }
+    }
+    if err != nil {
+        return nil, false, fmt.Errorf("Failed creating user '%s': %w", email, err)
+    }
+
+    if courseId > 0 {
+        _, err = c.EnrollUser(ctx, u.Id, courseId)
+        if err != nil {
+            return u, created, err
+        }
+    }
+    return u, created, nil
+}
+
+func (c *Client) createUser(ctx context.Context, d NewUser) (*ListUsersUser, error) {
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_USERS)
+    if err != nil {
+        return nil, fmt.Errorf("Failed building request url: %w", err)
+    }
+    data, err := json.Marshal(d)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to marshal user: %w", err)
+    }
+    result := c.doApiRequestContext(ctx, http.MethodPost, u.String(), data, http.StatusCreated,
+        http.StatusOK)
+    if result.Error != nil {
+        return nil, result.Error
+    }
+
+    r := &ListUsersUser{}
+    err = json.Unmarshal(result.Data, &r)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal response data: %w", err)
+    }
+    return r, nil
+}
+
+// Has Teachable email the user a link to set a new password
+func (c *Client) SendPasswordReset(ctx context.Context, userId uint64) error {
+    u, err := BuildRequestUrl(c.ApiUrl, API_URL_USERS, to.String(userId), API_URL_RESET_PASSWORD)
+    if err != nil {
+        return fmt.Errorf("Failed building request url: %w", err)
+    }
+    result := c.doApiRequestContext(ctx, http.MethodPost, u.String(), nil, http.StatusOK,
+        http.StatusNoContent)
+    if result.Error != nil {
+        return fmt.Errorf("Failed sending password reset to user %d: %w", userId, result.Error)
+    }
+    return nil
+}
@@ -0,0 +1,84 @@
+package teachable_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
+)
+
+func TestClientCreateUser(t *testing.T) {
+	var mutex sync.Mutex
+	users := map[string]uint64{"existing@example.com": 5}
+	var resets []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/users":
+			email := r.URL.Query().Get("email")
+			if id, ok := users[email]; ok {
+				fmt.Fprintf(w, `{"users":[{"id":%d,"email":%q}],"meta":{"page":1,"total":1,"number_of_pages":1}}`,
+					id, email)
+				return
+			}
+			fmt.Fprint(w, `{"users":[],"meta":{"page":1,"total":0,"number_of_pages":0}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/users":
+			var u teachable.NewUser
+			json.NewDecoder(r.Body).Decode(&u)
+			if _, ok := users[u.Email]; ok {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"errors":{"email":["has already been taken"]}}`)
+				return
+			}
+			users[u.Email] = uint64(len(users) + 5)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":%d,"name":%q,"email":%q}`, users[u.Email], u.Name, u.Email)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/users/6/reset_password":
+			resets = append(resets, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	client := teachable.NewClient(ts.URL, "api-user", "api-password")
+	ctx := context.Background()
+
+	cases := []struct {
+		emailIn     string
+		wantId      uint64
+		wantCreated bool
+		wantError   bool
+	}{
+		{"existing@example.com", 5, false, false},
+		{"New@Example.com", 6, true, false},
+		{"new@example.com", 6, false, false},
+		{"not-an-email", 0, false, true},
+	}
+	for _, c := range cases {
+		u, created, err := client.CreateUser(ctx, "Test Student", c.emailIn, 0)
+		gotError := err != nil
+		if gotError != c.wantError {
+			t.Errorf("CreateUser(%q) == (error=%t), want (error=%t), got err: %v",
+				c.emailIn, gotError, c.wantError, err)
+			continue
+		}
+		if !gotError && (u.Id != c.wantId || created != c.wantCreated) {
+			t.Errorf("CreateUser(%q) == (id=%d, created=%t), want (id=%d, created=%t)",
+				c.emailIn, u.Id, created, c.wantId, c.wantCreated)
+		}
+	}
+
+	if err := client.SendPasswordReset(ctx, 6); err != nil || len(resets) != 1 {
+		t.Errorf("SendPasswordReset(6) == %v, server saw %d resets", err, len(resets))
+	}
+	if err := client.SendPasswordReset(ctx, 99); err == nil {
+		t.Errorf("SendPasswordReset(99) of missing user returned no error")
+	}
+}
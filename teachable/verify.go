@@ -0,0 +1,336 @@
+package teachable
+
+import (
+    "crypto/hmac"
+    "crypto/sha256"
+    "crypto/subtle"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net"
+    "net/url"
+    "reflect"
+    "strings"
+    "time"
+
+    "github.com/xiam/to"
+)
+
+// Teachable no longer sends a relic ID, so webhooks are checked with any of
+// these instead. Add ?secret=... to the hook URL (or send the secret in
+// WEBHOOK_SECRET_HEADER), and have the webhook server pass the query and
+// remote address to the program after the header and body.
+const (
+    WEBHOOK_SECRET_HEADER = "X-Webhook-Secret"
+    WEBHOOK_SECRET_PARAM = "secret"
+    WEBHOOK_SIGNATURE_HEADER = "X-Teachable-Signature" // hex HMAC-SHA256 of the body
+    DEFAULT_WEBHOOK_MAX_AGE = time.Hour
+)
+
+var (
+    ErrWebhookSecretMismatch = errors.New("Webhook secret does not match")
+    ErrWebhookSourceNotAllowed = errors.New("Webhook source IP is not allowed")
+    ErrWebhookSignatureMismatch = errors.New("Webhook signature does not match")
+    ErrWebhookStale = errors.New("Webhook event is too old")
+)
+
+// Everything we get about an incoming webhook
+type WebhookRequest struct {
+    Header      *WebhookHeader
+    Body        []byte
+    Query       url.Values // from the hook URL, may be empty
+    RemoteAddr  string // "ip" or "ip:port", may be empty
+}
+
+// Builds the request from the webhook program arguments (without the
+// program name): header JSON, body, and optionally the query (JSON object
+// or query string) and remote address.
+func ParseWebhookRequest(args []string) (*WebhookRequest, error) {
+    if len(args) < 2 {
+        return nil, fmt.Errorf("Not enough webhook arguments, expected at least 2, got: %d", len(args))
+    }
+    r := &WebhookRequest{Header: &WebhookHeader{}, Body: []byte(args[1]), Query: url.Values{}}
+    err := json.Unmarshal([]byte(args[0]), r.Header)
+    if err != nil {
+        return nil, fmt.Errorf("Failed unmarshaling webhook header: %w", err)
+    }
+    if len(args) > 2 {
+        r.Query, err = parseWebhookQuery(args[2])
+        if err != nil {
+            return nil, err
+        }
+    }
+    if len(args) > 3 {
+        r.RemoteAddr = args[3]
+    }
+    return r, nil
+}
+
+func parseWebhookQuery(raw string) (url.Values, error) {
+    raw = strings.TrimSpace(raw)
+    if !strings.HasPrefix(raw, "{") {
+        q, err := url.ParseQuery(strings.TrimPrefix(raw, "?"))
+        if err != nil {
+            return nil, fmt.Errorf("Failed parsing webhook query: %w", err)
+        }
+        return q, nil
+    }
+    m := make(map[string]interface{})
+    err := json.Unmarshal([]byte(raw), &m)
+    if err != nil {
+        return nil, fmt.Errorf("Failed unmarshaling webhook query: %w", err)
+    }
+    q := url.Values{}
+    for k, v := range m {
+        if l, ok := v.([]interface{}); ok {
+            for _, v2 := range l {
+                q.Add(k, to.String(v2))
+            }
+            continue
+        }
+        q.Set(k, to.String(v))
+    }
+    return q, nil
+}
+
+// Returns the named header, ignoring case
+func (h *WebhookHeader) Get(name string) string {
+    v := reflect.ValueOf(*h)
+    typ := v.Type()
+    for i := 0; i < typ.NumField(); i++ {
+        jsonTag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+        if jsonTag != "" && jsonTag != "-" && strings.EqualFold(jsonTag, name) {
+            return v.Field(i).String()
+        }
+    }
+    for k, v := range h.Extra {
+        if strings.EqualFold(k, name) {
+            return to.String(v)
+        }
+    }
+    return ""
+}
+
+// Checks an incoming webhook, returning an error if it should be rejected
+type WebhookVerifier interface {
+    VerifyWebhook(r *WebhookRequest) error
+}
+
+// Lets a plain function be used as a WebhookVerifier
+type WebhookVerifierFunc func(r *WebhookRequest) error
+
+func (f WebhookVerifierFunc) VerifyWebhook(r *WebhookRequest) error {
+    return f(r)
+}
+
+// Requires all of the verifiers to pass
+type WebhookVerifiers []WebhookVerifier
+
+func (l WebhookVerifiers) VerifyWebhook(r *WebhookRequest) error {
+    for _, v := range l {
+        if err := v.VerifyWebhook(r); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Requires a shared secret in the header or the query parameter
+type SecretVerifier struct {
+    Secret      string
+    Header      string // defaults to WEBHOOK_SECRET_HEADER
+    Param       string // defaults to WEBHOOK_SECRET_PARAM
+}
+
+func (v *SecretVerifier) VerifyWebhook(r *WebhookRequest) error {
+    header, param := v.Header, v.Param
+    if header == "" {
+        header = WEBHOOK_SECRET_HEADER
+    }
+    if param == "" {
+        param = WEBHOOK_SECRET_PARAM
+    }
+    secret := ""
+    if r.Header != nil {
+        secret = r.Header.Get(header)
+    }
+    if secret == "" {
+        secret = r.Query.Get(param)
+    }
+    if v.Secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(v.Secret)) != 1 {
+        return ErrWebhookSecretMismatch
+    }
+    return nil
+}
+
+// Requires the remote address to be one of the allowed IPs or networks
+type SourceIpVerifier struct {
+    Allowed     []*net.IPNet
+}
+
+// Creates a verifier from IPs (e.g. "1.2.3.4") and CIDRs (e.g. "1.2.3.0/24")
+func NewSourceIpVerifier(allowed []string) (*SourceIpVerifier, error) {
+    v := &SourceIpVerifier{}
+    for _, a := range allowed {
+        a = strings.TrimSpace(a)
+        if !strings.Contains(a, "/") {
+            ip := net.ParseIP(a)
+            if ip == nil {
+                return nil, fmt.Errorf("Invalid allowed webhook IP: %s", a)
+            }
+            bits := 8 * net.IPv6len
+            if ip.To4() != nil {
+                ip, bits = ip.To4(), 8 * net.IPv4len
+            }
+            v.Allowed = append(v.Allowed, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+            continue
+        }
+        _, n, err := net.ParseCIDR(a)
+        if err != nil {
+            return nil, fmt.Errorf("Invalid allowed webhook network: %w", err)
+        }
+        v.Allowed = append(v.Allowed, n)
+    }
+    return v, nil
+}
+
+func (v *SourceIpVerifier) VerifyWebhook(r *WebhookRequest) error {
+    host := r.RemoteAddr
+    if h, _, err := net.SplitHostPort(host); err == nil {
+        host = h
+    }
+    ip := net.ParseIP(host)
+    if ip == nil {
+        return fmt.Errorf("%w: missing or invalid remote address '%s'", ErrWebhookSourceNotAllowed,
+            r.RemoteAddr)
+    }
+    for _, n := range v.Allowed {
+        if n.Contains(ip) {
+            return nil
+        }
+    }
+    return fmt.Errorf("%w: %s", ErrWebhookSourceNotAllowed, ip)
+}
+
+// Requires a hex HMAC-SHA256 signature of the body, optionally prefixed
+// with "sha256="
+type SignatureVerifier struct {
+    Secret      string
+    Header      string // defaults to WEBHOOK_SIGNATURE_HEADER
+}
+
+func (v *SignatureVerifier) VerifyWebhook(r *WebhookRequest) error {
+    header := v.Header
+    if header == "" {
+        header = WEBHOOK_SIGNATURE_HEADER
+    }
+    signature := ""
+    if r.Header != nil {
+        signature = strings.TrimPrefix(r.Header.Get(header), "sha256=")
+    }
+    got, err := hex.DecodeString(signature)
+    if err != nil || len(got) < 1 {
+        return ErrWebhookSignatureMismatch
+    }
+    if !hmac.Equal(got, SignWebhookBody([]byte(v.Secret), r.Body)) {
+        return ErrWebhookSignatureMismatch
+    }
+    return nil
+}
+
+// Returns the HMAC-SHA256 of the body
+func SignWebhookBody(secret []byte, body []byte) []byte {
+    mac := hmac.New(sha256.New, secret)
+    mac.Write(body)
+    return mac.Sum(nil)
+}
+
+// Requires the event's created time to be within MaxAge of now, so an old
+// captured webhook can't be replayed
+type FreshnessVerifier struct {
+    MaxAge      time.Duration // defaults to DEFAULT_WEBHOOK_MAX_AGE
+    Now         func() time.Time // defaults to time.Now
+}
+
+func (v *FreshnessVerifier) VerifyWebhook(r *WebhookRequest) error {
+    var e struct {
+        Created     string  `json:"created"`
+    }
+    err := json.Unmarshal(r.Body, &e)
+    if err != nil || e.Created == "" {
+        return fmt.Errorf("%w: missing created time", ErrWebhookStale)
+    }
+    created, err := time.Parse(time.RFC3339, e.Created)
+    if err != nil {
+        return fmt.Errorf("%w: invalid created time '%s'", ErrWebhookStale, e.Created)
+    }
+    maxAge, now := v.MaxAge, time.Now
+    if maxAge <= 0 {
+        maxAge = DEFAULT_WEBHOOK_MAX_AGE
+    }
+    if v.Now != nil {
+        now = v.Now
+    }
+    age := now().Sub(created)
+    if age > maxAge || age < -maxAge {
+        return fmt.Errorf("%w: created %s", ErrWebhookStale, e.Created)
+    }
+    return nil
+}
+
+// Builds the verifiers configured in the secrets file. The freshness check
+// is added whenever any other check is, or when WEBHOOK_MAX_AGE is set.
+// Returns nil if nothing is configured.
+func NewWebhookVerifierFromSecrets(s *SecretsConfig) (WebhookVerifier, error) {
+    var l WebhookVerifiers
+    if s.WebhookSecret != "" {
+        l = append(l, &SecretVerifier{Secret: s.WebhookSecret})
+    }
+    if len(s.WebhookAllowedIps) > 0 {
+        v, err := NewSourceIpVerifier(s.WebhookAllowedIps)
+        if err != nil {
+            return nil, err
+        }
+        l = append(l, v)
+    }
+    if s.WebhookSigningSecret != "" {
+        l = append(l, &SignatureVerifier{Secret: s.WebhookSigningSecret})
+    }
+    if len(l) > 0 || s.WebhookMaxAge != "" {
+        v := &FreshnessVerifier{}
+        if s.WebhookMaxAge != "" {
+            d, err := time.ParseDuration(s.WebhookMaxAge)
+            if err != nil {
+                return nil, fmt.Errorf("Invalid webhook max age: %w", err)
+            }
+            v.MaxAge = d
+        }
+        l = append(l, v)
+    }
+    if len(l) < 1 {
+        return nil, nil
+    }
+    return l, nil
+}
+
+// Runs the basic checks in EnsureValidWebhook() and then the client's
+// WebhookVerifier, if it has one
+func (c *Client) VerifyWebhook(r *WebhookRequest) error {
+    err := EnsureValidWebhook(r.Header, r.Body)
+    if err != nil {
+        return err
+    }
+    if c.WebhookVerifier == nil {
+        return nil
+    }
+    return c.WebhookVerifier.VerifyWebhook(r)
+}
+
+func VerifyWebhook(r *WebhookRequest) error {
+    c, err := DefaultClient()
+    if err != nil {
+        return fmt.Errorf("Could not load webhook settings: %w", err)
+    }
+    return c.VerifyWebhook(r)
+}
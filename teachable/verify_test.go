@@ -0,0 +1,69 @@
+package teachable_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	v, err := teachable.NewWebhookVerifierFromSecrets(&teachable.SecretsConfig{
+		WebhookSecret:        "hook-secret",
+		WebhookSigningSecret: "signing-secret",
+		WebhookAllowedIps:    []string{"10.0.0.0/8", "192.168.1.5"},
+		WebhookMaxAge:        "15m",
+	})
+	if err != nil {
+		t.Fatalf("NewWebhookVerifierFromSecrets() returned error: %v", err)
+	}
+	for _, f := range v.(teachable.WebhookVerifiers) {
+		if fv, ok := f.(*teachable.FreshnessVerifier); ok {
+			fv.Now = func() time.Time { return now }
+		}
+	}
+	client := teachable.NewClient("https://school.teachable.com", "api-user", "api-password")
+	client.WebhookVerifier = v
+
+	body := func(created time.Time) string {
+		return fmt.Sprintf(`{"type":"User.created","id":1,"created":%q,"object":{}}`,
+			created.Format(time.RFC3339))
+	}
+	sign := func(b string) string {
+		return hex.EncodeToString(teachable.SignWebhookBody([]byte("signing-secret"), []byte(b)))
+	}
+	header := func(b string, secret string) string {
+		return fmt.Sprintf(`{"User-Agent":"rest-client/2.1.0","x-webhook-secret":%q,"X-Teachable-Signature":"sha256=%s"}`,
+			secret, sign(b))
+	}
+	fresh := body(now.Add(-time.Minute))
+	stale := body(now.Add(-time.Hour))
+
+	cases := []struct {
+		args    []string
+		wantErr error
+	}{
+		{[]string{header(fresh, "hook-secret"), fresh, "", "10.1.2.3:4567"}, nil},
+		{[]string{header(fresh, ""), fresh, `{"secret":"hook-secret"}`, "192.168.1.5"}, nil},
+		{[]string{header(fresh, ""), fresh, "secret=wrong", "10.1.2.3"}, teachable.ErrWebhookSecretMismatch},
+		{[]string{header(fresh, "hook-secret"), fresh}, teachable.ErrWebhookSourceNotAllowed},
+		{[]string{header(fresh, "hook-secret"), fresh, "", "192.168.1.6"}, teachable.ErrWebhookSourceNotAllowed},
+		{[]string{header(stale, "hook-secret"), fresh, "", "10.1.2.3"}, teachable.ErrWebhookSignatureMismatch},
+		{[]string{header(stale, "hook-secret"), stale, "", "10.1.2.3"}, teachable.ErrWebhookStale},
+	}
+	for i, c := range cases {
+		r, err := teachable.ParseWebhookRequest(c.args)
+		if err != nil {
+			t.Errorf("%d: ParseWebhookRequest() returned error: %v", i, err)
+			continue
+		}
+		err = client.VerifyWebhook(r)
+		if c.wantErr == nil && err != nil || c.wantErr != nil && !errors.Is(err, c.wantErr) {
+			t.Errorf("%d: VerifyWebhook() == %v, want %v", i, err, c.wantErr)
+		}
+	}
+}
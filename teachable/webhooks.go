@@ -18,16 +18,8 @@ import (
 /*
  * Action handlers
  */
-func EnsureValidRelicId(actualRelicId string) error {
-    var c SecretsConfig
-    err := c.GetSecrets(SecretsFilePath)
-	if err != nil {
-		log.Fatalf("Could not open YAML secrets file: %s", err.Error())
-	}
-
-	expectedRelicId := c.RelicId
-
-	if actualRelicId != expectedRelicId {
+func (c *Client) EnsureValidRelicId(actualRelicId string) error {
+	if actualRelicId != c.RelicId {
 		//log.Printf("WARNING: Invalid relic ID \"%s\" when expecting: %s\n",
 		message := fmt.Sprintf("Received invalid relic ID \"%s\" in webhook", actualRelicId)
 		log.Printf("Error: %s", message)
@@ -36,6 +28,14 @@ func EnsureValidRelicId(actualRelicId string) error {
 	return nil
 }
 
+func EnsureValidRelicId(actualRelicId string) error {
+	c, err := DefaultClient()
+	if err != nil {
+		return fmt.Errorf("Could not load expected relic ID: %w", err)
+	}
+	return c.EnsureValidRelicId(actualRelicId)
+}
+
 func EnsureValidWebhook(h *WebhookHeader, d []byte) error {
 	// Validate the header
 	// NOTE Teachable stopped sending a relic ID and now includes something called:
@@ -255,6 +255,8 @@ type StudentCancelled struct {
 type StudentCancelledObject struct {
 	SchoolIdRaw float64 `json:"school_id"`
 	SchoolId    string
+	CourseIdRaw float64 `json:"course_id"`
+	CourseId    string
 	IdRaw       float64 `json:"id"`
 	Id          string
 	IsActive    bool                 `json:"is_active"`
@@ -295,6 +297,7 @@ func (s *StudentCancelled) UnmarshalJSON(jsonStr []byte) error {
 	// Convert from raw types in StudentEnrolledObject struct
 	s2.Object.Id = to.String(to.Uint64(s2.Object.IdRaw))
 	s2.Object.SchoolId = to.String(to.Uint64(s2.Object.SchoolIdRaw))
+	s2.Object.CourseId = to.String(to.Uint64(s2.Object.CourseIdRaw))
 	s2.Object.UserId = to.String(to.Uint64(s2.Object.UserIdRaw))
 
 	// Convert from raw types in StudentEnrolledUser struct
@@ -357,3 +360,54 @@ func (s *StudentUpdated) UnmarshalJSON(jsonStr []byte) error {
 
 	return nil
 }
+
+// Student created a comment on a lecture
+type CommentCreated struct {
+	Type        string               `json:"type"`
+	Id          float64              `json:"id"`
+	Created     string               `json:"created"`
+	HookEventId float64              `json:"hook_event_id"`
+	Object      CommentCreatedObject `json:"object,string"`
+	//Extra map[string]interface{}
+}
+
+type CommentCreatedObject struct {
+	IdRaw           float64 `json:"id"`
+	Id              string
+	Body            string  `json:"body"`
+	CreatedAt       string  `json:"created_at"`
+	CourseIdRaw     float64 `json:"course_id"`
+	CourseId        string
+	AttachmentIdRaw float64 `json:"attachment_id"`
+	AttachmentId    string
+	LectureIdRaw    float64 `json:"lecture_id"`
+	LectureId       string
+	User            StudentEnrolledUser `json:"user,string"`
+	//Extra map[string]interface{}
+}
+
+type _CommentCreated CommentCreated
+
+func (s *CommentCreated) UnmarshalJSON(jsonStr []byte) error {
+	s2 := _CommentCreated{}
+
+	err := json.Unmarshal(jsonStr, &s2)
+	if err != nil {
+		return err
+	}
+
+	// Convert from raw types in CommentCreatedObject struct
+	s2.Object.Id = to.String(to.Uint64(s2.Object.IdRaw))
+	s2.Object.CourseId = to.String(to.Uint64(s2.Object.CourseIdRaw))
+	s2.Object.AttachmentId = to.String(to.Uint64(s2.Object.AttachmentIdRaw))
+	s2.Object.LectureId = to.String(to.Uint64(s2.Object.LectureIdRaw))
+
+	// Convert from raw types in StudentEnrolledUser struct
+	s2.Object.User.Id = to.String(to.Uint64(s2.Object.User.IdRaw))
+	s2.Object.User.SchoolId = to.String(to.Uint64(s2.Object.User.SchoolIdRaw))
+	s2.Object.User.SignInCount = to.Uint64(s2.Object.User.SignInCountRaw)
+
+	*s = CommentCreated(s2)
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"bitbucket.org/dagoodma/dagoodma-go/stripewrap"
+	"bitbucket.org/dagoodma/dagoodma-go/util"
+	"bitbucket.org/dagoodma/nancyhillis-go/studiojourney"
+	"bitbucket.org/dagoodma/nancyhillis-go/teachable"
+)
+
+var Debug = false // Show/hide debug output
+
+// Stripe event sent when a student buys SJ outside of Teachable
+var SubscriptionCreatedEvent = "customer.subscription.created"
+
+// Stripe event sent when a subscription ends, whether it was canceled or
+// canceled after billing completed
+var SubscriptionEndedEvent = "customer.subscription.deleted"
+
+// Only Studio Journey plans are handled
+var SjPlanPrefix = "sj-"
+
+// Course that's granted on purchase or when billing completes, and revoked
+// when billing is canceled
+var SjCourseAcronym = teachable.CourseAcronym("SJC")
+
+// How long to wait on Teachable before giving up
+var TeachableTimeout = 60 * time.Second
+
+func main() {
+	argsWithProg := os.Args
+	if len(argsWithProg) < 3 {
+		HandleError(nil, "No data provided")
+		return
+	}
+
+	// Local secrets?
+	if _, err := os.Stat("teachable_secrets.yml"); !os.IsNotExist(err) {
+		log.Println("Got local Teachable secrets.")
+		teachable.SecretsFilePath = "teachable_secrets.yml"
+	}
+	if _, err := os.Stat("course_catalog.yml"); !os.IsNotExist(err) {
+		log.Println("Got local course catalog.")
+		teachable.CourseCatalogFilePath = "course_catalog.yml"
+	}
+
+	// Create the webhook event
+	programName := string(argsWithProg[0])
+	header := []byte(argsWithProg[1])
+	data := []byte(argsWithProg[2])
+	w := util.NewWebhookEvent(programName, header, data)
+	if Debug {
+		util.RecordWebhookStarted(w)
+	}
+
+	// Unmarshal the Stripe event
+	event, err := stripewrap.UnmarshallWebhookEvent(data)
+	if err != nil {
+		HandleError(w, "Error while parsing input data for Stripe webhook event '%s'. %v", data, err)
+		return
+	}
+	if event.Type != SubscriptionCreatedEvent && event.Type != SubscriptionEndedEvent {
+		HandleError(w, "Unexpected Stripe webhook event: %s", event.Type)
+		return
+	}
+	planId := event.GetObjectValue("plan", "id")
+	if !strings.HasPrefix(planId, SjPlanPrefix) {
+		if Debug {
+			log.Printf("Ignoring subscription event for non-SJ plan: %s", planId)
+		}
+		return
+	}
+	customerId := event.GetObjectValue("customer")
+
+	// Billing complete means they keep the course, otherwise they canceled
+	status, err := studiojourney.GetAccountStatus(customerId)
+	if err != nil {
+		HandleError(w, "Failed looking up SJ account status for customer %s: %v", customerId, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), TeachableTimeout)
+	defer cancel()
+	tc, err := teachable.DefaultClient()
+	if err != nil {
+		HandleError(w, "Failed loading Teachable client: %v", err)
+		return
+	}
+	if event.Type == SubscriptionCreatedEvent {
+		GrantNewStudentAccess(ctx, w, tc, status)
+		return
+	}
+
+	// Only revoke access when they really canceled. The billing sheet isn't
+	// updated right away when a payment plan's last subscription ends, and
+	// plan switches also delete subscriptions, so anything else is left for
+	// someone to check.
+	revoke := status.Status == "canceled" && !status.IsBillingActive
+	if !status.IsBillingComplete && !revoke {
+		message := fmt.Sprintf("Student \"%s\" (%s) SJ subscription ended with account status '%s',"+
+			" so SJC access was left unchanged in Teachable. Check whether it should be revoked.",
+			status.Email, customerId, status.Status)
+		util.ReportWebhookSuccess(w, message)
+		return
+	}
+
+	u, err := tc.GetUserByEmail(ctx, status.Email)
+	if err != nil {
+		HandleError(w, "Failed to find SJ student \"%s\" (%s) in Teachable: %v", status.Email,
+			customerId, err)
+		return
+	}
+
+	var changed bool
+	var action string
+	if status.IsBillingComplete {
+		action = "granted"
+		changed, err = tc.EnrollUserByAcronym(ctx, u.Id, SjCourseAcronym)
+	} else {
+		action = "revoked"
+		changed, err = tc.UnenrollUserByAcronym(ctx, u.Id, SjCourseAcronym)
+	}
+	if err != nil {
+		HandleError(w, "Failed updating SJC access for \"%s\" (%s) in Teachable: %v", status.Email,
+			customerId, err)
+		return
+	}
+
+	// Report to slack
+	reason := "billing was canceled"
+	if status.IsBillingComplete {
+		reason = "billing completed"
+	}
+	message := fmt.Sprintf("Student \"%s\" (%s) %s, so SJC access was %s in Teachable.",
+		status.Email, customerId, reason, action)
+	if !changed {
+		message = fmt.Sprintf("Student \"%s\" (%s) %s, and SJC access was already %s in Teachable.",
+			status.Email, customerId, reason, action)
+	}
+	util.ReportWebhookSuccess(w, message)
+}
+
+// Creates the student's Teachable account if needed and enrolls them. New
+// accounts get an email to set their password.
+func GrantNewStudentAccess(ctx context.Context, w *util.WebhookEvent, tc *teachable.Client,
+	status *studiojourney.StudentStatus) {
+	course, err := tc.GetCourseByAcronym(ctx, SjCourseAcronym)
+	if err != nil {
+		HandleError(w, "Failed finding SJC course in Teachable: %v", err)
+		return
+	}
+	name := strings.TrimSpace(status.FirstName + " " + status.LastName)
+	u, created, err := tc.CreateUser(ctx, name, status.Email, course.Id)
+	if err != nil {
+		HandleError(w, "Failed creating SJ student \"%s\" (%s) in Teachable: %v", status.Email,
+			status.CustomerId, err)
+		return
+	}
+	if !created {
+		message := fmt.Sprintf("Student \"%s\" (%s) purchased SJ and was enrolled in SJC with their"+
+			" existing Teachable account.", status.Email, status.CustomerId)
+		util.ReportWebhookSuccess(w, message)
+		return
+	}
+	err = tc.SendPasswordReset(ctx, u.Id)
+	if err != nil {
+		HandleError(w, "Created SJ student \"%s\" (%s) in Teachable, but failed sending their"+
+			" password email: %v", status.Email, status.CustomerId, err)
+		return
+	}
+	message := fmt.Sprintf("Student \"%s\" (%s) purchased SJ, so a Teachable account was created"+
+		" and enrolled in SJC.", status.Email, status.CustomerId)
+	util.ReportWebhookSuccess(w, message)
+}
+
+func HandleError(w *util.WebhookEvent, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	util.PrintJsonError(message)
+	if Debug {
+		log.Printf(message)
+	}
+	if w != nil {
+		util.ReportWebhookFailure(w, message)
+	}
+}